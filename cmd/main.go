@@ -2,8 +2,11 @@ package main
 
 import (
 	"cursovay/internal/controller"
+	"cursovay/internal/currency"
 	"cursovay/internal/repository"
+	"cursovay/internal/validation"
 	"cursovay/internal/view"
+	"cursovay/pkg/config"
 	"cursovay/pkg/localization"
 	"log"
 	"os"
@@ -41,6 +44,29 @@ func main() {
 	repo := repository.NewManufacturerRepository("")
 	controller := controller.NewManufacturerController(repo)
 
+	// Глубина истории отмены берется из настроек, если она там задана
+	cfg, err := config.LoadConfig()
+	if err == nil && cfg.UndoDepth > 0 {
+		controller.SetUndoDepth(cfg.UndoDepth)
+	}
+
+	// Правила проверки записей; без файла правил действуют правила по умолчанию
+	if rules, err := validation.Load(cfg.RulesPath()); err == nil {
+		controller.SetRules(rules)
+	} else if !os.IsNotExist(err) {
+		log.Printf("Ошибка загрузки правил проверки: %v", err)
+	}
+
+	// Курсы валют и валюта отчетов; без файла курсов пересчитывается
+	// только базовая валюта
+	rates, err := currency.Load(cfg.RatesPath())
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("Ошибка загрузки курсов валют: %v", err)
+	}
+	if err := controller.SetCurrency(rates, cfg.Currency); err != nil {
+		log.Printf("Ошибка настройки валюты отчетов: %v", err)
+	}
+
 	// Загружаем локализацию для графиков
 	if err := controller.LoadLocalization("ru"); err != nil {
 		log.Printf("Ошибка загрузки локализации графиков: %v", err)
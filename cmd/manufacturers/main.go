@@ -0,0 +1,12 @@
+// Консольная утилита для работы с базой производителей без окна Fyne.
+// Пример: manufacturers -file data.csv list
+package main
+
+import (
+	"cursovay/internal/cli"
+	"os"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
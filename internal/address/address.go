@@ -0,0 +1,229 @@
+// Package address разбирает адрес, записанный одной строкой, на части
+// (улица, город, регион, индекс, страна) и собирает строку обратно
+// без страны: страна хранится в отдельном поле записи.
+// Разбор нужен для перевода старых записей, где адрес — свободный текст:
+//
+//	150000, Россия, Ярославская обл., г. Ярославль, ул. Ленина, д. 5
+//	1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA
+//	Musterstraße 1, 10115 Berlin, Germany
+package address
+
+import (
+	"cursovay/internal/country"
+	"cursovay/internal/model"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Address — части адреса. Country — код страны ISO 3166, если страну
+// удалось определить, иначе текст как в исходной строке.
+type Address struct {
+	Street     string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// Empty сообщает, что ни одна часть адреса не заполнена
+func (a Address) Empty() bool {
+	return a == Address{}
+}
+
+// String собирает адрес в одну строку: улица, город, регион, индекс.
+// Страна в строку не входит: у записи она хранится в поле страны
+// и показывается рядом с адресом, поэтому иначе была бы выведена дважды.
+func (a Address) String() string {
+	parts := []string{a.Street, a.City, a.Region, a.PostalCode}
+	var nonEmpty []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
+
+// Of возвращает части адреса записи
+func Of(m *model.Manufacturer) Address {
+	return Address{Street: m.Street, City: m.City, Region: m.Region, PostalCode: m.PostalCode, Country: m.Country}
+}
+
+// Structured сообщает, что у записи заполнена хотя бы одна часть адреса
+// (страна не в счет: она была и до разделения адреса)
+func Structured(m *model.Manufacturer) bool {
+	return m.Street != "" || m.City != "" || m.Region != "" || m.PostalCode != ""
+}
+
+// Join пересчитывает адрес одной строкой из частей адреса. Запись без
+// частей адреса не меняется: ее Address — исходный свободный текст.
+func Join(m *model.Manufacturer) {
+	if Structured(m) {
+		m.Address = Of(m).String()
+	}
+}
+
+// Split заполняет части адреса разбором m.Address. Записи, у которых
+// части адреса уже есть, не меняются. Страна из адреса записывается,
+// только если у записи она не указана. Если разбор не уверен — часть
+// адреса пришлось считать регионом или дописать к улице по догадке или
+// страна в адресе не совпадает со страной записи, — запись не меняется
+// и исходный текст адреса сохраняется.
+// Возвращает true, если запись изменилась.
+func Split(m *model.Manufacturer) bool {
+	if Structured(m) || strings.TrimSpace(m.Address) == "" {
+		return false
+	}
+	a, sure := parse(m.Address)
+	if !sure || a.Street == "" && a.City == "" && a.Region == "" && a.PostalCode == "" {
+		return false
+	}
+	if code, ok := country.Normalize(m.Country); a.Country != "" && m.Country != "" && (!ok || code != a.Country) {
+		return false
+	}
+	m.Street, m.City, m.Region, m.PostalCode = a.Street, a.City, a.Region, a.PostalCode
+	if m.Country == "" {
+		m.Country = a.Country
+	}
+	Join(m)
+	return true
+}
+
+var (
+	postalCode     = regexp.MustCompile(`^(\d{6}|\d{5}(-\d{4})?|[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}|\d{4} ?[A-Z]{2})$`)
+	postalCity     = regexp.MustCompile(`^(\d{4,6})\s+(\D.*)$`)            // 10115 Berlin
+	statePostal    = regexp.MustCompile(`^([A-Z]{2})\s+(\d{5}(-\d{4})?)$`) // CA 94043
+	cityPrefix     = regexp.MustCompile(`^(?i)(г\.|г |город |гор\.|city of )\s*`)
+	houseNumber    = regexp.MustCompile(`^(?i)(д\.|дом|стр\.|строение|корп\.|корпус|к\.|оф\.|офис|кв\.|пом\.|помещение|литер|лит\.|suite|ste\.?|unit|apt\.?|#)?\s*\d+[\p{L}\d/-]*$`)
+	streetWords    = []string{"ул", "улица", "пр", "пр-т", "просп", "проспект", "пер", "переулок", "ш", "шоссе", "наб", "набережная", "бульвар", "б-р", "пл", "площадь", "проезд", "пр-д", "тупик", "аллея", "мкр", "микрорайон", "тракт", "street", "st", "avenue", "ave", "road", "rd", "boulevard", "blvd", "lane", "ln", "drive", "dr", "way", "parkway", "pkwy", "square", "sq", "str", "straße", "strasse", "weg", "platz", "allee", "via", "rue", "calle"}
+	regionWords    = []string{"обл", "область", "край", "респ", "республика", "ао", "округ", "район", "р-н", "region", "oblast", "krai", "state", "province", "county", "district", "prefecture"}
+	settlementPref = []string{"пос.", "п.", "пгт", "с.", "село", "дер.", "деревня", "ст-ца", "станица"}
+)
+
+// Parse разбирает адрес одной строкой. Части разделяются запятыми;
+// каждая часть узнается по индексу, словам "ул.", "обл.", "г."
+// и справочнику стран и городов. Нераспознанные части считаются городом,
+// затем улицей, если хотя бы одна часть узнана.
+func Parse(line string) Address {
+	a, _ := parse(line)
+	return a
+}
+
+// parse разбирает адрес как Parse и сообщает, уверен ли разбор:
+// нераспознанных частей не больше, чем пустых мест для города и улицы
+func parse(line string) (Address, bool) {
+	var a Address
+	var rest []string
+	appendStreet := func(p string) {
+		if a.Street == "" {
+			a.Street = p
+		} else {
+			a.Street += ", " + p
+		}
+	}
+
+	parts := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ';' || r == '\n' })
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		switch {
+		case p == "":
+		case a.PostalCode == "" && postalCode.MatchString(strings.ToUpper(p)):
+			a.PostalCode = strings.ToUpper(p)
+		case a.PostalCode == "" && a.City == "" && postalCity.MatchString(p) && !hasWord(p, streetWords):
+			m := postalCity.FindStringSubmatch(p)
+			a.PostalCode, a.City = m[1], strings.TrimSpace(m[2])
+		case a.PostalCode == "" && statePostal.MatchString(p):
+			m := statePostal.FindStringSubmatch(p)
+			a.Region, a.PostalCode = m[1], m[2]
+		case a.Country == "" && isCountry(p, i == len(parts)-1):
+			c, _ := country.ByName(p)
+			a.Country = c.Code
+		case a.Region == "" && hasWord(p, regionWords):
+			a.Region = p
+		case a.City == "" && cityPrefix.MatchString(p):
+			a.City = strings.TrimSpace(cityPrefix.ReplaceAllString(p, ""))
+		case a.City == "" && (hasPrefix(p, settlementPref) || isCity(p)):
+			a.City = p
+		case hasWord(p, streetWords):
+			appendStreet(p)
+		case houseNumber.MatchString(p):
+			// Номер дома, корпус, офис — продолжение улицы
+			appendStreet(p)
+		default:
+			rest = append(rest, p)
+		}
+	}
+
+	// Без единой узнанной части адрес не разбирается: город и улицу
+	// нельзя отличить от названия площадки или свободного описания
+	if a.Empty() {
+		return a, false
+	}
+	// Город и улица по догадке — обычное дело ("Mountain View",
+	// "Musterstraße 1"); регион по догадке или лишние части — нет
+	sure := true
+	for _, p := range rest {
+		switch {
+		case a.City == "" && !hasDigit(p):
+			a.City = p
+		case a.Street == "":
+			a.Street = p
+		case a.Region == "" && !hasDigit(p):
+			a.Region = p
+			sure = false
+		default:
+			appendStreet(p)
+			sure = false
+		}
+	}
+	if a.Country == "" && a.City != "" {
+		if c, ok := country.City(a.City); ok {
+			a.Country = c.Code
+		}
+	}
+	return a, sure
+}
+
+// isCountry узнает страну по названию, коду или псевдониму. Двухбуквенный
+// код в середине адреса страной не считается: это обычно штат (CA, NY).
+func isCountry(p string, last bool) bool {
+	if _, ok := country.ByCode(p); ok && len(strings.TrimSpace(p)) == 2 && !last {
+		return false
+	}
+	_, ok := country.ByName(p)
+	return ok
+}
+
+func isCity(p string) bool {
+	_, ok := country.City(p)
+	return ok
+}
+
+// hasWord ищет среди слов части одно из words без учета регистра и точек
+func hasWord(p string, words []string) bool {
+	for _, w := range strings.FieldsFunc(strings.ToLower(p), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '.'
+	}) {
+		for _, word := range words {
+			if w == word {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasPrefix(p string, prefixes []string) bool {
+	lower := strings.ToLower(p)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(lower, prefix+" ") || strings.HasPrefix(lower, prefix) && strings.HasSuffix(prefix, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
@@ -0,0 +1,52 @@
+package address
+
+import (
+	"cursovay/internal/model"
+	"testing"
+)
+
+func TestJoinLeavesOutCountry(t *testing.T) {
+	m := &model.Manufacturer{Country: "RU", City: "Ярославль", Street: "ул. Ленина, д. 5", PostalCode: "150000"}
+	Join(m)
+	if want := "ул. Ленина, д. 5, Ярославль, 150000"; m.Address != want {
+		t.Errorf("Join: %q, ожидалось %q", m.Address, want)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name, address, country string
+		wantSplit              bool
+		want                   Address // части адреса и страна записи после разбора
+	}{
+		{
+			"русский адрес", "150000, Россия, Ярославская обл., г. Ярославль, ул. Ленина, д. 5", "",
+			true, Address{Street: "ул. Ленина, д. 5", City: "Ярославль", Region: "Ярославская обл.", PostalCode: "150000", Country: "RU"},
+		},
+		{
+			"город по догадке", "1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA", "US",
+			true, Address{Street: "1600 Amphitheatre Pkwy", City: "Mountain View", Region: "CA", PostalCode: "94043", Country: "US"},
+		},
+		{
+			"улица по догадке", "Musterstraße 1, 10115 Berlin, Germany", "Germany",
+			true, Address{Street: "Musterstraße 1", City: "Berlin", PostalCode: "10115", Country: "Germany"},
+		},
+		{"свободный текст", "Склад у вокзала", "RU", false, Address{Country: "RU"}},
+		{"лишние части", "Промзона, корпус Б, северный въезд, 150000", "RU", false, Address{Country: "RU"}},
+		{"другая страна", "Musterstraße 1, 10115 Berlin, Germany", "FR", false, Address{Country: "FR"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model.Manufacturer{Address: tt.address, Country: tt.country}
+			if got := Split(m); got != tt.wantSplit {
+				t.Fatalf("Split = %v, ожидалось %v", got, tt.wantSplit)
+			}
+			if got := Of(m); got != tt.want {
+				t.Errorf("части адреса %+v, ожидалось %+v", got, tt.want)
+			}
+			if !tt.wantSplit && m.Address != tt.address {
+				t.Errorf("исходный адрес изменился: %q", m.Address)
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+// Package api — локальный HTTP-сервер с JSON API поверх ManufacturerController.
+// Обработчик из NewServer можно проверять через net/http/httptest.
+package api
+
+import (
+	"cursovay/internal/controller"
+	"cursovay/internal/model"
+	"cursovay/internal/query"
+	"cursovay/internal/repository"
+	"cursovay/internal/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server обслуживает запросы к одному контроллеру.
+// Изменяющие запросы выполняются по одному, и после каждого
+// текущий файл контроллера сохраняется.
+type Server struct {
+	ctrl *controller.ManufacturerController
+	mux  *http.ServeMux
+	mu   sync.Mutex
+}
+
+// NewServer создает обработчик со всеми маршрутами API:
+//
+//	GET    /api/manufacturers              список (q — запрос, фильтры по полям, sort, order)
+//	POST   /api/manufacturers              добавить
+//	GET    /api/manufacturers/{id}         одна запись
+//	PUT    /api/manufacturers/{id}         заменить запись
+//	DELETE /api/manufacturers/{id}         удалить
+//	GET    /api/charts/{type}              PNG графика (color, values, sorted, group)
+//	GET    /api/export/{format}            выгрузка pdf, json, csv или xlsx
+func NewServer(ctrl *controller.ManufacturerController) *Server {
+	s := &Server{ctrl: ctrl, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /api/manufacturers", s.handleList)
+	s.mux.HandleFunc("POST /api/manufacturers", s.handleCreate)
+	s.mux.HandleFunc("GET /api/manufacturers/{id}", s.handleGet)
+	s.mux.HandleFunc("PUT /api/manufacturers/{id}", s.handleUpdate)
+	s.mux.HandleFunc("DELETE /api/manufacturers/{id}", s.handleDelete)
+	s.mux.HandleFunc("GET /api/charts/{type}", s.handleChart)
+	s.mux.HandleFunc("GET /api/export/{format}", s.handleExport)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleList поддерживает параметры:
+// q — запрос на языке пакета query, <поле>=текст — подстрока в поле,
+// sort — поля сортировки через запятую (минус перед полем — по убыванию),
+// order=desc — обратить направление всех полей
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	manufacturers := s.ctrl.GetCurrentData()
+	fields := s.ctrl.Fields()
+	if q := params.Get("q"); q != "" {
+		found, err := s.ctrl.Search(q)
+		var syntaxErr *query.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		manufacturers = found
+	}
+
+	for name, values := range params {
+		switch name {
+		case "q", "sort", "order":
+			continue
+		}
+		field, ok := model.LookupField(fields, name)
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("неизвестное поле для поиска: %s", name))
+			return
+		}
+		for _, value := range values {
+			filtered, err := repository.ApplyQuery(manufacturers, repository.Query{Column: field.Key, Text: value, Fields: fields})
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			manufacturers = filtered
+		}
+	}
+
+	if sortBy := params.Get("sort"); sortBy != "" {
+		order := strings.ToLower(params.Get("order"))
+		if order != "" && order != "asc" && order != "desc" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("order должен быть asc или desc"))
+			return
+		}
+		keys, err := repository.ParseSortKeys(sortBy, fields)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if order == "desc" {
+			for i := range keys {
+				keys[i].Ascending = !keys[i].Ascending
+			}
+		}
+		sorted, err := repository.SortManufacturers(manufacturers, keys, fields)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		manufacturers = sorted
+	}
+
+	if manufacturers == nil {
+		manufacturers = []model.Manufacturer{}
+	}
+	writeJSON(w, http.StatusOK, manufacturers)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	m, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var m model.Manufacturer
+	if !decodeManufacturer(w, r, &m) {
+		return
+	}
+
+	// ID всегда назначает контроллер
+	m.ID = 0
+	if err := s.ctrl.AddManufacturer(&m); err != nil {
+		writeSaveError(w, err)
+		return
+	}
+	if err := s.save(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/manufacturers/%d", m.ID))
+	writeJSON(w, http.StatusCreated, m)
+}
+
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	existing, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var m model.Manufacturer
+	if !decodeManufacturer(w, r, &m) {
+		return
+	}
+	if m.ID != 0 && m.ID != existing.ID {
+		writeError(w, http.StatusBadRequest, errors.New("ID в теле запроса не совпадает с ID в адресе"))
+		return
+	}
+
+	m.ID = existing.ID
+	if err := s.ctrl.UpdateManufacturer(&m); err != nil {
+		writeSaveError(w, err)
+		return
+	}
+	if err := s.save(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	m, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	if err := s.ctrl.DeleteManufacturer(m.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := s.save(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// save записывает изменение в текущий файл: у API нет отдельной команды
+// сохранения. Без файла (новая база) данные остаются только в памяти.
+// Если записать не удалось, изменение отменяется: клиент получает ошибку,
+// и в памяти не должно остаться того, чего нет в файле. Запросы
+// выполняются по одному (s.mu), поэтому отменяется именно это изменение.
+func (s *Server) save() error {
+	path := s.ctrl.GetCurrentFile()
+	if path == "" {
+		return nil
+	}
+	if err := s.ctrl.SaveToFile(path); err != nil {
+		if _, undoErr := s.ctrl.Undo(); undoErr != nil {
+			return fmt.Errorf("не удалось сохранить %s: %v; изменение не отменено: %v", path, err, undoErr)
+		}
+		return fmt.Errorf("не удалось сохранить %s: %v", path, err)
+	}
+	return nil
+}
+
+// handleChart отдает график в PNG. Тип можно указывать с расширением:
+// /api/charts/revenue_bar.png
+func (s *Server) handleChart(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	chartType := strings.TrimSuffix(r.PathValue("type"), ".png")
+
+	colorScheme := params.Get("color")
+	if colorScheme == "" {
+		colorScheme = "Default"
+	}
+	showValues, err := boolParam(params.Get("values"), false)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	sortData, err := boolParam(params.Get("sorted"), true)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch chartType {
+	case "revenue_bar", "founded_bar", "product_pie", "revenue_line", "revenue_growth", "revenue_cagr", "city_bar":
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown chart type: %s", chartType))
+		return
+	}
+
+	png, err := s.ctrl.GenerateChart(map[string]interface{}{
+		"type":        chartType,
+		"colorScheme": colorScheme,
+		"showValues":  showValues,
+		"sortData":    sortData,
+		"groupBy":     params.Get("group"),
+	})
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(png)
+}
+
+// handleExport выгружает данные через экспорт контроллера во временный файл
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(r.PathValue("format"))
+
+	var export func(string) error
+	var contentType string
+	switch format {
+	case "pdf":
+		export, contentType = s.ctrl.ExportToPDF, "application/pdf"
+	case "json":
+		export, contentType = s.ctrl.ExportToJSON, "application/json"
+	case "csv":
+		export, contentType = s.ctrl.ExportToCSV, "text/csv; charset=utf-8"
+	case "xlsx":
+		export, contentType = s.ctrl.ExportToXLSX, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("неизвестный формат экспорта: %s", format))
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "manufacturers-export")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	name := "manufacturers." + format
+	path := filepath.Join(dir, name)
+	if err := export(path); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Write(data)
+}
+
+// lookup находит запись по {id} из адреса или пишет ответ с ошибкой
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (*model.Manufacturer, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("неверный ID: %s", r.PathValue("id")))
+		return nil, false
+	}
+	m, err := s.ctrl.GetManufacturerByID(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	return m, true
+}
+
+// decodeManufacturer читает запись из тела запроса. Правила проверки
+// применяет контроллер при сохранении.
+func decodeManufacturer(w http.ResponseWriter, r *http.Request, m *model.Manufacturer) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(m); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("неверный JSON: %v", err))
+		return false
+	}
+	return true
+}
+
+func boolParam(value string, def bool) (bool, error) {
+	if value == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("ожидается true или false: %q", value)
+	}
+	return b, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	encoder.Encode(v)
+}
+
+// writeSaveError отвечает на ошибку сохранения записи. Нарушения правил
+// проверки возвращаются все сразу: {"error": "...", "violations": [...]}.
+func writeSaveError(w http.ResponseWriter, err error) {
+	var violations validation.Violations
+	if errors.As(err, &violations) {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":      err.Error(),
+			"violations": violations,
+		})
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+// writeError отвечает объектом {"error": "..."}
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"cursovay/internal/controller"
+	"cursovay/internal/model"
+	"cursovay/internal/repository"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestServer открывает файл с тремя записями и возвращает сервер
+// над ним и путь к файлу
+func newTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "manufacturers.csv")
+	c := controller.NewManufacturerController(repository.NewMemoryStore(nil))
+	c.SetManufacturers([]model.Manufacturer{
+		{ID: 1, Name: "Alpha", Country: "RU", FoundedYear: 1990, Revenue: 10, ProductType: "Dye"},
+		{ID: 2, Name: "Beta", Country: "DE", FoundedYear: 1995, Revenue: 30, ProductType: "Ink"},
+		{ID: 3, Name: "Gamma", Country: "FR", FoundedYear: 2001, Revenue: 20, ProductType: "Dye"},
+	})
+	if err := c.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	c = controller.NewManufacturerController(repository.NewMemoryStore(nil))
+	if _, err := c.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	return NewServer(c), path
+}
+
+func serve(s *Server, method, target, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestList(t *testing.T) {
+	s, _ := newTestServer(t)
+	tests := []struct {
+		target     string
+		wantStatus int
+		wantIDs    []int
+	}{
+		{"/api/manufacturers", http.StatusOK, []int{1, 2, 3}},
+		{"/api/manufacturers?q=productType:dye", http.StatusOK, []int{1, 3}},
+		{"/api/manufacturers?q=revenue%3E15", http.StatusOK, []int{2, 3}},
+		{"/api/manufacturers?country=Germany", http.StatusOK, []int{2}},
+		{"/api/manufacturers?productType=dye&name=gam", http.StatusOK, []int{3}},
+		{"/api/manufacturers?sort=-revenue", http.StatusOK, []int{2, 3, 1}},
+		{"/api/manufacturers?sort=productType,name&order=desc", http.StatusOK, []int{2, 3, 1}},
+		{"/api/manufacturers?q=name:zzz", http.StatusOK, []int{}},
+		{"/api/manufacturers?q=revenue%3E", http.StatusBadRequest, nil},
+		{"/api/manufacturers?unknown=1", http.StatusBadRequest, nil},
+		{"/api/manufacturers?sort=unknown", http.StatusBadRequest, nil},
+		{"/api/manufacturers?sort=name&order=up", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		w := serve(s, http.MethodGet, tt.target, "")
+		if w.Code != tt.wantStatus {
+			t.Errorf("GET %s: статус %d, ожидался %d: %s", tt.target, w.Code, tt.wantStatus, w.Body)
+			continue
+		}
+		if tt.wantIDs == nil {
+			continue
+		}
+		var got []model.Manufacturer
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Errorf("GET %s: %v", tt.target, err)
+			continue
+		}
+		ids := []int{}
+		for _, m := range got {
+			ids = append(ids, m.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.wantIDs) {
+			t.Errorf("GET %s: записи %v, ожидались %v", tt.target, ids, tt.wantIDs)
+		}
+	}
+}
+
+func TestRecordHandlers(t *testing.T) {
+	tests := []struct {
+		method, target, body string
+		wantStatus           int
+	}{
+		{http.MethodGet, "/api/manufacturers/2", "", http.StatusOK},
+		{http.MethodGet, "/api/manufacturers/9", "", http.StatusNotFound},
+		{http.MethodGet, "/api/manufacturers/abc", "", http.StatusBadRequest},
+		{http.MethodPost, "/api/manufacturers", `{"name": "Delta", "country": "US", "founded_year": 2010}`, http.StatusCreated},
+		{http.MethodPost, "/api/manufacturers", `{"name": "Delta", "founded_year": 1700}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/api/manufacturers", `{"name": "Delta", "unknown": 1}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/manufacturers/1", `{"name": "Alpha 2", "country": "RU", "founded_year": 1990}`, http.StatusOK},
+		{http.MethodPut, "/api/manufacturers/1", `{"id": 2, "name": "Alpha 2", "founded_year": 1990}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/manufacturers/1", `{"name": "", "founded_year": 1990}`, http.StatusUnprocessableEntity},
+		{http.MethodPut, "/api/manufacturers/9", `{"name": "Omega", "founded_year": 1990}`, http.StatusNotFound},
+		{http.MethodDelete, "/api/manufacturers/3", "", http.StatusNoContent},
+		{http.MethodDelete, "/api/manufacturers/9", "", http.StatusNotFound},
+		{http.MethodGet, "/api/charts/unknown", "", http.StatusNotFound},
+		{http.MethodGet, "/api/export/doc", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		s, _ := newTestServer(t)
+		w := serve(s, tt.method, tt.target, tt.body)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s %s: статус %d, ожидался %d: %s", tt.method, tt.target, w.Code, tt.wantStatus, w.Body)
+		}
+	}
+}
+
+// Изменения через API сразу сохраняются в текущий файл
+func TestChangesAreSaved(t *testing.T) {
+	s, path := newTestServer(t)
+
+	w := serve(s, http.MethodPost, "/api/manufacturers", `{"id": 7, "name": "Delta", "country": "US", "founded_year": 2010}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST: статус %d: %s", w.Code, w.Body)
+	}
+	var created model.Manufacturer
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 4 || w.Header().Get("Location") != "/api/manufacturers/4" {
+		t.Errorf("создана запись %d, Location %q", created.ID, w.Header().Get("Location"))
+	}
+	if w := serve(s, http.MethodDelete, "/api/manufacturers/1", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE: статус %d: %s", w.Code, w.Body)
+	}
+
+	c := controller.NewManufacturerController(repository.NewMemoryStore(nil))
+	data, err := c.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	var names []string
+	for _, m := range data {
+		names = append(names, m.Name)
+	}
+	if want := []string{"Beta", "Gamma", "Delta"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("в файле %v, ожидалось %v", names, want)
+	}
+}
+
+// Если файл не удалось записать, изменение отменяется и в памяти
+// остаются данные файла
+func TestFailedSaveIsUndone(t *testing.T) {
+	tests := []struct {
+		method, target, body string
+	}{
+		{http.MethodPost, "/api/manufacturers", `{"name": "Delta", "country": "US", "founded_year": 2010}`},
+		{http.MethodPut, "/api/manufacturers/1", `{"name": "Alpha 2", "country": "RU", "founded_year": 1990}`},
+		{http.MethodDelete, "/api/manufacturers/3", ""},
+	}
+	for _, tt := range tests {
+		s, path := newTestServer(t)
+		// На месте файла каталог: записать файл не получится
+		if err := os.Remove(path); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Mkdir(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if w := serve(s, tt.method, tt.target, tt.body); w.Code != http.StatusInternalServerError {
+			t.Errorf("%s %s: статус %d, ожидался 500: %s", tt.method, tt.target, w.Code, w.Body)
+		}
+		var names []string
+		for _, m := range s.ctrl.GetCurrentData() {
+			names = append(names, m.Name)
+		}
+		if want := []string{"Alpha", "Beta", "Gamma"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("%s %s: в памяти %v, ожидалось %v", tt.method, tt.target, names, want)
+		}
+		if s.ctrl.HasUnsavedChanges() {
+			t.Errorf("%s %s: остались несохраненные изменения", tt.method, tt.target)
+		}
+	}
+}
+
+func TestViolationsResponse(t *testing.T) {
+	s, _ := newTestServer(t)
+	w := serve(s, http.MethodPost, "/api/manufacturers", `{"name": "", "founded_year": 1700}`)
+	var body struct {
+		Error      string `json:"error"`
+		Violations []struct {
+			Field string `json:"field"`
+			Check string `json:"check"`
+		} `json:"violations"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%v: %s", err, w.Body)
+	}
+	if w.Code != http.StatusUnprocessableEntity || body.Error == "" || len(body.Violations) != 2 {
+		t.Errorf("статус %d, ответ %s", w.Code, w.Body)
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	s, _ := newTestServer(t)
+	w := serve(s, http.MethodGet, "/api/export/JSON", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("статус %d: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "Gamma") {
+		t.Errorf("в выгрузке нет записей: %s", w.Body)
+	}
+}
@@ -0,0 +1,365 @@
+// Package cli реализует консольную утилиту manufacturers, которая работает
+// с тем же ManufacturerController, что и окно Fyne, но без графики.
+package cli
+
+import (
+	"cursovay/internal/controller"
+	"cursovay/internal/currency"
+	"cursovay/internal/query"
+	"cursovay/internal/repository"
+	"cursovay/internal/validation"
+	"cursovay/pkg/config"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Коды завершения утилиты
+const (
+	ExitOK      = 0
+	ExitError   = 1 // ошибка чтения, записи или экспорта
+	ExitUsage   = 2 // неверные аргументы
+	ExitInvalid = 3 // запись не прошла проверку
+)
+
+const usageText = `Использование: manufacturers -file <data.csv> [-json] <команда> [аргументы]
+
+Команды:
+  list                              все записи
+  get <id>                          одна запись
+  add поле=значение ...             добавить запись
+  update <id> поле=значение ...     изменить поля записи
+  delete <id>                       удалить запись
+  search <запрос>                   поиск, например:
+                                    'country:Russia revenue>1000 founded:1990..2005 -productType:Dye "фраза"'
+  search -column поле <текст>       поиск подстроки в одном поле
+  sort [-desc] [-save] <поля>       сортировка по нескольким полям через запятую,
+                                    минус — по убыванию: country,-revenue
+                                    (с -save порядок записывается в файл)
+  export pdf|json|csv|xlsx <файл>   экспорт
+  export mailing <файл>             список рассылки в CSV: по строке на каждый контакт
+                                    с email и на общий email производителя
+  mailing [запрос]                  список рассылки без записи в файл
+  import [-delimiter ;] [-encoding Windows-1251] [-sheet лист] [-header=false]
+         [-map "Заголовок=поле,..."] [-report отчет.csv] <файл.csv|.xlsx>
+                                    импорт CSV или Excel поставщика; разделитель,
+                                    кодировка и заголовок определяются автоматически;
+                                    если какие-то строки отклонены, файл не меняется (код 3)
+  import [-mode append|replace|merge] [-report отчет.csv] <файл.json|.jsonl>
+                                    импорт JSON (массив как в export json или JSON Lines);
+                                    merge обновляет записи с тем же ID; replace
+                                    отменяется, если хоть одна запись не прошла проверку
+  chart [-type тип] [-color схема] [-values] [-group manufacturer|productType] <файл.png>
+                                    график (revenue_bar, founded_bar, product_pie, city_bar);
+                                    по истории выручки — revenue_line (выручка по годам),
+                                    revenue_growth (рост к прошлому году), revenue_cagr
+                                    (CAGR) по производителям или типам продукции
+  merge [-by id|name|email] [-prefer base|other] [-drop-missing] [-dry-run] <файл>
+                                    слить второй файл в текущий: + новые, ~ конфликты
+                                    (база | второй файл), - удаляемые записи
+  diff [-u] <файл>                  различия по полям: текущий файл против другого
+                                    (другой файл только читается); -u — отчет в виде
+                                    unified diff
+  duplicates [-threshold 0.9] [-no-email] [-no-phone] [-no-website] [-merge]
+                                    группы вероятных дубликатов; с -merge каждая группа
+                                    сливается в запись с наименьшим ID
+  normalize-phones                  привести телефоны к E.164 (+79991234567); регион
+                                    номеров без кода страны — по стране записи
+  normalize-countries               заменить страны кодами ISO 3166: Россия, Russia,
+                                    Yaroslavl -> RU; нераспознанные остаются как есть
+  countries [-lang ru|en] [текст]   справочник стран ISO 3166 с поиском по названию
+  split-addresses                   разобрать адреса одной строкой на улицу, город,
+                                    регион и индекс
+  total [запрос]                    итог выручки в валюте отчетов, по типам продукции
+                                    и в исходных валютах
+  product-types                     справочник типов продукции с числом производителей
+  product-types add|remove <тип>    добавить тип или удалить неиспользуемый
+  product-types rename <тип> <новое название>
+                                    переименовать тип во всех записях
+  product-types merge <в тип> <тип>...
+                                    объединить типы во всех записях
+  tags                              теги записей с числом производителей
+  custom-fields                     пользовательские поля файла
+  custom-fields add <ключ> <тип> [название] [значения...]
+                                    добавить поле типа text, number, date, enum или bool;
+                                    для enum — список допустимых значений
+  custom-fields remove <ключ>       удалить поле вместе с его значениями
+  validate                          проверить все записи по правилам; код 3, если есть ошибки
+  serve [-addr адрес]               HTTP API (по умолчанию 127.0.0.1:8080)
+
+Поля: id, name, country, address, phone, email, productType, foundedYear,
+revenue, employees, website, street, city, region, postalCode, history,
+currency, contacts, tags (также заголовки CSV и русские названия). История выручки задается строкой
+"2019=1200.50/45; 2020=1310/47" (год=выручка/сотрудники); revenue
+и employees — показатели последнего года истории. Адрес, заданный без частей адреса,
+разбирается на улицу, город, регион и индекс; при заданных частях address
+собирается из них.
+Контакты задаются списком JSON: contacts='[{"name":"Иванов","role":"Продажи",
+"phone":"+79991234567","email":"sales@example.ru","notes":"..."}]'; у каждого
+контакта должны быть имя и телефон или email.
+У производителя может быть несколько типов продукции через запятую:
+productType="Dye, Ink"; новые типы попадают в справочник файла,
+productType=Dye находит всех производителей, выпускающих Dye, а на круговой
+диаграмме и в итогах производитель учитывается в каждом своем типе.
+Теги задаются через запятую: tags="preferred, ISO9001"; в поиске tags=preferred
+находит записи с этим тегом, tags:iso — с тегом, содержащим подстроку.
+Пользовательские поля объявляются в каждом файле командой custom-fields
+и используются как встроенные: contract=2025-12-31, поиск contract<2026-01-01,
+сортировка и экспорт. Даты — ГГГГ-ММ-ДД или ДД.ММ.ГГГГ, bool — да/нет.
+Файл можно задать переменной окружения MANUFACTURERS_FILE, файл правил
+проверки — флагом -rules или переменной MANUFACTURERS_RULES (по умолчанию
+rules.json в каталоге настроек или rules_file из config.json).
+Выручка записи — в валюте currency (RUB, EUR, USD; пусто — базовая валюта
+курсов). Сортировка по выручке, графики, PDF и total пересчитывают ее
+в валюту отчетов (-currency, MANUFACTURERS_CURRENCY или currency из
+config.json) по файлу курсов (-rates, MANUFACTURERS_RATES, rates_file или
+rates.csv в каталоге настроек): CSV "date,currency,rate", где rate —
+стоимость единицы валюты в базовой валюте, заданной строкой "# base: RUB".
+`
+
+// usageError — неверные аргументы команды
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// validationError — данные записи не прошли проверку
+type validationError struct {
+	err error
+}
+
+func (e *validationError) Error() string { return "ошибка проверки: " + e.err.Error() }
+
+// app — общее состояние при выполнении одной команды
+type app struct {
+	ctrl       *controller.ManufacturerController
+	file       string
+	jsonOutput bool
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+type commandFunc func(a *app, args []string) error
+
+var commands = map[string]commandFunc{
+	"list":                runList,
+	"get":                 runGet,
+	"add":                 runAdd,
+	"update":              runUpdate,
+	"delete":              runDelete,
+	"search":              runSearch,
+	"sort":                runSort,
+	"export":              runExport,
+	"chart":               runChart,
+	"serve":               runServe,
+	"import":              runImport,
+	"mailing":             runMailing,
+	"merge":               runMerge,
+	"diff":                runDiff,
+	"duplicates":          runDuplicates,
+	"validate":            runValidate,
+	"normalize-phones":    runNormalizePhones,
+	"normalize-countries": runNormalizeCountries,
+	"countries":           runCountries,
+	"split-addresses":     runSplitAddresses,
+	"total":               runTotal,
+	"tags":                runTags,
+	"custom-fields":       runCustomFields,
+	"product-types":       runProductTypes,
+}
+
+// writingCommands меняют данные: после их успешного выполнения файл
+// сохраняется, если в нем есть несохраненные изменения. Значение сообщает
+// по аргументам команды, меняет ли она данные: у duplicates,
+// custom-fields и product-types есть подкоманды, которые только выводят
+// список, и они файл не перезаписывают.
+var writingCommands = map[string]func(args []string) bool{
+	"add":                 always,
+	"update":              always,
+	"delete":              always,
+	"import":              always,
+	"merge":               always,
+	"duplicates":          func(args []string) bool { return hasFlag(args, "merge") },
+	"normalize-phones":    always,
+	"normalize-countries": always,
+	"split-addresses":     always,
+	"custom-fields":       func(args []string) bool { return len(args) > 0 },
+	"product-types":       func(args []string) bool { return len(args) > 0 },
+}
+
+func always([]string) bool { return true }
+
+// hasFlag сообщает, включен ли в args логический флаг name
+// (-name, --name или -name=true); как и в flag, действует последнее значение
+func hasFlag(args []string, name string) bool {
+	on := false
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		arg = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if arg == name {
+			on = true
+		} else if value := strings.TrimPrefix(arg, name+"="); value != arg {
+			on, _ = strconv.ParseBool(value)
+		}
+	}
+	return on
+}
+
+// Run выполняет команду и возвращает код завершения процесса
+func Run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("manufacturers", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("file", os.Getenv("MANUFACTURERS_FILE"), "CSV-файл или база .db")
+	jsonOutput := flags.Bool("json", false, "выводить результат в формате JSON")
+	rulesFile := flags.String("rules", os.Getenv("MANUFACTURERS_RULES"), "файл правил проверки (JSON)")
+	ratesFile := flags.String("rates", os.Getenv("MANUFACTURERS_RATES"), "файл курсов валют (CSV)")
+	reportCurrency := flags.String("currency", os.Getenv("MANUFACTURERS_CURRENCY"), "валюта отчетов: RUB, EUR, USD")
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usageText)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	name := flags.Arg(0)
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "неизвестная команда: %s (доступны: %s)\n", name, commandNames())
+		return ExitUsage
+	}
+	if *file == "" {
+		fmt.Fprintln(stderr, "не указан файл данных: используйте -file или MANUFACTURERS_FILE")
+		return ExitUsage
+	}
+
+	ctrl, err := openFile(*file)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	rules, err := loadRules(*rulesFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "не удалось загрузить правила проверки: %v\n", err)
+		return ExitError
+	}
+	ctrl.SetRules(rules)
+	rates, display, err := loadRates(*ratesFile, *reportCurrency)
+	if err != nil {
+		fmt.Fprintf(stderr, "не удалось загрузить курсы валют: %v\n", err)
+		return ExitError
+	}
+	if err := ctrl.SetCurrency(rates, display); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	a := &app{ctrl: ctrl, file: *file, jsonOutput: *jsonOutput, stdout: stdout, stderr: stderr}
+	// Команда, вернувшая ошибку, файл не меняет: даже если часть данных
+	// уже изменена (импорт отклонил часть строк), в файл ничего не пишется
+	if err := run(a, flags.Args()[1:]); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return exitCode(err)
+	}
+	if writes, ok := writingCommands[name]; ok && writes(flags.Args()[1:]) && ctrl.HasUnsavedChanges() {
+		if err := ctrl.SaveToFile(*file); err != nil {
+			fmt.Fprintf(stderr, "%s: не удалось сохранить %s: %v\n", name, *file, err)
+			return ExitError
+		}
+	}
+	return ExitOK
+}
+
+// openFile загружает файл в новый контроллер. Несуществующий файл считается
+// новой пустой базой и создается после первой команды, изменившей данные.
+func openFile(path string) (*controller.ManufacturerController, error) {
+	ctrl := controller.NewManufacturerController(repository.NewMemoryStore(nil))
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		ctrl.NewDatabase()
+		ctrl.SetCurrentFile(path)
+		return ctrl, nil
+	}
+	if _, err := ctrl.LoadFromFile(path); err != nil {
+		return nil, fmt.Errorf("не удалось открыть %s: %v", path, err)
+	}
+	return ctrl, nil
+}
+
+// loadRules читает правила проверки из path, а без него — из файла,
+// указанного в настройках приложения. Если файла нет, возвращает nil:
+// действуют правила по умолчанию.
+func loadRules(path string) (*validation.RuleSet, error) {
+	if path != "" {
+		return validation.Load(path)
+	}
+	cfg, _ := config.LoadConfig()
+	rules, err := validation.Load(cfg.RulesPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return rules, err
+}
+
+// loadRates читает курсы валют из path, а без него — из файла, указанного
+// в настройках приложения; валюта отчетов без display берется из настроек.
+// Если файла курсов нет, возвращает nil: пересчитывается только базовая
+// валюта.
+func loadRates(path, display string) (*currency.Table, string, error) {
+	cfg, _ := config.LoadConfig()
+	if display == "" {
+		display = cfg.Currency
+	}
+	if path != "" {
+		rates, err := currency.Load(path)
+		return rates, display, err
+	}
+	rates, err := currency.Load(cfg.RatesPath())
+	if os.IsNotExist(err) {
+		return nil, display, nil
+	}
+	return rates, display, err
+}
+
+func exitCode(err error) int {
+	var usageErr *usageError
+	var validErr *validationError
+	var violations validation.Violations
+	var syntaxErr *query.SyntaxError
+	switch {
+	case errors.As(err, &usageErr), errors.As(err, &syntaxErr):
+		return ExitUsage
+	case errors.As(err, &validErr), errors.As(err, &violations):
+		return ExitInvalid
+	default:
+		return ExitError
+	}
+}
+
+func commandNames() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
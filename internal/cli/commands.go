@@ -0,0 +1,1006 @@
+package cli
+
+import (
+	"cursovay/internal/address"
+	"cursovay/internal/api"
+	"cursovay/internal/controller"
+	"cursovay/internal/country"
+	"cursovay/internal/currency"
+	"cursovay/internal/dedup"
+	"cursovay/internal/diff"
+	"cursovay/internal/importer"
+	"cursovay/internal/merge"
+	"cursovay/internal/model"
+	"cursovay/internal/repository"
+	"cursovay/internal/validation"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+func runList(a *app, args []string) error {
+	if len(args) != 0 {
+		return usagef("list не принимает аргументов")
+	}
+	return a.printList(a.ctrl.GetCurrentData())
+}
+
+func runGet(a *app, args []string) error {
+	if len(args) != 1 {
+		return usagef("использование: get <id>")
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	m, err := a.ctrl.GetManufacturerByID(id)
+	if err != nil {
+		return err
+	}
+	return a.printOne(m)
+}
+
+func runAdd(a *app, args []string) error {
+	if len(args) == 0 {
+		return usagef("использование: add поле=значение ...")
+	}
+	m := &model.Manufacturer{}
+	if err := setFields(m, args, false, a.ctrl.Fields()); err != nil {
+		return err
+	}
+	a.printWarnings(a.ctrl.ValidateManufacturer(m))
+	if err := a.ctrl.AddManufacturer(m); err != nil {
+		return err
+	}
+	return a.printOne(m)
+}
+
+func runUpdate(a *app, args []string) error {
+	if len(args) < 2 {
+		return usagef("использование: update <id> поле=значение ...")
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	m, err := a.ctrl.GetManufacturerByID(id)
+	if err != nil {
+		return err
+	}
+	if err := setFields(m, args[1:], true, a.ctrl.Fields()); err != nil {
+		return err
+	}
+	a.printWarnings(a.ctrl.ValidateManufacturer(m))
+	if err := a.ctrl.UpdateManufacturer(m); err != nil {
+		return err
+	}
+	return a.printOne(m)
+}
+
+func runDelete(a *app, args []string) error {
+	if len(args) != 1 {
+		return usagef("использование: delete <id>")
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := a.ctrl.GetManufacturerByID(id); err != nil {
+		return err
+	}
+	return a.ctrl.DeleteManufacturer(id)
+}
+
+func runSearch(a *app, args []string) error {
+	flags := newFlagSet("search")
+	column := flags.String("column", "", "искать только в этом поле")
+	if err := flags.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if flags.NArg() != 1 {
+		return usagef("использование: search <запрос> или search -column поле <текст>")
+	}
+
+	text := flags.Arg(0)
+	if *column == "" {
+		result, err := a.ctrl.Search(text)
+		if err != nil {
+			return err
+		}
+		return a.printList(result)
+	}
+
+	result, err := repository.ApplyQuery(a.ctrl.GetCurrentData(), repository.Query{Column: *column, Text: text})
+	if err != nil {
+		return usagef("%v", err)
+	}
+	return a.printList(result)
+}
+
+func runSort(a *app, args []string) error {
+	flags := newFlagSet("sort")
+	desc := flags.Bool("desc", false, "по убыванию")
+	save := flags.Bool("save", false, "записать новый порядок в файл")
+	if err := flags.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if flags.NArg() != 1 {
+		return usagef("использование: sort [-desc] [-save] <поле>[,-поле...]")
+	}
+
+	keys, err := repository.ParseSortKeys(flags.Arg(0), a.ctrl.Fields())
+	if err != nil {
+		return usagef("%v", err)
+	}
+	if *desc {
+		for i := range keys {
+			keys[i].Ascending = !keys[i].Ascending
+		}
+	}
+	sorted, err := a.ctrl.SortBy(a.ctrl.GetCurrentData(), keys)
+	if err != nil {
+		return err
+	}
+
+	if *save {
+		if err := a.ctrl.ReplaceManufacturers("Sort", sorted); err != nil {
+			return err
+		}
+		// Порядок записей не считается изменением, поэтому сохраняем явно
+		if err := a.ctrl.SaveToFile(a.file); err != nil {
+			return err
+		}
+	}
+	return a.printList(sorted)
+}
+
+func runExport(a *app, args []string) error {
+	if len(args) != 2 {
+		return usagef("использование: export pdf|json|csv|xlsx|mailing <файл>")
+	}
+
+	format, output := strings.ToLower(args[0]), args[1]
+	switch format {
+	case "pdf":
+		return a.ctrl.ExportToPDF(output)
+	case "json":
+		return a.ctrl.ExportToJSON(output)
+	case "csv":
+		return a.ctrl.ExportToCSV(output)
+	case "xlsx":
+		return a.ctrl.ExportToXLSX(output)
+	case "mailing":
+		_, err := a.ctrl.ExportMailingList(output, a.ctrl.GetCurrentData())
+		return err
+	default:
+		return usagef("неизвестный формат экспорта: %s", format)
+	}
+}
+
+func runChart(a *app, args []string) error {
+	flags := newFlagSet("chart")
+	chartType := flags.String("type", "revenue_bar", "тип графика")
+	colorScheme := flags.String("color", "Default", "цветовая схема (Default, Blue Theme, Green Theme, Rainbow)")
+	showValues := flags.Bool("values", false, "подписывать значения")
+	sortData := flags.Bool("sorted", true, "сортировать данные")
+	group := flags.String("group", controller.GroupByManufacturer, "группировка истории выручки: manufacturer или productType")
+	if err := flags.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if flags.NArg() != 1 {
+		return usagef("использование: chart [-type тип] [-color схема] [-values] [-group manufacturer|productType] <файл.png>")
+	}
+	if *group != controller.GroupByManufacturer && *group != controller.GroupByProductType {
+		return usagef("неизвестная группировка: %s", *group)
+	}
+
+	png, err := a.ctrl.GenerateChart(map[string]interface{}{
+		"type":        *chartType,
+		"colorScheme": *colorScheme,
+		"showValues":  *showValues,
+		"sortData":    *sortData,
+		"groupBy":     *group,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(flags.Arg(0), png, 0644)
+}
+
+func runServe(a *app, args []string) error {
+	flags := newFlagSet("serve")
+	addr := flags.String("addr", "127.0.0.1:8080", "адрес для прослушивания")
+	if err := flags.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if flags.NArg() != 0 {
+		return usagef("использование: serve [-addr адрес]")
+	}
+
+	fmt.Fprintf(a.stdout, "API: http://%s/api/manufacturers\n", *addr)
+	return http.ListenAndServe(*addr, api.NewServer(a.ctrl))
+}
+
+func runImport(a *app, args []string) error {
+	flags := newFlagSet("import")
+	delimiter := flags.String("delimiter", "", "разделитель столбцов (\\t — табуляция)")
+	encoding := flags.String("encoding", "", "кодировка: "+strings.Join(importer.Encodings, ", "))
+	header := flags.String("header", "", "первая строка — заголовок (true или false)")
+	mapping := flags.String("map", "", "сопоставление столбцов: Заголовок=поле,... (поле - — пропустить)")
+	report := flags.String("report", "", "записать отчет об ошибках в CSV-файл")
+	sheet := flags.String("sheet", "", "лист книги Excel (по умолчанию первый)")
+	mode := flags.String("mode", "append", "режим для JSON: append, replace или merge (по ID)")
+	if err := flags.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if flags.NArg() != 1 {
+		return usagef("использование: import [-delimiter d] [-encoding e] [-sheet лист] [-header true|false] [-map ...] [-mode m] [-report файл] <файл.csv|.xlsx|.json|.jsonl>")
+	}
+
+	switch strings.ToLower(filepath.Ext(flags.Arg(0))) {
+	case ".json", ".jsonl":
+		importMode, err := controller.ParseImportMode(*mode)
+		if err != nil {
+			return usagef("%v", err)
+		}
+		result, err := a.ctrl.ImportFromJSON(flags.Arg(0), importMode)
+		if err != nil && result != nil {
+			// Замена отменена из-за отклоненных записей: показываем, каких
+			if reportErr := a.writeImportIssues(result, *report); reportErr != nil {
+				return reportErr
+			}
+			return &validationError{err: err}
+		}
+		if err != nil {
+			return err
+		}
+		return a.reportImport(result, *report)
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var workbook *importer.Workbook
+	if strings.EqualFold(filepath.Ext(flags.Arg(0)), ".xlsx") {
+		workbook, err = importer.OpenWorkbook(data)
+		if err != nil {
+			return err
+		}
+		defer workbook.Close()
+		if *sheet == "" {
+			*sheet = workbook.Sheets()[0]
+		}
+	}
+
+	var format importer.Format
+	if workbook != nil {
+		format = workbook.Sniff(*sheet)
+	} else {
+		format = importer.Sniff(data)
+	}
+	if *delimiter != "" {
+		d := []rune(strings.ReplaceAll(*delimiter, `\t`, "\t"))
+		if len(d) != 1 {
+			return usagef("разделитель должен быть одним символом: %q", *delimiter)
+		}
+		format.Delimiter = d[0]
+	}
+	if *encoding != "" {
+		format.Encoding = *encoding
+	}
+	if *header != "" {
+		hasHeader, err := strconv.ParseBool(*header)
+		if err != nil {
+			return usagef("-header: ожидается true или false")
+		}
+		format.HasHeader = hasHeader
+	}
+
+	var table *importer.Table
+	if workbook != nil {
+		table, err = workbook.ReadSheet(*sheet, format)
+	} else {
+		table, err = importer.ReadTable(data, format)
+	}
+	if err != nil {
+		return err
+	}
+	fields := a.ctrl.Fields()
+	columns := importer.GuessMapping(table, format, fields)
+	if err := applyMapping(columns, table.Header, *mapping, fields); err != nil {
+		return err
+	}
+
+	result, err := importer.Convert(table, columns, fields, a.ctrl.ImportChecker(controller.ImportAppend))
+	if err != nil {
+		return usagef("%v", err)
+	}
+	if err := a.ctrl.ImportManufacturers(result.Manufacturers); err != nil {
+		return err
+	}
+	return a.reportImport(result, *report)
+}
+
+func runMerge(a *app, args []string) error {
+	flags := newFlagSet("merge")
+	by := flags.String("by", "id", "сопоставление записей: id, name (название+страна) или email")
+	prefer := flags.String("prefer", "base", "чья версия побеждает в конфликтах: base или other")
+	dropMissing := flags.Bool("drop-missing", false, "удалить записи, которых нет во втором файле")
+	dryRun := flags.Bool("dry-run", false, "только показать различия")
+	if err := flags.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if flags.NArg() != 1 {
+		return usagef("использование: merge [-by id|name|email] [-prefer base|other] [-drop-missing] [-dry-run] <второй файл>")
+	}
+	matchBy, err := merge.ParseMatchBy(*by)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	var side merge.Side
+	switch *prefer {
+	case "base":
+		side = merge.Base
+	case "other":
+		side = merge.Other
+	default:
+		return usagef("-prefer: ожидается base или other")
+	}
+
+	if _, err := os.Stat(flags.Arg(0)); err != nil {
+		return err
+	}
+	other, err := openFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	plan := merge.Compare(a.ctrl.GetCurrentData(), other.GetCurrentData(), matchBy, a.ctrl.Fields())
+	plan.PreferAll(side)
+	for _, entry := range plan.Removed {
+		entry.Include = !*dropMissing
+	}
+
+	for _, entry := range plan.Added {
+		fmt.Fprintf(a.stdout, "+ %s (%s)\n", entry.Record.Name, entry.Record.Country)
+	}
+	for _, entry := range plan.Removed {
+		mark := "="
+		if !entry.Include {
+			mark = "-"
+		}
+		fmt.Fprintf(a.stdout, "%s %d %s: нет во втором файле\n", mark, entry.Record.ID, entry.Record.Name)
+	}
+	fields := a.ctrl.Fields()
+	for _, pair := range plan.Conflicts() {
+		fmt.Fprintf(a.stdout, "~ %d %s\n", pair.Base.ID, pair.Base.Name)
+		for _, key := range pair.Fields {
+			field, _ := model.LookupField(fields, key)
+			fmt.Fprintf(a.stdout, "    %s: %q | %q\n", field.Header, field.Get(&pair.Base), field.Get(&pair.Other))
+		}
+	}
+	fmt.Fprintf(a.stdout, "совпало %d (конфликтов %d), новых %d, только в базовом %d\n",
+		len(plan.Matched), len(plan.Conflicts()), len(plan.Added), len(plan.Removed))
+
+	if *dryRun {
+		return nil
+	}
+	return a.ctrl.ApplyMerge(plan.Result())
+}
+
+func runDiff(a *app, args []string) error {
+	flags := newFlagSet("diff")
+	unified := flags.Bool("u", false, "вывести отчет в виде unified diff")
+	if err := flags.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	// Утилита только что прочитала файл, поэтому сравнивать его можно
+	// лишь с другим файлом
+	if flags.NArg() != 1 {
+		return usagef("использование: diff [-u] <другой файл>")
+	}
+	changes, err := a.ctrl.DiffFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	oldName, newName := a.file, flags.Arg(0)
+
+	switch {
+	case a.jsonOutput:
+		if changes.Changes == nil {
+			changes.Changes = []diff.RecordChange{}
+		}
+		return a.printJSON(changes.Changes)
+	case *unified:
+		return changes.WriteUnified(a.stdout, oldName, newName)
+	}
+
+	for _, c := range changes.Changes {
+		switch c.Kind {
+		case diff.Added:
+			fmt.Fprintf(a.stdout, "+ %d %s\n", c.ID, c.New.Name)
+		case diff.Removed:
+			fmt.Fprintf(a.stdout, "- %d %s\n", c.ID, c.Old.Name)
+		default:
+			fmt.Fprintf(a.stdout, "~ %d %s\n", c.ID, c.New.Name)
+			for _, f := range c.Fields {
+				fmt.Fprintf(a.stdout, "    %s: %q → %q\n", f.Field, f.Old, f.New)
+			}
+		}
+	}
+	fmt.Fprintf(a.stdout, "добавлено %d, удалено %d, изменено %d\n",
+		changes.Count(diff.Added), changes.Count(diff.Removed), changes.Count(diff.Modified))
+	return nil
+}
+
+func runDuplicates(a *app, args []string) error {
+	opts := dedup.DefaultOptions()
+	flags := newFlagSet("duplicates")
+	flags.Float64Var(&opts.NameSimilarity, "threshold", opts.NameSimilarity, "порог похожести названий от 0 до 1 (0 — не сравнивать)")
+	noEmail := flags.Bool("no-email", false, "не сравнивать email")
+	noPhone := flags.Bool("no-phone", false, "не сравнивать телефоны")
+	noWebsite := flags.Bool("no-website", false, "не сравнивать сайты")
+	doMerge := flags.Bool("merge", false, "слить каждую группу в запись с наименьшим ID")
+	if err := flags.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if flags.NArg() != 0 || opts.NameSimilarity < 0 || opts.NameSimilarity > 1 {
+		return usagef("использование: duplicates [-threshold 0.9] [-no-email] [-no-phone] [-no-website] [-merge]")
+	}
+	opts.MatchEmail, opts.MatchPhone, opts.MatchWebsite = !*noEmail, !*noPhone, !*noWebsite
+
+	groups := a.ctrl.FindDuplicates(opts)
+	if a.jsonOutput {
+		if groups == nil {
+			groups = []dedup.Group{}
+		}
+		if err := a.printJSON(groups); err != nil {
+			return err
+		}
+	} else {
+		for _, g := range groups {
+			fmt.Fprintf(a.stdout, "группа (%s):\n", strings.Join(g.Reasons, ", "))
+			for _, m := range g.Members {
+				fmt.Fprintf(a.stdout, "  %d\t%s\t%s\t%s\t%s\n", m.ID, m.Name, m.Email, m.Phone, m.Website)
+			}
+		}
+		fmt.Fprintf(a.stdout, "найдено групп: %d\n", len(groups))
+	}
+
+	if !*doMerge || len(groups) == 0 {
+		return nil
+	}
+	ids := make([][]int, len(groups))
+	for i, g := range groups {
+		for _, m := range g.Members {
+			ids[i] = append(ids[i], m.ID)
+		}
+	}
+	return a.ctrl.MergeDuplicates(ids)
+}
+
+func runNormalizePhones(a *app, args []string) error {
+	if len(args) != 0 {
+		return usagef("использование: normalize-phones")
+	}
+	changed, invalid, err := a.ctrl.NormalizePhones()
+	if err != nil {
+		return err
+	}
+	if a.jsonOutput {
+		if invalid == nil {
+			invalid = []int{}
+		}
+		return a.printJSON(map[string]interface{}{"changed": changed, "invalid": invalid})
+	}
+	for _, id := range invalid {
+		m, err := a.ctrl.GetManufacturerByID(id)
+		if err != nil {
+			return err
+		}
+		for _, number := range controller.InvalidPhones(m) {
+			fmt.Fprintf(a.stderr, "%d\t%s\t%q: неверный номер\n", m.ID, m.Name, number)
+		}
+	}
+	fmt.Fprintf(a.stdout, "приведено к E.164: %d, неверных номеров: %d\n", changed, len(invalid))
+	return nil
+}
+
+func runNormalizeCountries(a *app, args []string) error {
+	if len(args) != 0 {
+		return usagef("использование: normalize-countries")
+	}
+	changed, unknown, err := a.ctrl.NormalizeCountries()
+	if err != nil {
+		return err
+	}
+	if a.jsonOutput {
+		if unknown == nil {
+			unknown = []int{}
+		}
+		return a.printJSON(map[string]interface{}{"changed": changed, "unknown": unknown})
+	}
+	for _, id := range unknown {
+		m, err := a.ctrl.GetManufacturerByID(id)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "%d\t%s\t%q: страна не найдена\n", m.ID, m.Name, m.Country)
+	}
+	fmt.Fprintf(a.stdout, "заменено кодами ISO 3166: %d, не распознано: %d\n", changed, len(unknown))
+	return nil
+}
+
+func runMailing(a *app, args []string) error {
+	if len(args) > 1 {
+		return usagef("использование: mailing [запрос]")
+	}
+	data := a.ctrl.GetCurrentData()
+	if len(args) == 1 {
+		found, err := a.ctrl.Search(args[0])
+		if err != nil {
+			return err
+		}
+		data = found
+	}
+	list := controller.MailingList(data)
+	if a.jsonOutput {
+		if list == nil {
+			list = []controller.MailingEntry{}
+		}
+		return a.printJSON(list)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Email\tName\tRole\tManufacturer")
+	for _, e := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Email, e.Name, e.Role, e.Manufacturer)
+	}
+	return w.Flush()
+}
+
+func runTotal(a *app, args []string) error {
+	if len(args) > 1 {
+		return usagef("использование: total [запрос]")
+	}
+	data := a.ctrl.GetCurrentData()
+	if len(args) == 1 {
+		found, err := a.ctrl.Search(args[0])
+		if err != nil {
+			return err
+		}
+		data = found
+	}
+	total, err := a.ctrl.TotalRevenue(data)
+	if err != nil {
+		return err
+	}
+	if a.jsonOutput {
+		return a.printJSON(total)
+	}
+
+	fmt.Fprintf(a.stdout, "записей: %d\nвыручка: %s\n", total.Count, currency.Format(total.Total, total.Currency))
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	for _, productType := range sortedKeys(total.ByType) {
+		fmt.Fprintf(w, "  %s\t%s\n", productType, currency.Format(total.ByType[productType], total.Currency))
+	}
+	w.Flush()
+	if len(total.Original) > 1 {
+		fmt.Fprintln(a.stdout, "в исходных валютах:")
+		for _, code := range sortedKeys(total.Original) {
+			fmt.Fprintf(a.stdout, "  %s\n", currency.Format(total.Original[code], code))
+		}
+	}
+	return nil
+}
+
+// sortedKeys возвращает ключи по алфавиту
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func runSplitAddresses(a *app, args []string) error {
+	if len(args) != 0 {
+		return usagef("использование: split-addresses")
+	}
+	changed, unparsed, err := a.ctrl.SplitAddresses()
+	if err != nil {
+		return err
+	}
+	if a.jsonOutput {
+		if unparsed == nil {
+			unparsed = []int{}
+		}
+		return a.printJSON(map[string]interface{}{"changed": changed, "unparsed": unparsed})
+	}
+	for _, id := range unparsed {
+		m, err := a.ctrl.GetManufacturerByID(id)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "%d\t%s\t%q: адрес не разобран\n", m.ID, m.Name, m.Address)
+	}
+	fmt.Fprintf(a.stdout, "адресов разобрано: %d, не разобрано: %d\n", changed, len(unparsed))
+	return nil
+}
+
+func runCountries(a *app, args []string) error {
+	flags := newFlagSet("countries")
+	lang := flags.String("lang", "ru", "язык названий: ru или en")
+	if err := flags.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if flags.NArg() > 1 {
+		return usagef("использование: countries [-lang ru|en] [текст]")
+	}
+	found := country.Search(flags.Arg(0), *lang)
+	if a.jsonOutput {
+		if found == nil {
+			found = []country.Country{}
+		}
+		return a.printJSON(found)
+	}
+	for _, c := range found {
+		fmt.Fprintf(a.stdout, "%s\t%s\t%s\n", c.Code, c.Alpha3, c.Name(*lang))
+	}
+	return nil
+}
+
+func runTags(a *app, args []string) error {
+	if len(args) != 0 {
+		return usagef("использование: tags")
+	}
+	tags := a.ctrl.Tags()
+	if a.jsonOutput {
+		if tags == nil {
+			tags = []controller.TagCount{}
+		}
+		return a.printJSON(tags)
+	}
+	for _, t := range tags {
+		fmt.Fprintf(a.stdout, "%s\t%d\n", t.Tag, t.Count)
+	}
+	return nil
+}
+
+func runProductTypes(a *app, args []string) error {
+	const usage = "использование: product-types [add <тип> | remove <тип> | rename <тип> <новое название> | merge <в тип> <тип>...]"
+	if len(args) == 0 {
+		types := a.ctrl.ProductTypeUsage()
+		if a.jsonOutput {
+			return a.printJSON(types)
+		}
+		for _, u := range types {
+			fmt.Fprintf(a.stdout, "%s\t%d\n", u.Value, u.Count)
+		}
+		return nil
+	}
+
+	var (
+		changed int
+		err     error
+	)
+	switch {
+	case args[0] == "add" && len(args) == 2:
+		err = a.ctrl.AddProductType(args[1])
+	case args[0] == "remove" && len(args) == 2:
+		err = a.ctrl.RemoveProductType(args[1])
+	case args[0] == "rename" && len(args) == 3:
+		changed, err = a.ctrl.RenameProductType(args[1], args[2])
+	case args[0] == "merge" && len(args) >= 3:
+		changed, err = a.ctrl.MergeProductTypes(args[2:], args[1])
+	default:
+		return usagef(usage)
+	}
+	if err != nil {
+		return err
+	}
+	if args[0] == "rename" || args[0] == "merge" {
+		fmt.Fprintf(a.stdout, "изменено записей: %d\n", changed)
+	}
+	return nil
+}
+
+func runCustomFields(a *app, args []string) error {
+	const usage = "использование: custom-fields [add <ключ> <тип> [название] [значения...] | remove <ключ>]"
+	schema := a.ctrl.CustomFields()
+	if len(args) == 0 {
+		if a.jsonOutput {
+			if schema == nil {
+				schema = model.Schema{}
+			}
+			return a.printJSON(schema)
+		}
+		for _, f := range schema {
+			fmt.Fprintf(a.stdout, "%s\t%s\t%s\t%s\n", f.Key, f.Type, f.Title(), strings.Join(f.Options, ", "))
+		}
+		return nil
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			return usagef(usage)
+		}
+		field := model.CustomField{Key: args[1], Type: model.FieldType(args[2])}
+		if len(args) > 3 {
+			field.Name = args[3]
+		}
+		if len(args) > 4 {
+			field.Options = args[4:]
+		}
+		if _, ok := schema.Lookup(field.Key); ok {
+			return fmt.Errorf("поле %s уже есть", field.Key)
+		}
+		schema = append(schema, field)
+	case "remove":
+		if len(args) != 2 {
+			return usagef(usage)
+		}
+		if _, ok := schema.Lookup(args[1]); !ok {
+			return fmt.Errorf("нет пользовательского поля %s", args[1])
+		}
+		var kept model.Schema
+		for _, f := range schema {
+			if f.Key != args[1] {
+				kept = append(kept, f)
+			}
+		}
+		schema = kept
+	default:
+		return usagef(usage)
+	}
+	return a.ctrl.SetCustomFields(schema)
+}
+
+func runValidate(a *app, args []string) error {
+	if len(args) != 0 {
+		return usagef("использование: validate")
+	}
+	violations := a.ctrl.ValidateAll()
+	if a.jsonOutput {
+		if violations == nil {
+			violations = validation.Violations{}
+		}
+		if err := a.printJSON(violations); err != nil {
+			return err
+		}
+	} else {
+		for _, v := range violations {
+			fmt.Fprintf(a.stdout, "%d\t%s\t%q\t%s (%s)\n", v.ID, v.Field, v.Value, v.Message, v.Severity)
+		}
+		fmt.Fprintf(a.stdout, "ошибок: %d, предупреждений: %d\n", len(violations.Errors()), len(violations.Warnings()))
+	}
+	if errs := violations.Errors(); len(errs) > 0 {
+		return &validationError{err: fmt.Errorf("записей с ошибками: %d", countRecords(errs))}
+	}
+	return nil
+}
+
+// countRecords считает записи, у которых есть нарушения
+func countRecords(violations validation.Violations) int {
+	ids := make(map[int]bool)
+	for _, v := range violations {
+		ids[v.ID] = true
+	}
+	return len(ids)
+}
+
+// printWarnings выводит предупреждения проверки в stderr; ошибки
+// вернет контроллер при сохранении
+func (a *app) printWarnings(violations validation.Violations) {
+	for _, v := range violations.Warnings() {
+		fmt.Fprintf(a.stderr, "предупреждение: %s\n", v)
+	}
+}
+
+// reportImport выводит проблемы по строкам в stderr, при необходимости
+// записывает отчет в CSV и возвращает ошибку проверки, если часть
+// записей не импортирована
+func (a *app) reportImport(result *importer.Result, report string) error {
+	if err := a.writeImportIssues(result, report); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "импортировано %d из %d записей\n", len(result.Manufacturers), result.Rows)
+	if n := result.Rejected(); n > 0 {
+		return &validationError{err: fmt.Errorf("%d записей не импортировано", n)}
+	}
+	return nil
+}
+
+// writeImportIssues выводит проблемы импорта и записывает их в отчет report
+func (a *app) writeImportIssues(result *importer.Result, report string) error {
+	position := "строка"
+	if result.Indexed {
+		position = "запись"
+	}
+	for _, issue := range result.Issues {
+		if issue.Column != "" {
+			fmt.Fprintf(a.stderr, "%s %d, %s %q: %s (%s)\n", position, issue.Line, issue.Column, issue.Value, issue.Message, issue.Severity)
+		} else {
+			fmt.Fprintf(a.stderr, "%s %d: %s (%s)\n", position, issue.Line, issue.Message, issue.Severity)
+		}
+	}
+	if report != "" {
+		file, err := os.Create(report)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err := result.WriteReport(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMapping меняет сопоставление столбцов по списку "Заголовок=поле,...".
+// Столбец можно указать и номером с 1, поле ищется среди полей файла fields.
+func applyMapping(mapping importer.Mapping, header []string, spec string, fields []model.Field) error {
+	if spec == "" {
+		return nil
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		column, key, ok := strings.Cut(pair, "=")
+		if !ok {
+			return usagef("-map: ожидается Заголовок=поле, получено %q", pair)
+		}
+		column, key = strings.TrimSpace(column), strings.TrimSpace(key)
+
+		index := -1
+		for i, name := range header {
+			if strings.EqualFold(name, column) {
+				index = i
+				break
+			}
+		}
+		if n, err := strconv.Atoi(column); index == -1 && err == nil && n >= 1 && n <= len(header) {
+			index = n - 1
+		}
+		if index == -1 {
+			return usagef("-map: в файле нет столбца %q", column)
+		}
+
+		if key == "-" || key == "" {
+			mapping[index] = ""
+			continue
+		}
+		field, ok := model.LookupField(fields, key)
+		if !ok || field.Key == "id" {
+			return usagef("-map: неизвестное поле %q", key)
+		}
+		for i := range mapping {
+			if mapping[i] == field.Key {
+				mapping[i] = ""
+			}
+		}
+		mapping[index] = field.Key
+	}
+	return nil
+}
+
+// setFields заполняет поля записи из аргументов вида поле=значение;
+// поля ищутся среди полей файла fields. ID задается только утилитой,
+// поэтому менять его нельзя.
+func setFields(m *model.Manufacturer, args []string, keepID bool, fields []model.Field) error {
+	set := make(map[string]bool)
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return usagef("ожидается поле=значение, получено %q", arg)
+		}
+		field, ok := model.LookupField(fields, name)
+		if !ok {
+			return usagef("неизвестное поле: %s", name)
+		}
+		if field.Key == "id" {
+			if keepID {
+				return usagef("ID записи изменить нельзя")
+			}
+			return usagef("ID назначается автоматически")
+		}
+		if err := field.Set(m, strings.TrimSpace(value)); err != nil {
+			return &validationError{err: fmt.Errorf("%s: %v", field.Key, err)}
+		}
+		set[field.Key] = true
+	}
+	// Выручка и численность без истории относятся к последнему году истории
+	if (set["revenue"] || set["employees"]) && !set["history"] && len(m.History) > 0 {
+		history := append(model.History(nil), m.History...)
+		latest := &history[len(history)-1]
+		if set["revenue"] {
+			latest.Revenue = m.Revenue
+		}
+		if set["employees"] {
+			latest.Employees = m.Employees
+		}
+		m.History = history
+	}
+	// Обозначения валют ("$", "руб") приводятся к кодам ISO 4217
+	if set["currency"] {
+		m.Currency, _ = currency.Normalize(m.Currency)
+	}
+	// Адрес одной строкой без частей адреса разбирается заново
+	if set["address"] && !set["street"] && !set["city"] && !set["region"] && !set["postalCode"] {
+		m.Street, m.City, m.Region, m.PostalCode = "", "", "", ""
+		address.Split(m)
+	}
+	return nil
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, usagef("неверный ID: %s", s)
+	}
+	return id, nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// printList выводит записи таблицей или массивом JSON
+func (a *app) printList(manufacturers []model.Manufacturer) error {
+	if a.jsonOutput {
+		if manufacturers == nil {
+			manufacturers = []model.Manufacturer{}
+		}
+		return a.printJSON(manufacturers)
+	}
+
+	fields := a.ctrl.Fields()
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = f.Header
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for i := range manufacturers {
+		values := make([]string, len(fields))
+		for j, f := range fields {
+			values[j] = f.Get(&manufacturers[i])
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	return w.Flush()
+}
+
+// printOne выводит одну запись списком "поле: значение" или объектом JSON
+func (a *app) printOne(m *model.Manufacturer) error {
+	if a.jsonOutput {
+		return a.printJSON(m)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 1, ' ', 0)
+	for _, f := range a.ctrl.Fields() {
+		fmt.Fprintf(w, "%s:\t%s\n", f.Header, f.Get(m))
+	}
+	return w.Flush()
+}
+
+func (a *app) printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(a.stdout, string(data))
+	return err
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"cursovay/internal/address"
+	"cursovay/internal/model"
+	"fmt"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// SplitAddresses разбирает адреса, записанные одной строкой, на улицу,
+// город, регион и индекс одним действием "Split addresses", которое можно
+// отменить. Записи, у которых части адреса уже заполнены, не меняются.
+// Возвращает число измененных записей и ID записей с адресом, который
+// разобрать не удалось.
+func (c *ManufacturerController) SplitAddresses() (int, []int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	after := cloneManufacturers(c.manufacturers)
+	changed := 0
+	var unparsed []int
+	for i := range after {
+		m := &after[i]
+		if address.Structured(m) || m.Address == "" {
+			continue
+		}
+		if address.Split(m) {
+			changed++
+		} else {
+			unparsed = append(unparsed, m.ID)
+		}
+	}
+	if changed == 0 {
+		return 0, unparsed, nil
+	}
+
+	err := c.execute(&replaceCommand{
+		label:  "Split addresses",
+		before: cloneManufacturers(c.manufacturers),
+		after:  after,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return changed, unparsed, nil
+}
+
+// generateCityBarChart строит число производителей по городам, начиная
+// с самых частых. Записи без города не учитываются.
+func (c *ManufacturerController) generateCityBarChart(manufacturers []model.Manufacturer, colorScheme string, showValues bool) ([]byte, error) {
+	config := DefaultChartConfig()
+
+	p := plot.New()
+	p.X.Label.TextStyle.Font.Size = config.FontSize
+	p.Y.Label.TextStyle.Font.Size = config.FontSize
+	p.Title.TextStyle.Font.Size = config.FontSize + 2
+	p.X.Label.Padding = config.MarginBot
+	p.Y.Label.Padding = config.MarginLeft
+
+	p.Title.Text = currentLocalization.Charts.CityBar.Title
+	p.X.Label.Text = currentLocalization.Charts.CityBar.XLabel
+	p.Y.Label.Text = currentLocalization.Charts.CityBar.YLabel
+
+	var values plotter.Values
+	var labels []string
+	for _, item := range c.countBy("city", manufacturers) {
+		if item.Value == "" {
+			continue
+		}
+		values = append(values, float64(item.Count))
+		labels = append(labels, item.Value)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no cities in data")
+	}
+
+	bars, err := plotter.NewBarChart(values, vg.Points(50))
+	if err != nil {
+		return nil, err
+	}
+	switch colorScheme {
+	case "Blue Theme":
+		bars.Color = color.RGBA{0, 0, 255, 255}
+	case "Green Theme":
+		bars.Color = color.RGBA{0, 255, 0, 255}
+	case "Rainbow":
+		bars.Color = color.RGBA{255, 0, 0, 255}
+	}
+
+	if showValues {
+		xys := make(plotter.XYs, len(values))
+		counts := make([]string, len(values))
+		for i, v := range values {
+			xys[i] = plotter.XY{X: float64(i), Y: v}
+			counts[i] = fmt.Sprintf("%.0f", v)
+		}
+		valueLabels, err := plotter.NewLabels(&ChartLabels{XYs: xys, Labels: counts})
+		if err != nil {
+			return nil, err
+		}
+		p.Add(valueLabels)
+	}
+
+	p.Add(bars)
+	p.NominalX(labels...)
+	return chartPNG(p, config)
+}
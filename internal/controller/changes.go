@@ -0,0 +1,261 @@
+package controller
+
+import (
+	"cursovay/internal/diff"
+	"cursovay/internal/model"
+	"cursovay/internal/repository"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// ChangeKind — вид изменения записи с момента последней загрузки или сохранения
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota + 1
+	ChangeModified
+	ChangeDeleted
+)
+
+// ChangeSet перечисляет ID измененных записей по видам изменений
+type ChangeSet struct {
+	Added    []int
+	Modified []int
+	Deleted  []int
+	Settings bool // изменены пользовательские поля или справочник типов продукции
+}
+
+// Empty сообщает, что несохраненных изменений нет
+func (cs ChangeSet) Empty() bool {
+	return cs.Count() == 0 && !cs.Settings
+}
+
+// Count возвращает общее число измененных записей
+func (cs ChangeSet) Count() int {
+	return len(cs.Added) + len(cs.Modified) + len(cs.Deleted)
+}
+
+// changeTracker запоминает, какие записи отличаются от загруженных
+// или последних сохраненных. Отметка записи снимается, когда запись
+// снова совпадает с файлом, например после отмены всех ее правок.
+type changeTracker struct {
+	saved   map[int]model.Manufacturer // записи в файле по ID
+	changes map[int]ChangeKind
+}
+
+func newChangeTracker(saved []model.Manufacturer) *changeTracker {
+	t := &changeTracker{}
+	t.reset(saved)
+	return t
+}
+
+// update сверяет запись id с файлом; current — запись в памяти или nil,
+// если ее больше нет
+func (t *changeTracker) update(id int, current *model.Manufacturer) {
+	saved, inFile := t.saved[id]
+	switch {
+	case current == nil && !inFile:
+		// Запись, которой нет в файле, просто забываем
+		delete(t.changes, id)
+	case current == nil:
+		t.changes[id] = ChangeDeleted
+	case !inFile:
+		t.changes[id] = ChangeAdded
+	case reflect.DeepEqual(saved, *current):
+		delete(t.changes, id)
+	default:
+		t.changes[id] = ChangeModified
+	}
+}
+
+// reset запоминает saved как содержимое файла и снимает все отметки
+func (t *changeTracker) reset(saved []model.Manufacturer) {
+	t.saved = make(map[int]model.Manufacturer, len(saved))
+	for _, m := range saved {
+		t.saved[m.ID] = m
+	}
+	t.changes = make(map[int]ChangeKind)
+}
+
+func (t *changeTracker) set() ChangeSet {
+	var cs ChangeSet
+	for id, kind := range t.changes {
+		switch kind {
+		case ChangeAdded:
+			cs.Added = append(cs.Added, id)
+		case ChangeModified:
+			cs.Modified = append(cs.Modified, id)
+		case ChangeDeleted:
+			cs.Deleted = append(cs.Deleted, id)
+		}
+	}
+	sort.Ints(cs.Added)
+	sort.Ints(cs.Modified)
+	sort.Ints(cs.Deleted)
+	return cs
+}
+
+// fileState — состояние, которое контроллер хранит отдельно для каждого
+// открытого файла (вкладки), ключ — путь к файлу, "" — новая база
+type fileState struct {
+	lastID       int          // наибольший выданный в файле ID
+	schema       model.Schema // пользовательские поля файла
+	productTypes []string     // справочник типов продукции файла
+	settings     bool         // поля или справочник изменены после сохранения
+	changes      *changeTracker
+	history      *history
+}
+
+func newFileState(lastID int) *fileState {
+	return &fileState{lastID: lastID, changes: newChangeTracker(nil), history: &history{}}
+}
+
+// file возвращает состояние текущего файла, создавая его при необходимости
+func (c *ManufacturerController) file() *fileState {
+	return c.fileFor(c.currentFile)
+}
+
+func (c *ManufacturerController) fileFor(path string) *fileState {
+	state, ok := c.files[path]
+	if !ok {
+		state = newFileState(0)
+		c.files[path] = state
+	}
+	return state
+}
+
+// resetFile начинает отслеживание изменений и историю файла заново
+// (после загрузки или создания базы)
+func (c *ManufacturerController) resetFile(path string, lastID int) *fileState {
+	state := newFileState(lastID)
+	c.files[path] = state
+	return state
+}
+
+// loaded запоминает состояние только что загруженного файла по его
+// метаданным, включая пользовательские поля, и делает файл текущим. Записям
+// с повторяющимися ID выдаются новые ID, такие записи считаются
+// измененными. Справочник типов продукции дополняется типами из записей.
+func (c *ManufacturerController) loaded(path string, manufacturers []model.Manufacturer, meta repository.Metadata) error {
+	schema, err := meta.Schema()
+	if err != nil {
+		return err
+	}
+	productTypes, err := meta.ProductTypes()
+	if err != nil {
+		return err
+	}
+
+	oldIDs := make([]int, len(manufacturers))
+	for i, m := range manufacturers {
+		oldIDs[i] = m.ID
+	}
+
+	lastID := repository.EnsureUniqueIDs(manufacturers, meta.LastID())
+	state := c.resetFile(path, lastID)
+	state.schema = schema
+	state.productTypes = withProductTypes(productTypes, manufacturers)
+	state.changes.reset(manufacturers)
+	for i, m := range manufacturers {
+		if m.ID != oldIDs[i] {
+			// В файле запись осталась с прежним ID
+			saved := m
+			saved.ID = oldIDs[i]
+			state.changes.saved[m.ID] = saved
+			state.changes.update(m.ID, &m)
+		}
+	}
+
+	c.manufacturers = manufacturers
+	c.currentFile = path
+	return nil
+}
+
+// markSaved переносит состояние текущего файла на путь сохранения
+// и сбрасывает список изменений
+func (c *ManufacturerController) markSaved(path string) {
+	state := c.file()
+	if path != c.currentFile {
+		delete(c.files, c.currentFile)
+		c.files[path] = state
+	}
+	state.changes.reset(c.manufacturers)
+	state.settings = false
+}
+
+// Changes возвращает изменения текущего файла с момента загрузки или сохранения
+func (c *ManufacturerController) Changes() ChangeSet {
+	return c.ChangesFor(c.GetCurrentFile())
+}
+
+// ChangesFor возвращает изменения файла, открытого по указанному пути
+func (c *ManufacturerController) ChangesFor(path string) ChangeSet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := c.fileFor(path)
+	cs := state.changes.set()
+	cs.Settings = state.settings
+	return cs
+}
+
+// DiffWithFile сравнивает данные в памяти с файлом currentFile на диске
+// и возвращает различия по полям. Состояние контроллера не меняется.
+func (c *ManufacturerController) DiffWithFile() (*diff.Diff, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.currentFile == "" {
+		return nil, errors.New("файл не выбран")
+	}
+	onDisk, err := c.readFile(c.currentFile)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Compare(onDisk, c.manufacturers, c.fields()), nil
+}
+
+// DiffFile сравнивает данные в памяти с другим файлом filePath: записи
+// файла считаются новой версией. Файл только читается: база не
+// переносится на новую схему, состояние контроллера не меняется.
+func (c *ManufacturerController) DiffFile(filePath string) (*diff.Diff, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, err
+	}
+	other, err := c.readFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Compare(c.manufacturers, other, c.fields()), nil
+}
+
+// readFile читает записи из CSV или базы, не загружая их в контроллер
+// и не меняя файл. Вызывается под c.mu.
+func (c *ManufacturerController) readFile(filePath string) ([]model.Manufacturer, error) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, nil // файл еще не создан: все записи новые
+	}
+
+	if IsDatabaseFile(filePath) {
+		if c.database != nil && c.database.Path() == filePath {
+			return c.database.List()
+		}
+		return repository.ReadSQLiteFile(filePath)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла: %v", err)
+	}
+	defer file.Close()
+	manufacturers, _, err := repository.ReadCSV(file)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения CSV: %v", err)
+	}
+	return manufacturers, nil
+}
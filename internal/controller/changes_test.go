@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"cursovay/internal/diff"
+	"cursovay/internal/model"
+	"cursovay/internal/repository"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestController открывает во временном каталоге CSV с записями data
+func newTestController(t *testing.T, name string, data []model.Manufacturer) (*ManufacturerController, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	c := NewManufacturerController(repository.NewMemoryStore(nil))
+	c.SetManufacturers(data)
+	if err := c.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	c = NewManufacturerController(repository.NewMemoryStore(nil))
+	if _, err := c.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	return c, path
+}
+
+func testData() []model.Manufacturer {
+	return []model.Manufacturer{
+		{ID: 1, Name: "Alpha", Country: "RU", FoundedYear: 1990, Revenue: 10, ProductType: "Dye"},
+		{ID: 2, Name: "Beta", Country: "DE", FoundedYear: 1995, Revenue: 20, ProductType: "Ink"},
+		{ID: 3, Name: "Gamma", Country: "FR", FoundedYear: 2001, Revenue: 30, ProductType: "Dye"},
+	}
+}
+
+func TestChangeTracker(t *testing.T) {
+	saved := testData()
+	edited := func(name string) model.Manufacturer {
+		m := saved[0]
+		m.Name = name
+		return m
+	}
+	added := model.Manufacturer{ID: 4, Name: "Delta"}
+
+	tests := []struct {
+		name  string
+		steps [][]model.Manufacturer // состояния данных после каждого действия
+		want  ChangeSet
+	}{
+		{"добавление", [][]model.Manufacturer{append(testData(), added)}, ChangeSet{Added: []int{4}}},
+		{"изменение", [][]model.Manufacturer{{edited("A"), saved[1], saved[2]}, {edited("B"), saved[1], saved[2]}}, ChangeSet{Modified: []int{1}}},
+		{"удаление", [][]model.Manufacturer{{saved[0], saved[2]}}, ChangeSet{Deleted: []int{2}}},
+		{"добавленная и измененная", [][]model.Manufacturer{append(testData(), added), append(testData(), model.Manufacturer{ID: 4, Name: "Delta Ltd"})}, ChangeSet{Added: []int{4}}},
+		{"добавленная и удаленная", [][]model.Manufacturer{append(testData(), added), testData()}, ChangeSet{}},
+		{"удаление отменено", [][]model.Manufacturer{{saved[0], saved[2]}, testData()}, ChangeSet{}},
+		{"правка отменена", [][]model.Manufacturer{{edited("A"), saved[1], saved[2]}, testData()}, ChangeSet{}},
+		{"удалена и возвращена измененной", [][]model.Manufacturer{{saved[1], saved[2]}, {saved[1], saved[2], edited("A")}}, ChangeSet{Modified: []int{1}}},
+		{"сортировка", [][]model.Manufacturer{{saved[2], saved[1], saved[0]}}, ChangeSet{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newChangeTracker(saved)
+			before := testData()
+			for _, after := range tt.steps {
+				recordChanges(tr, before, after)
+				before = after
+			}
+			if got := tr.set(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("изменения %+v, ожидались %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Отмена всех правок возвращает файл в сохраненное состояние
+func TestUndoToSavedState(t *testing.T) {
+	c, _ := newTestController(t, "data.csv", testData())
+
+	stored, _ := c.GetManufacturerByID(1)
+	m := *stored
+	m.Name = "Alpha Ltd"
+	if err := c.UpdateManufacturer(&m); err != nil {
+		t.Fatalf("UpdateManufacturer: %v", err)
+	}
+	if err := c.DeleteManufacturer(2); err != nil {
+		t.Fatalf("DeleteManufacturer: %v", err)
+	}
+	if err := c.AddManufacturer(&model.Manufacturer{Name: "Delta", Country: "RU", FoundedYear: 2010}); err != nil {
+		t.Fatalf("AddManufacturer: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.Undo(); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+	}
+	if c.HasUnsavedChanges() {
+		t.Errorf("после отмены всех действий остались изменения: %+v", c.Changes())
+	}
+
+	if _, err := c.Redo(); err != nil {
+		t.Fatalf("Redo: %v", err)
+	}
+	if want := (ChangeSet{Modified: []int{1}}); !reflect.DeepEqual(c.Changes(), want) {
+		t.Errorf("после повтора изменения %+v, ожидались %+v", c.Changes(), want)
+	}
+	if err := c.DeleteManufacturer(9); err == nil || !strings.Contains(err.Error(), "не найден") {
+		t.Errorf("удаление несуществующей записи: %v", err)
+	}
+}
+
+func TestEditIsUnsavedUntilSave(t *testing.T) {
+	c, path := newTestController(t, "data.csv", testData())
+	if c.HasUnsavedChanges() {
+		t.Fatalf("после загрузки есть изменения: %+v", c.Changes())
+	}
+
+	m, err := c.GetManufacturerByID(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Revenue = 25
+	if err := c.UpdateManufacturer(m); err != nil {
+		t.Fatalf("UpdateManufacturer: %v", err)
+	}
+	if err := c.DeleteManufacturer(3); err != nil {
+		t.Fatalf("DeleteManufacturer: %v", err)
+	}
+
+	want := ChangeSet{Modified: []int{2}, Deleted: []int{3}}
+	if got := c.Changes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Changes() = %+v, ожидалось %+v", got, want)
+	}
+
+	// Файл на диске не меняется до явного сохранения
+	onDisk, err := c.readFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(onDisk) != 3 {
+		t.Errorf("в файле %d записей до сохранения, ожидалось 3", len(onDisk))
+	}
+
+	if err := c.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	if c.HasUnsavedChanges() {
+		t.Errorf("после сохранения остались изменения: %+v", c.Changes())
+	}
+}
+
+func TestDiffWithFile(t *testing.T) {
+	c, path := newTestController(t, "data.csv", testData())
+
+	d, err := c.DiffWithFile()
+	if err != nil {
+		t.Fatalf("DiffWithFile: %v", err)
+	}
+	if len(d.Changes) != 0 {
+		t.Fatalf("различия сразу после загрузки: %+v", d.Changes)
+	}
+
+	m, _ := c.GetManufacturerByID(1)
+	m.Name = "Alpha Ltd"
+	if err := c.UpdateManufacturer(m); err != nil {
+		t.Fatalf("UpdateManufacturer: %v", err)
+	}
+	added := &model.Manufacturer{Name: "Delta", Country: "RU", FoundedYear: 2010}
+	if err := c.AddManufacturer(added); err != nil {
+		t.Fatalf("AddManufacturer: %v", err)
+	}
+	if err := c.DeleteManufacturer(2); err != nil {
+		t.Fatalf("DeleteManufacturer: %v", err)
+	}
+
+	d, err = c.DiffWithFile()
+	if err != nil {
+		t.Fatalf("DiffWithFile: %v", err)
+	}
+	counts := map[diff.Kind]int{diff.Added: 1, diff.Removed: 1, diff.Modified: 1}
+	for kind, want := range counts {
+		if got := d.Count(kind); got != want {
+			t.Errorf("%s: %d, ожидалось %d", kind, got, want)
+		}
+	}
+	for _, change := range d.Changes {
+		if change.Kind != diff.Modified {
+			continue
+		}
+		want := []diff.FieldChange{{Field: "name", Old: "Alpha", New: "Alpha Ltd"}}
+		if change.ID != 1 || !reflect.DeepEqual(change.Fields, want) {
+			t.Errorf("изменение %d: %+v, ожидалось %+v", change.ID, change.Fields, want)
+		}
+	}
+
+	if err := c.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	if d, _ := c.DiffWithFile(); len(d.Changes) != 0 {
+		t.Errorf("различия после сохранения: %+v", d.Changes)
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MailingEntry — один адресат рассылки: контактное лицо или общий email
+// производителя
+type MailingEntry struct {
+	Email          string `json:"email"`
+	Name           string `json:"name,omitempty"`
+	Role           string `json:"role,omitempty"`
+	Phone          string `json:"phone,omitempty"`
+	Manufacturer   string `json:"manufacturer"`
+	ManufacturerID int    `json:"manufacturer_id"`
+	Country        string `json:"country,omitempty"`
+	ProductType    string `json:"product_type,omitempty"`
+}
+
+// mailingHeaders — заголовки CSV-файла рассылки
+var mailingHeaders = []string{"Email", "Name", "Role", "Phone", "Manufacturer", "Manufacturer ID", "Country", "Product Type"}
+
+// MailingList разворачивает контакты производителей в плоский список
+// адресатов: по строке на каждый контакт с email и на общий email
+// производителя, если его нет среди контактов. Повторяющиеся адреса
+// попадают в список один раз. Контакты без email пропускаются.
+func MailingList(manufacturers []model.Manufacturer) []MailingEntry {
+	var list []MailingEntry
+	seen := make(map[string]bool)
+	add := func(m *model.Manufacturer, c model.Contact) {
+		key := strings.ToLower(strings.TrimSpace(c.Email))
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		list = append(list, MailingEntry{
+			Email: strings.TrimSpace(c.Email), Name: c.Name, Role: c.Role, Phone: c.Phone,
+			Manufacturer: m.Name, ManufacturerID: m.ID, Country: m.Country, ProductType: m.ProductType,
+		})
+	}
+	for i := range manufacturers {
+		m := &manufacturers[i]
+		for _, c := range m.Contacts {
+			add(m, c)
+		}
+		add(m, model.Contact{Email: m.Email, Phone: m.Phone})
+	}
+	return list
+}
+
+// ExportMailingList записывает список адресатов рассылки по записям
+// manufacturers в CSV-файл
+func (c *ManufacturerController) ExportMailingList(filePath string, manufacturers []model.Manufacturer) (int, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create CSV file: %v", err)
+	}
+	defer file.Close()
+
+	list := MailingList(manufacturers)
+	writer := csv.NewWriter(file)
+	if err := writer.Write(mailingHeaders); err != nil {
+		return 0, err
+	}
+	for _, e := range list {
+		record := []string{e.Email, e.Name, e.Role, e.Phone, e.Manufacturer,
+			strconv.Itoa(e.ManufacturerID), e.Country, e.ProductType}
+		if err := writer.Write(record); err != nil {
+			return 0, err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return 0, fmt.Errorf("failed to write CSV file: %v", err)
+	}
+	return len(list), nil
+}
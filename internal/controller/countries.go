@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"cursovay/internal/country"
+)
+
+// NormalizeCountries заменяет страны всех записей текущего файла кодами
+// ISO 3166 (Россия, Russia, Yaroslavl -> RU) одним действием
+// "Normalize countries", которое можно отменить. Возвращает число
+// измененных записей и ID записей, страну которых определить не удалось:
+// они остаются без изменений.
+func (c *ManufacturerController) NormalizeCountries() (int, []int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	after := cloneManufacturers(c.manufacturers)
+	changed := 0
+	var unknown []int
+	for i := range after {
+		m := &after[i]
+		code, ok := country.Normalize(m.Country)
+		if !ok {
+			unknown = append(unknown, m.ID)
+			continue
+		}
+		if code != m.Country {
+			m.Country = code
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, unknown, nil
+	}
+
+	err := c.execute(&replaceCommand{
+		label:  "Normalize countries",
+		before: cloneManufacturers(c.manufacturers),
+		after:  after,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return changed, unknown, nil
+}
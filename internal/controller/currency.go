@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"cursovay/internal/currency"
+	"cursovay/internal/model"
+	"cursovay/internal/repository"
+	"fmt"
+	"time"
+)
+
+// SetCurrency задает таблицу курсов и валюту отчетов. nil — пустая
+// таблица с базовой валютой RUB; пустая валюта отчетов — базовая валюта
+// таблицы. В валюту отчетов пересчитываются сортировка по выручке,
+// графики, PDF и итоги.
+func (c *ManufacturerController) SetCurrency(rates *currency.Table, display string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rates == nil {
+		rates = currency.NewTable(currency.DefaultBase)
+	}
+	if display != "" {
+		code, ok := currency.Normalize(display)
+		if !ok {
+			return fmt.Errorf("неверный код валюты: %s", display)
+		}
+		display = code
+	}
+	c.rates = rates
+	c.currency = display
+	return nil
+}
+
+// SetReportCurrency меняет валюту отчетов, оставляя таблицу курсов.
+// Пустая строка — базовая валюта таблицы.
+func (c *ManufacturerController) SetReportCurrency(display string) error {
+	c.mu.RLock()
+	rates := c.rates
+	c.mu.RUnlock()
+	return c.SetCurrency(rates, display)
+}
+
+// Currency возвращает валюту отчетов
+func (c *ManufacturerController) Currency() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reportCurrency()
+}
+
+// Currencies перечисляет валюты, в которые можно пересчитать выручку
+func (c *ManufacturerController) Currencies() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rates.Codes()
+}
+
+// reportCurrency — валюта отчетов. Вызывается под c.mu.
+func (c *ManufacturerController) reportCurrency() string {
+	if c.currency == "" {
+		return c.rates.Base()
+	}
+	return c.currency
+}
+
+// InReportCurrency возвращает копии записей с выручкой и историей
+// выручки в валюте отчетов. Выручка за год пересчитывается по курсу
+// на конец года, выручка без истории — по последнему известному курсу.
+func (c *ManufacturerController) InReportCurrency(manufacturers []model.Manufacturer) ([]model.Manufacturer, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.convertRecords(manufacturers)
+}
+
+// convertRecords — InReportCurrency под c.mu
+func (c *ManufacturerController) convertRecords(manufacturers []model.Manufacturer) ([]model.Manufacturer, error) {
+	to := c.reportCurrency()
+	result := cloneManufacturers(manufacturers)
+	for i := range result {
+		m := &result[i]
+		from := m.Currency
+		if from == "" {
+			from = c.rates.Base()
+		}
+		if from == to {
+			m.Currency = to
+			continue
+		}
+
+		if len(m.History) > 0 {
+			history := make(model.History, len(m.History))
+			for j, s := range m.History {
+				revenue, err := c.rates.Convert(s.Revenue, from, to, currency.YearEnd(s.Year))
+				if err != nil {
+					return nil, fmt.Errorf("%s (ID %d): %v", m.Name, m.ID, err)
+				}
+				s.Revenue = revenue
+				history[j] = s
+			}
+			m.History = history
+		}
+
+		if latest, ok := m.History.Latest(); ok {
+			m.Revenue = latest.Revenue
+		} else {
+			revenue, err := c.rates.Convert(m.Revenue, from, to, time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("%s (ID %d): %v", m.Name, m.ID, err)
+			}
+			m.Revenue = revenue
+		}
+		m.Currency = to
+	}
+	return result, nil
+}
+
+// needsConversion сообщает, есть ли записи в валюте, отличной от валюты
+// отчетов. Вызывается под c.mu.
+func (c *ManufacturerController) needsConversion(manufacturers []model.Manufacturer) bool {
+	to := c.reportCurrency()
+	for _, m := range manufacturers {
+		if m.Currency != to && !(m.Currency == "" && to == c.rates.Base()) {
+			return true
+		}
+	}
+	return false
+}
+
+// RevenueTotal — итог выручки в валюте отчетов
+type RevenueTotal struct {
+	Currency string             `json:"currency"`
+	Total    float64            `json:"total"`
+	Count    int                `json:"count"`
+	ByType   map[string]float64 `json:"by_product_type"` // выручка производителя с несколькими типами входит в каждый
+	Original map[string]float64 `json:"original"`        // суммы в исходных валютах
+}
+
+// TotalRevenue считает выручку всех записей в валюте отчетов, в том числе
+// по типам продукции
+func (c *ManufacturerController) TotalRevenue(manufacturers []model.Manufacturer) (*RevenueTotal, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	converted, err := c.convertRecords(manufacturers)
+	if err != nil {
+		return nil, err
+	}
+	total := &RevenueTotal{
+		Currency: c.reportCurrency(),
+		Count:    len(converted),
+		ByType:   make(map[string]float64),
+		Original: make(map[string]float64),
+	}
+	for i, m := range converted {
+		total.Total += m.Revenue
+		for _, productType := range typesOf(&converted[i]) {
+			total.ByType[productType] += m.Revenue
+		}
+
+		code := manufacturers[i].Currency
+		if code == "" {
+			code = c.rates.Base()
+		}
+		total.Original[code] += manufacturers[i].Revenue
+	}
+	return total, nil
+}
+
+// sortsByRevenue сообщает, есть ли среди ключей сортировки выручка
+func sortsByRevenue(keys []repository.SortKey) bool {
+	for _, key := range keys {
+		if f, ok := model.FieldByName(key.Field); ok && f.Key == "revenue" {
+			return true
+		}
+	}
+	return false
+}
+
+// sortConverted сортирует записи по их копиям в валюте отчетов.
+// Вызывается под c.mu.
+func (c *ManufacturerController) sortConverted(manufacturers []model.Manufacturer, keys []repository.SortKey) ([]model.Manufacturer, error) {
+	converted, err := c.convertRecords(manufacturers)
+	if err != nil {
+		return nil, err
+	}
+	sorted, err := repository.SortManufacturers(converted, keys, c.fields())
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]model.Manufacturer, len(manufacturers))
+	for _, m := range manufacturers {
+		byID[m.ID] = m
+	}
+	result := make([]model.Manufacturer, len(sorted))
+	for i, m := range sorted {
+		result[i] = byID[m.ID]
+	}
+	return result, nil
+}
+
+// revenueLabel дополняет подпись оси выручки кодом валюты отчетов
+func (c *ManufacturerController) revenueLabel(label string) string {
+	code := c.Currency()
+	if label == "" {
+		return code
+	}
+	return label + ", " + code
+}
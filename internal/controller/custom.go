@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CustomFields возвращает пользовательские поля текущего файла
+func (c *ManufacturerController) CustomFields() model.Schema {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append(model.Schema(nil), c.fileFor(c.currentFile).schema...)
+}
+
+// Fields возвращает поля текущего файла: встроенные и за ними
+// пользовательские. По ним строятся колонки, поиск, сортировка и проверка.
+func (c *ManufacturerController) Fields() []model.Field {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.fields()
+}
+
+// FieldsOf возвращает поля открытого файла path, не обязательно текущего
+func (c *ManufacturerController) FieldsOf(path string) []model.Field {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.fieldsOf(path)
+}
+
+// fields возвращает поля текущего файла; вызывается под c.mu
+func (c *ManufacturerController) fields() []model.Field {
+	return c.fieldsOf(c.currentFile)
+}
+
+func (c *ManufacturerController) fieldsOf(path string) []model.Field {
+	var schema model.Schema
+	if state, ok := c.files[path]; ok {
+		schema = state.schema
+	}
+	return schema.Fields()
+}
+
+// SetCustomFields заменяет пользовательские поля текущего файла.
+// Значения удаленных полей стираются, значения остальных приводятся
+// к новому типу; если какое-то значение к нему не приводится, поля
+// не меняются. Изменение полей отменяется вместе с изменениями значений
+// и попадает в файл при сохранении.
+func (c *ManufacturerController) SetCustomFields(schema model.Schema) error {
+	if err := schema.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	after := cloneManufacturers(c.manufacturers)
+	for i := range after {
+		m := &after[i]
+		for key, value := range m.Custom {
+			field, ok := schema.Lookup(key)
+			if !ok {
+				m.SetCustom(key, "")
+				continue
+			}
+			normalized, err := field.Normalize(value)
+			if err != nil {
+				return fmt.Errorf("запись %d, поле %s: %v", m.ID, field.Key, err)
+			}
+			if normalized != value {
+				m.SetCustom(key, normalized)
+			}
+		}
+	}
+
+	state := c.file()
+	return c.execute(&schemaCommand{
+		state:  state,
+		before: state.schema,
+		after:  schema,
+		records: replaceCommand{
+			before: cloneManufacturers(c.manufacturers),
+			after:  after,
+		},
+	})
+}
+
+// TagCount — тег и число производителей с ним
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// Tags перечисляет теги текущего файла, начиная с самых частых.
+// Теги, отличающиеся только регистром, считаются одним.
+func (c *ManufacturerController) Tags() []TagCount {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	index := make(map[string]int)
+	var result []TagCount
+	for _, m := range c.manufacturers {
+		for _, tag := range m.Tags {
+			key := strings.ToLower(tag)
+			i, ok := index[key]
+			if !ok {
+				i = len(result)
+				index[key] = i
+				result = append(result, TagCount{Tag: tag})
+			}
+			result[i].Count++
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return strings.ToLower(result[i].Tag) < strings.ToLower(result[j].Tag)
+	})
+	return result
+}
+
+// extrasLine — строка отчета с тегами и значениями пользовательских полей
+// записи: "Tags: preferred, ISO9001; Контракт до: 2025-12-31".
+// Пустая строка — у записи нет ни тегов, ни значений.
+func extrasLine(m *model.Manufacturer, schema model.Schema) string {
+	var parts []string
+	if len(m.Tags) > 0 {
+		parts = append(parts, "Tags: "+model.JoinList(m.Tags))
+	}
+	for _, f := range schema {
+		if value := m.Custom[f.Key]; value != "" {
+			parts = append(parts, f.Title()+": "+value)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"cursovay/internal/repository"
+	"cursovay/internal/validation"
+	"testing"
+)
+
+func hasField(fields []model.Field, key string) bool {
+	_, ok := model.LookupField(fields, key)
+	return ok
+}
+
+func TestCustomFieldsPerFile(t *testing.T) {
+	c, pathA := newTestController(t, "a.csv", testData())
+	if err := c.SetCustomFields(model.Schema{{Key: "grade", Name: "Grade", Type: model.TypeNumber}}); err != nil {
+		t.Fatalf("SetCustomFields: %v", err)
+	}
+	stored, _ := c.GetManufacturerByID(1)
+	m := *stored
+	m.SetCustom("grade", "5")
+	if err := c.UpdateManufacturer(&m); err != nil {
+		t.Fatalf("UpdateManufacturer: %v", err)
+	}
+
+	// Второй файл без пользовательских полей открыт в том же контроллере;
+	// данные файлов переключаются как во вкладках главного окна
+	dataA := c.GetCurrentData()
+	_, pathB := newTestController(t, "b.csv", testData())
+	if _, err := c.LoadFromFile(pathB); err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	if hasField(c.Fields(), "grade") {
+		t.Error("поле grade первого файла видно во втором")
+	}
+	if !hasField(c.FieldsOf(pathA), "grade") {
+		t.Error("у первого файла пропало поле grade")
+	}
+	if _, err := repository.ParseSortKeys("grade", c.Fields()); err == nil {
+		t.Error("сортировка по grade во втором файле не вернула ошибку")
+	}
+
+	// Правило для пользовательского поля применяется только там, где поле есть
+	rules, err := validation.Parse([]byte(`{"rules":[{"field":"grade","range":"0..3"}]}`))
+	if err != nil {
+		t.Fatalf("validation.Parse: %v", err)
+	}
+	c.SetRules(rules)
+	if vs := c.ValidateAll(); len(vs) != 0 {
+		t.Errorf("во втором файле нарушения %v", vs)
+	}
+	c.UpdateManufacturers(dataA)
+	c.SetCurrentFile(pathA)
+	if vs := c.ValidateAll(); len(vs) != 1 || vs[0].Field != "grade" || vs[0].ID != 1 {
+		t.Errorf("в первом файле нарушения %v, ожидалось одно для grade", vs)
+	}
+
+	keys, err := repository.ParseSortKeys("-grade", c.Fields())
+	if err != nil {
+		t.Fatalf("ParseSortKeys: %v", err)
+	}
+	sorted, err := c.SortBy(c.GetCurrentData(), keys)
+	if err != nil {
+		t.Fatalf("SortBy: %v", err)
+	}
+	if sorted[0].ID != 1 {
+		t.Errorf("первой после сортировки по grade идет запись %d", sorted[0].ID)
+	}
+
+	// Поле и его значения сохраняются в файл и читаются обратно
+	if err := c.SaveToFile(pathA); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	reloaded := NewManufacturerController(repository.NewMemoryStore(nil))
+	if _, err := reloaded.LoadFromFile(pathA); err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	if !hasField(reloaded.Fields(), "grade") {
+		t.Fatal("после загрузки нет поля grade")
+	}
+	if got, _ := reloaded.GetManufacturerByID(1); got.Custom["grade"] != "5" {
+		t.Errorf("после загрузки grade = %q", got.Custom["grade"])
+	}
+}
+
+// Изменение полей отменяется вместе со стертыми значениями записей
+func TestSetCustomFieldsUndo(t *testing.T) {
+	c, _ := newTestController(t, "fields.csv", testData())
+	grade := model.Schema{{Key: "grade", Name: "Grade", Type: model.TypeNumber}}
+	if err := c.SetCustomFields(grade); err != nil {
+		t.Fatalf("SetCustomFields: %v", err)
+	}
+	stored, _ := c.GetManufacturerByID(1)
+	m := *stored
+	m.SetCustom("grade", "5")
+	if err := c.UpdateManufacturer(&m); err != nil {
+		t.Fatalf("UpdateManufacturer: %v", err)
+	}
+
+	gradeOf := func() string {
+		stored, _ := c.GetManufacturerByID(1)
+		return stored.Custom["grade"]
+	}
+	steps := []struct {
+		name  string
+		step  func() error
+		field bool
+		value string
+	}{
+		{"удаление поля", func() error { return c.SetCustomFields(nil) }, false, ""},
+		{"отмена", func() error { _, err := c.Undo(); return err }, true, "5"},
+		{"повтор", func() error { _, err := c.Redo(); return err }, false, ""},
+		{"отмена повтора", func() error { _, err := c.Undo(); return err }, true, "5"},
+	}
+	for _, s := range steps {
+		if err := s.step(); err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		if got := hasField(c.Fields(), "grade"); got != s.field {
+			t.Errorf("%s: поле grade есть — %v, ожидалось %v", s.name, got, s.field)
+		}
+		if got := gradeOf(); got != s.value {
+			t.Errorf("%s: значение grade %q, ожидалось %q", s.name, got, s.value)
+		}
+	}
+
+	// Отмена до исходного состояния убирает и само поле
+	for c.CanUndo() {
+		if _, err := c.Undo(); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+	}
+	if hasField(c.Fields(), "grade") {
+		t.Error("после отмены всех действий осталось поле grade")
+	}
+}
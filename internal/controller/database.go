@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"cursovay/internal/repository"
+	"cursovay/internal/service"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsDatabaseFile сообщает, что файл нужно открывать как базу SQLite, а не CSV
+func IsDatabaseFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return true
+	}
+	return false
+}
+
+// openDatabase возвращает открытую базу по пути, открывая ее при необходимости.
+// Ранее открытая база с другим путем закрывается.
+func (c *ManufacturerController) openDatabase(filePath string) (*repository.SQLiteStore, error) {
+	if c.database != nil && c.database.Path() == filePath {
+		return c.database, nil
+	}
+
+	db, err := repository.OpenSQLiteStore(filePath)
+	if err != nil {
+		return nil, err
+	}
+	c.closeDatabase()
+	c.database = db
+	c.service = service.NewManufacturerService(db)
+	return db, nil
+}
+
+// closeDatabase закрывает открытую базу и возвращает сервис к исходному хранилищу
+func (c *ManufacturerController) closeDatabase() {
+	if c.database == nil {
+		return
+	}
+	c.database.Close()
+	c.database = nil
+	c.service = service.NewManufacturerService(c.defaultStore)
+}
+
+// currentDatabase возвращает базу, если текущий файл — открытая база SQLite
+// и в нем нет несохраненных изменений записей. Только в этом случае поиск,
+// сортировку и агрегаты можно выполнять в SQL: иначе в базе старые данные,
+// и запрос не нашел бы новых записей и нашел бы прежние значения.
+// Вызывается под c.mu.
+func (c *ManufacturerController) currentDatabase() *repository.SQLiteStore {
+	if c.database == nil || c.database.Path() != c.currentFile {
+		return nil
+	}
+	if state, ok := c.files[c.currentFile]; ok && len(state.changes.changes) > 0 {
+		return nil
+	}
+	return c.database
+}
+
+func (c *ManufacturerController) loadFromDatabase(filePath string) ([]model.Manufacturer, error) {
+	db, err := c.openDatabase(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы: %v", err)
+	}
+
+	manufacturers, err := db.List()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения базы: %v", err)
+	}
+	meta, err := db.Metadata()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения базы: %v", err)
+	}
+
+	if err := c.loaded(filePath, manufacturers, meta); err != nil {
+		return nil, fmt.Errorf("ошибка чтения базы: %v", err)
+	}
+	return manufacturers, nil
+}
+
+func (c *ManufacturerController) saveToDatabase(filePath string) error {
+	db, err := c.openDatabase(filePath)
+	if err != nil {
+		return err
+	}
+	if err := db.ReplaceAll(c.manufacturers, c.metadata()); err != nil {
+		return err
+	}
+	c.markSaved(filePath)
+	c.currentFile = filePath
+	return nil
+}
+
+// sortInDatabase упорядочивает переданные записи так, как их вернул ORDER BY.
+// Возвращает false, если в базе нет какой-то из записей (например, несохраненной).
+func sortInDatabase(db *repository.SQLiteStore, manufacturers []model.Manufacturer, keys []repository.SortKey) ([]model.Manufacturer, bool) {
+	ordered, err := db.Query(repository.Query{Sort: keys})
+	if err != nil {
+		return nil, false
+	}
+
+	wanted := make(map[int]model.Manufacturer, len(manufacturers))
+	for _, m := range manufacturers {
+		wanted[m.ID] = m
+	}
+
+	sorted := make([]model.Manufacturer, 0, len(manufacturers))
+	for _, m := range ordered {
+		if item, ok := wanted[m.ID]; ok {
+			sorted = append(sorted, item)
+		}
+	}
+	return sorted, len(sorted) == len(manufacturers)
+}
+
+// chartDataFromDatabase возвращает данные для графика в том порядке,
+// в котором его строит generate*Chart при включенной сортировке
+func (c *ManufacturerController) chartDataFromDatabase(chartType string) ([]model.Manufacturer, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	db := c.currentDatabase()
+	if db == nil {
+		return nil, false
+	}
+
+	var q repository.Query
+	switch chartType {
+	case "revenue_bar":
+		q = repository.Query{SortBy: "revenue", Ascending: false}
+	case "founded_bar":
+		q = repository.Query{SortBy: "foundedYear", Ascending: true}
+	default:
+		return nil, false
+	}
+
+	data, err := db.Query(q)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// countBy считает производителей по значениям поля key, начиная с самых
+// частых. Для базы SQLite используется GROUP BY. В полях-списках (типы
+// продукции, теги) производитель учитывается в каждом своем значении,
+// такие поля считаются в памяти.
+func (c *ManufacturerController) countBy(key string, manufacturers []model.Manufacturer) []repository.GroupCount {
+	field, _ := model.FieldByName(key)
+
+	c.mu.RLock()
+	db := c.currentDatabase()
+	c.mu.RUnlock()
+
+	if db != nil && !field.List {
+		if counts, err := db.CountBy(key); err == nil {
+			return counts
+		}
+	}
+
+	counts := make(map[string]int)
+	for i := range manufacturers {
+		value := field.Get(&manufacturers[i])
+		if !field.List {
+			counts[value]++
+			continue
+		}
+		items := model.SplitList(value)
+		if len(items) == 0 {
+			counts[""]++
+		}
+		for _, item := range items {
+			counts[item]++
+		}
+	}
+
+	result := make([]repository.GroupCount, 0, len(counts))
+	for value, count := range counts {
+		result = append(result, repository.GroupCount{Value: value, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
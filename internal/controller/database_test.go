@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"testing"
+)
+
+func names(data []model.Manufacturer) map[string]bool {
+	result := make(map[string]bool)
+	for _, m := range data {
+		result[m.Name] = true
+	}
+	return result
+}
+
+// Пока изменения не сохранены, поиск, подсчеты и графики базы SQLite
+// строятся по данным в памяти, а не по устаревшим строкам в файле
+func TestDatabaseQueriesSeeUnsavedEdits(t *testing.T) {
+	c, path := newTestController(t, "base.db", testData())
+
+	if err := c.AddManufacturer(&model.Manufacturer{Name: "Delta", Country: "US", FoundedYear: 2010, Revenue: 50, ProductType: "Ink"}); err != nil {
+		t.Fatalf("AddManufacturer: %v", err)
+	}
+	stored, _ := c.GetManufacturerByID(1)
+	renamed := *stored
+	renamed.Name = "Omega"
+	if err := c.UpdateManufacturer(&renamed); err != nil {
+		t.Fatalf("UpdateManufacturer: %v", err)
+	}
+
+	tests := []struct {
+		text  string
+		found bool
+	}{
+		{"Delta", true},
+		{"Omega", true},
+		{"Alpha", false},
+	}
+	for _, tt := range tests {
+		found, err := c.Search(tt.text)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.text, err)
+		}
+		if names(found)[tt.text] != tt.found {
+			t.Errorf("Search(%q) = %v, найдено ожидалось %v", tt.text, found, tt.found)
+		}
+	}
+
+	if data, ok := c.chartDataFromDatabase("revenue_bar"); ok {
+		t.Errorf("график строится по базе с несохраненными изменениями: %v", data)
+	}
+	counts := c.countBy("country", c.GetCurrentData())
+	total := 0
+	for _, count := range counts {
+		total += count.Count
+	}
+	if total != 4 {
+		t.Errorf("подсчет по странам учел %d записей, ожидалось 4: %v", total, counts)
+	}
+
+	// После сохранения запросы снова выполняет база
+	if err := c.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	if data, ok := c.chartDataFromDatabase("revenue_bar"); !ok || len(data) != 4 || data[0].Name != "Delta" {
+		t.Errorf("график после сохранения: %v, %v", data, ok)
+	}
+	if found, err := c.Search("Omega"); err != nil || len(found) != 1 {
+		t.Errorf("Search после сохранения: %v, %v", found, err)
+	}
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"cursovay/internal/dedup"
+	"cursovay/internal/model"
+	"fmt"
+)
+
+// FindDuplicates ищет группы вероятных дубликатов в текущем файле
+func (c *ManufacturerController) FindDuplicates(opts dedup.Options) []dedup.Group {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return dedup.Find(c.manufacturers, opts)
+}
+
+// MergeDuplicates сливает каждую группу записей (по ID) в запись
+// с наименьшим ID, остальные записи группы удаляются. Все группы
+// сливаются одним действием, которое можно отменить.
+func (c *ManufacturerController) MergeDuplicates(groups [][]int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	after := cloneManufacturers(c.manufacturers)
+	for _, ids := range groups {
+		if len(ids) < 2 {
+			continue
+		}
+		members := make([]model.Manufacturer, 0, len(ids))
+		for _, id := range ids {
+			i := indexOfID(after, id)
+			if i == -1 {
+				return fmt.Errorf("производитель с ID %d не найден", id)
+			}
+			members = append(members, after[i])
+		}
+
+		merged := dedup.Merge(members, c.fields())
+		kept := after[:0]
+		for _, m := range after {
+			switch {
+			case m.ID == merged.ID:
+				kept = append(kept, merged)
+			case containsID(ids, m.ID):
+				// удаляется: данные уже в merged
+			default:
+				kept = append(kept, m)
+			}
+		}
+		after = kept
+	}
+
+	return c.execute(&replaceCommand{
+		label:  "Merge duplicates",
+		before: cloneManufacturers(c.manufacturers),
+		after:  after,
+	})
+}
+
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// DefaultUndoDepth — сколько последних действий можно отменить по умолчанию
+const DefaultUndoDepth = 100
+
+// command — обратимое действие над списком производителей.
+// Название команды — ключ локализации (например, "Delete").
+type command interface {
+	name() string
+	do(data []model.Manufacturer) ([]model.Manufacturer, error)
+	undo(data []model.Manufacturer) ([]model.Manufacturer, error)
+}
+
+// addCommand добавляет производителя с уже назначенным ID
+type addCommand struct {
+	m model.Manufacturer
+}
+
+func (cmd *addCommand) name() string { return "Add" }
+
+func (cmd *addCommand) do(data []model.Manufacturer) ([]model.Manufacturer, error) {
+	return append(data, cmd.m), nil
+}
+
+func (cmd *addCommand) undo(data []model.Manufacturer) ([]model.Manufacturer, error) {
+	i := indexOfID(data, cmd.m.ID)
+	if i == -1 {
+		return nil, fmt.Errorf("производитель с ID %d не найден", cmd.m.ID)
+	}
+	return append(data[:i], data[i+1:]...), nil
+}
+
+// updateCommand заменяет запись, запоминая ее прежнее состояние
+type updateCommand struct {
+	before, after model.Manufacturer
+}
+
+func (cmd *updateCommand) name() string { return "Edit" }
+
+func (cmd *updateCommand) do(data []model.Manufacturer) ([]model.Manufacturer, error) {
+	return replaceByID(data, cmd.after)
+}
+
+func (cmd *updateCommand) undo(data []model.Manufacturer) ([]model.Manufacturer, error) {
+	return replaceByID(data, cmd.before)
+}
+
+// deleteCommand удаляет запись и при отмене возвращает ее на прежнее место
+type deleteCommand struct {
+	m     model.Manufacturer
+	index int
+}
+
+func (cmd *deleteCommand) name() string { return "Delete" }
+
+func (cmd *deleteCommand) do(data []model.Manufacturer) ([]model.Manufacturer, error) {
+	i := indexOfID(data, cmd.m.ID)
+	if i == -1 {
+		return nil, fmt.Errorf("производитель с ID %d не найден", cmd.m.ID)
+	}
+	cmd.m = data[i]
+	cmd.index = i
+	return append(data[:i], data[i+1:]...), nil
+}
+
+func (cmd *deleteCommand) undo(data []model.Manufacturer) ([]model.Manufacturer, error) {
+	i := cmd.index
+	if i > len(data) {
+		i = len(data)
+	}
+	data = append(data, model.Manufacturer{})
+	copy(data[i+1:], data[i:])
+	data[i] = cmd.m
+	return data, nil
+}
+
+// replaceCommand заменяет весь список: массовые правки и сортировки
+type replaceCommand struct {
+	label         string
+	before, after []model.Manufacturer
+}
+
+func (cmd *replaceCommand) name() string { return cmd.label }
+
+func (cmd *replaceCommand) do([]model.Manufacturer) ([]model.Manufacturer, error) {
+	return cloneManufacturers(cmd.after), nil
+}
+
+func (cmd *replaceCommand) undo([]model.Manufacturer) ([]model.Manufacturer, error) {
+	return cloneManufacturers(cmd.before), nil
+}
+
+// schemaCommand меняет пользовательские поля файла вместе с записями,
+// значения которых при этом стерты или приведены к новому типу
+type schemaCommand struct {
+	state         *fileState
+	before, after model.Schema
+	records       replaceCommand
+}
+
+func (cmd *schemaCommand) name() string { return "Edit custom fields" }
+
+func (cmd *schemaCommand) do(data []model.Manufacturer) ([]model.Manufacturer, error) {
+	cmd.state.schema = cmd.after
+	cmd.state.settings = true
+	return cmd.records.do(data)
+}
+
+func (cmd *schemaCommand) undo(data []model.Manufacturer) ([]model.Manufacturer, error) {
+	cmd.state.schema = cmd.before
+	cmd.state.settings = true
+	return cmd.records.undo(data)
+}
+
+// history хранит выполненные и отмененные команды одного файла
+type history struct {
+	done   []command
+	undone []command
+}
+
+func (h *history) push(cmd command, depth int) {
+	h.done = append(h.done, cmd)
+	h.undone = nil
+	h.trim(depth)
+}
+
+// trim оставляет не больше depth последних команд
+func (h *history) trim(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	if len(h.done) > depth {
+		h.done = append([]command(nil), h.done[len(h.done)-depth:]...)
+	}
+	if len(h.undone) > depth {
+		h.undone = append([]command(nil), h.undone[len(h.undone)-depth:]...)
+	}
+}
+
+// SetUndoDepth задает, сколько последних действий можно отменить.
+// 0 отключает историю.
+func (c *ManufacturerController) SetUndoDepth(depth int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if depth < 0 {
+		depth = 0
+	}
+	c.undoDepth = depth
+	for _, state := range c.files {
+		state.history.trim(depth)
+	}
+}
+
+// execute выполняет команду над текущим файлом и запоминает ее для отмены.
+// Вызывается под c.mu.
+func (c *ManufacturerController) execute(cmd command) error {
+	if err := c.applyStep(cmd.do, true); err != nil {
+		return err
+	}
+	c.file().history.push(cmd, c.undoDepth)
+	return nil
+}
+
+// applyStep заменяет данные результатом step, отмечает измененные записи
+// и сверяет типы продукции со справочником; spell приводит типы измененных
+// записей к написанию из справочника. Отмена его не использует, чтобы
+// вернуть записи в точности такими, какими они были. Файл не записывается:
+// изменения попадают в него только при явном сохранении (SaveToFile).
+func (c *ManufacturerController) applyStep(step func([]model.Manufacturer) ([]model.Manufacturer, error), spell bool) error {
+	state := c.file()
+	before := c.manufacturers
+	after, err := step(cloneManufacturers(before))
+	if err != nil {
+		return err
+	}
+	c.catalogueStep(before, after, spell)
+	recordChanges(state.changes, before, after)
+	c.manufacturers = after
+	return nil
+}
+
+// Undo отменяет последнее действие в текущем файле и возвращает его название
+func (c *ManufacturerController) Undo() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h := c.file().history
+	if len(h.done) == 0 {
+		return "", errors.New("нет действий для отмены")
+	}
+	cmd := h.done[len(h.done)-1]
+	if err := c.applyStep(cmd.undo, false); err != nil {
+		return "", err
+	}
+	h.done = h.done[:len(h.done)-1]
+	h.undone = append(h.undone, cmd)
+	return cmd.name(), nil
+}
+
+// Redo повторяет последнее отмененное действие и возвращает его название
+func (c *ManufacturerController) Redo() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h := c.file().history
+	if len(h.undone) == 0 {
+		return "", errors.New("нет действий для повтора")
+	}
+	cmd := h.undone[len(h.undone)-1]
+	if err := c.applyStep(cmd.do, true); err != nil {
+		return "", err
+	}
+	h.undone = h.undone[:len(h.undone)-1]
+	h.done = append(h.done, cmd)
+	return cmd.name(), nil
+}
+
+// CanUndo сообщает, есть ли в текущем файле действие для отмены
+func (c *ManufacturerController) CanUndo() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.file().history.done) > 0
+}
+
+// CanRedo сообщает, есть ли в текущем файле отмененное действие для повтора
+func (c *ManufacturerController) CanRedo() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.file().history.undone) > 0
+}
+
+// ReplaceManufacturers заменяет весь список одним действием, которое можно
+// отменить целиком. label — ключ локализации для названия действия.
+func (c *ManufacturerController) ReplaceManufacturers(label string, data []model.Manufacturer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.execute(&replaceCommand{
+		label:  label,
+		before: cloneManufacturers(c.manufacturers),
+		after:  cloneManufacturers(data),
+	})
+}
+
+// recordChanges сверяет с файлом записи, которые отличаются в before и after.
+// Изменение одного порядка записей (сортировка) изменением не считается.
+func recordChanges(t *changeTracker, before, after []model.Manufacturer) {
+	old := make(map[int]model.Manufacturer, len(before))
+	for _, m := range before {
+		old[m.ID] = m
+	}
+
+	for i := range after {
+		m := &after[i]
+		if prev, ok := old[m.ID]; !ok || !reflect.DeepEqual(prev, *m) {
+			t.update(m.ID, m)
+		}
+		delete(old, m.ID)
+	}
+	for id := range old {
+		t.update(id, nil)
+	}
+}
+
+func indexOfID(data []model.Manufacturer, id int) int {
+	for i, m := range data {
+		if m.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func replaceByID(data []model.Manufacturer, m model.Manufacturer) ([]model.Manufacturer, error) {
+	i := indexOfID(data, m.ID)
+	if i == -1 {
+		return nil, fmt.Errorf("производитель с ID %d не найден", m.ID)
+	}
+	data[i] = m
+	return data, nil
+}
+
+func cloneManufacturers(data []model.Manufacturer) []model.Manufacturer {
+	return append([]model.Manufacturer(nil), data...)
+}
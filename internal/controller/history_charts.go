@@ -0,0 +1,298 @@
+package controller
+
+import (
+	"bytes"
+	"cursovay/internal/model"
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+)
+
+// Группировка данных для графиков по истории выручки
+const (
+	GroupByManufacturer = "manufacturer"
+	GroupByProductType  = "productType"
+)
+
+// maxTrendSeries — сколько линий с наибольшей выручкой показывают
+// графики тренда и роста: больше линий на одном графике не различить
+const maxTrendSeries = 8
+
+// revenueSeries — история выручки одного производителя или типа продукции
+type revenueSeries struct {
+	name    string
+	history model.History
+}
+
+// revenueSeriesBy собирает историю выручки по производителям или по типам
+// продукции, начиная с наибольшей выручки в последнем году. Для типа
+// продукции выручка за год — сумма выручки его производителей, у которых
+// этот год есть в истории. Записи без истории не учитываются.
+func revenueSeriesBy(manufacturers []model.Manufacturer, groupBy string) ([]revenueSeries, error) {
+	var result []revenueSeries
+	switch groupBy {
+	case GroupByManufacturer, "":
+		for _, m := range manufacturers {
+			if len(m.History) > 0 {
+				result = append(result, revenueSeries{name: m.Name, history: m.History})
+			}
+		}
+	case GroupByProductType:
+		totals := make(map[string]map[int]model.YearStat)
+		for _, m := range manufacturers {
+			if len(m.History) == 0 {
+				continue
+			}
+			// Производитель с несколькими типами входит в ряд каждого
+			for _, productType := range typesOf(&m) {
+				years := totals[productType]
+				if years == nil {
+					years = make(map[int]model.YearStat)
+					totals[productType] = years
+				}
+				for _, s := range m.History {
+					total := years[s.Year]
+					total.Year = s.Year
+					total.Revenue += s.Revenue
+					total.Employees += s.Employees
+					years[s.Year] = total
+				}
+			}
+		}
+		for productType, years := range totals {
+			var history model.History
+			for _, s := range years {
+				history = append(history, s)
+			}
+			result = append(result, revenueSeries{name: productType, history: history.Sorted()})
+		}
+	default:
+		return nil, fmt.Errorf("unknown grouping: %s", groupBy)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no revenue history available")
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, _ := result[i].history.Latest()
+		b, _ := result[j].history.Latest()
+		if a.Revenue != b.Revenue {
+			return a.Revenue > b.Revenue
+		}
+		return result[i].name < result[j].name
+	})
+	return result, nil
+}
+
+// generateRevenueTrendChart строит выручку по годам: по линии на каждого
+// производителя или тип продукции
+func (c *ManufacturerController) generateRevenueTrendChart(manufacturers []model.Manufacturer, showValues bool, groupBy string) ([]byte, error) {
+	series, err := revenueSeriesBy(manufacturers, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	text := currentLocalization.Charts.RevenueTrend
+	text.YLabel = c.revenueLabel(text.YLabel)
+	return yearLinesChart(text, series, showValues, "%.2f",
+		func(h model.History) plotter.XYs {
+			pts := make(plotter.XYs, len(h))
+			for i, s := range h {
+				pts[i] = plotter.XY{X: float64(s.Year), Y: s.Revenue}
+			}
+			return pts
+		})
+}
+
+// generateRevenueGrowthChart строит рост выручки к предыдущему году в процентах
+func (c *ManufacturerController) generateRevenueGrowthChart(manufacturers []model.Manufacturer, showValues bool, groupBy string) ([]byte, error) {
+	series, err := revenueSeriesBy(manufacturers, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	return yearLinesChart(currentLocalization.Charts.RevenueGrowth, series, showValues, "%.1f%%",
+		func(h model.History) plotter.XYs {
+			growth := h.Growth()
+			var pts plotter.XYs
+			for _, s := range h {
+				if g, ok := growth[s.Year]; ok {
+					pts = append(pts, plotter.XY{X: float64(s.Year), Y: g * 100})
+				}
+			}
+			return pts
+		})
+}
+
+// yearLinesChart рисует по линии с точками на каждую из первых
+// maxTrendSeries историй; points переводит историю в точки (год, значение)
+func yearLinesChart(text ChartLocalization, series []revenueSeries, showValues bool, valueFormat string,
+	points func(model.History) plotter.XYs) ([]byte, error) {
+	config := DefaultChartConfig()
+
+	p := plot.New()
+	p.X.Label.TextStyle.Font.Size = config.FontSize
+	p.Y.Label.TextStyle.Font.Size = config.FontSize
+	p.Title.TextStyle.Font.Size = config.FontSize + 2
+	p.Legend.TextStyle.Font.Size = config.FontSize - 2
+	p.X.Label.Padding = config.MarginBot
+	p.Y.Label.Padding = config.MarginLeft
+	p.Legend.Top = true
+
+	p.Title.Text = text.Title
+	p.X.Label.Text = text.XLabel
+	p.Y.Label.Text = text.YLabel
+	p.X.Tick.Marker = yearTicks{}
+	p.Add(plotter.NewGrid())
+
+	if len(series) > maxTrendSeries {
+		series = series[:maxTrendSeries]
+	}
+	drawn := 0
+	for i, s := range series {
+		pts := points(s.history)
+		if len(pts) == 0 {
+			continue
+		}
+		line, scatter, err := plotter.NewLinePoints(pts)
+		if err != nil {
+			return nil, err
+		}
+		line.Color = plotutil.Color(i)
+		scatter.Color = plotutil.Color(i)
+		p.Add(line, scatter)
+		p.Legend.Add(s.name, line, scatter)
+
+		if showValues {
+			values := make([]string, len(pts))
+			for j := range pts {
+				values[j] = fmt.Sprintf(valueFormat, pts[j].Y)
+			}
+			labels, err := plotter.NewLabels(&ChartLabels{XYs: pts, Labels: values})
+			if err != nil {
+				return nil, err
+			}
+			p.Add(labels)
+		}
+		drawn++
+	}
+	if drawn == 0 {
+		return nil, fmt.Errorf("not enough revenue history: at least two consecutive years are needed")
+	}
+	return chartPNG(p, config)
+}
+
+// generateCAGRChart строит среднегодовой рост выручки (CAGR) между первым
+// и последним годом истории для каждого производителя или типа продукции
+func (c *ManufacturerController) generateCAGRChart(manufacturers []model.Manufacturer, colorScheme string, showValues, sortData bool, groupBy string) ([]byte, error) {
+	series, err := revenueSeriesBy(manufacturers, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var values plotter.Values
+	var labels []string
+	for _, s := range series {
+		if cagr, ok := s.history.CAGR(); ok {
+			values = append(values, cagr*100)
+			labels = append(labels, s.name)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("not enough revenue history: at least two years with positive revenue are needed")
+	}
+	if sortData {
+		order := make([]int, len(values))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool { return values[order[i]] > values[order[j]] })
+		sortedValues := make(plotter.Values, len(values))
+		sortedLabels := make([]string, len(labels))
+		for i, k := range order {
+			sortedValues[i], sortedLabels[i] = values[k], labels[k]
+		}
+		values, labels = sortedValues, sortedLabels
+	}
+
+	config := DefaultChartConfig()
+	p := plot.New()
+	p.X.Label.TextStyle.Font.Size = config.FontSize
+	p.Y.Label.TextStyle.Font.Size = config.FontSize
+	p.Title.TextStyle.Font.Size = config.FontSize + 2
+	p.X.Label.Padding = config.MarginBot
+	p.Y.Label.Padding = config.MarginLeft
+
+	p.Title.Text = currentLocalization.Charts.RevenueCAGR.Title
+	p.X.Label.Text = currentLocalization.Charts.RevenueCAGR.XLabel
+	p.Y.Label.Text = currentLocalization.Charts.RevenueCAGR.YLabel
+
+	bars, err := plotter.NewBarChart(values, vg.Points(50))
+	if err != nil {
+		return nil, err
+	}
+	switch colorScheme {
+	case "Blue Theme":
+		bars.Color = color.RGBA{0, 0, 255, 255}
+	case "Green Theme":
+		bars.Color = color.RGBA{0, 255, 0, 255}
+	case "Rainbow":
+		bars.Color = color.RGBA{255, 0, 0, 255}
+	}
+
+	if showValues {
+		xys := make(plotter.XYs, len(values))
+		texts := make([]string, len(values))
+		for i, v := range values {
+			xys[i] = plotter.XY{X: float64(i), Y: v}
+			texts[i] = fmt.Sprintf("%.1f%%", v)
+		}
+		valueLabels, err := plotter.NewLabels(&ChartLabels{XYs: xys, Labels: texts})
+		if err != nil {
+			return nil, err
+		}
+		p.Add(valueLabels)
+	}
+
+	p.Add(bars)
+	p.NominalX(labels...)
+	return chartPNG(p, config)
+}
+
+// chartPNG отрисовывает график в PNG заданного размера
+func chartPNG(p *plot.Plot, config ChartConfig) ([]byte, error) {
+	w := new(bytes.Buffer)
+	wt, err := p.WriterTo(config.Width, config.Height, "png")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := wt.WriteTo(w); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+// yearTicks ставит деления оси только на целые годы
+type yearTicks struct{}
+
+func (yearTicks) Ticks(min, max float64) []plot.Tick {
+	first, last := int(math.Ceil(min)), int(math.Floor(max))
+	step := 1
+	for (last-first)/step > 12 {
+		step *= 2
+	}
+	var ticks []plot.Tick
+	for year := first; year <= last; year++ {
+		tick := plot.Tick{Value: float64(year)}
+		if (year-first)%step == 0 {
+			tick.Label = strconv.Itoa(year)
+		}
+		ticks = append(ticks, tick)
+	}
+	return ticks
+}
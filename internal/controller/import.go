@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"cursovay/internal/importer"
+	"cursovay/internal/model"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ImportMode — как импортированные записи сочетаются с текущими
+type ImportMode int
+
+const (
+	// ImportAppend добавляет записи с новыми ID
+	ImportAppend ImportMode = iota
+	// ImportReplace заменяет все записи импортированными, сохраняя их ID
+	ImportReplace
+	// ImportMerge заменяет записи с совпадающим ID и добавляет остальные
+	ImportMerge
+)
+
+// ImportModes — названия режимов для интерфейса и утилиты (ключи локализации)
+var ImportModes = map[string]ImportMode{
+	"append":  ImportAppend,
+	"replace": ImportReplace,
+	"merge":   ImportMerge,
+}
+
+// ParseImportMode разбирает название режима импорта
+func ParseImportMode(name string) (ImportMode, error) {
+	mode, ok := ImportModes[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("неизвестный режим импорта: %s (append, replace или merge)", name)
+	}
+	return mode, nil
+}
+
+// ImportManufacturers добавляет импортированные записи одним действием,
+// которое можно отменить целиком. Записям назначаются новые ID.
+func (c *ManufacturerController) ImportManufacturers(manufacturers []model.Manufacturer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.importRecords("Import", manufacturers, ImportAppend)
+}
+
+// ApplyMerge заменяет данные текущего файла результатом слияния
+// (merge.Plan.Result) одним действием "Merge". Записи с ID 0 получают новые ID.
+func (c *ManufacturerController) ApplyMerge(manufacturers []model.Manufacturer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.importRecords("Merge", manufacturers, ImportReplace)
+}
+
+// ImportFromJSON читает файл в формате ExportToJSON или JSON Lines
+// и импортирует записи, прошедшие проверку по правилам. Отклоненные записи
+// перечислены в отчете с их индексом. При замене данных отклоненная запись
+// пропала бы из файла, поэтому в этом режиме импорт отменяется целиком:
+// возвращается отчет и ошибка, данные не меняются.
+func (c *ManufacturerController) ImportFromJSON(filePath string, mode ImportMode) (*importer.Result, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file: %v", err)
+	}
+	result, err := importer.ReadJSON(data, c.CustomFields(), c.ImportChecker(mode))
+	if err != nil {
+		return nil, err
+	}
+	if n := result.Rejected(); mode == ImportReplace && n > 0 {
+		return result, fmt.Errorf("замена данных отменена: %d записей не прошли проверку", n)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.importRecords("Import", result.Manufacturers, mode); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importRecords выполняет импорт одной командой label и записывает
+// итоговые ID обратно в records. ID, которые уже заняты или не заданы,
+// назначаются заново. Вызывается под c.mu.
+func (c *ManufacturerController) importRecords(label string, records []model.Manufacturer, mode ImportMode) error {
+	state := c.file()
+	lastID := c.nextID() - 1
+
+	var after []model.Manufacturer
+	if mode != ImportReplace {
+		after = cloneManufacturers(c.manufacturers)
+	}
+	taken := make(map[int]bool, len(after)+len(records))
+	merged := make(map[int]bool)
+	for _, m := range after {
+		taken[m.ID] = true
+	}
+	if mode != ImportAppend {
+		for _, m := range records {
+			if m.ID > lastID {
+				lastID = m.ID
+			}
+		}
+	}
+
+	for i := range records {
+		r := &records[i]
+		switch mode {
+		case ImportAppend:
+			r.ID = 0
+		case ImportMerge:
+			// Повтор ID в файле дает новую запись, а не второе обновление
+			if j := indexOfID(after, r.ID); r.ID > 0 && j != -1 && !merged[r.ID] {
+				after[j] = *r
+				merged[r.ID] = true
+				continue
+			}
+		}
+		if r.ID <= 0 || taken[r.ID] {
+			lastID++
+			r.ID = lastID
+		}
+		taken[r.ID] = true
+		after = append(after, *r)
+	}
+
+	if err := c.execute(&replaceCommand{
+		label:  label,
+		before: cloneManufacturers(c.manufacturers),
+		after:  after,
+	}); err != nil {
+		return err
+	}
+	state.lastID = lastID
+	return nil
+}
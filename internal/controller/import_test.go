@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Выгрузка в JSON и импорт с заменой возвращают те же записи
+func TestJSONRoundTrip(t *testing.T) {
+	data := testData()
+	data[0].Address = "ул. Ленина, д. 5, г. Ярославль" // адрес без частей
+	data[1].Street, data[1].City, data[1].Address = "Musterstraße 1", "Berlin", "Musterstraße 1, Berlin"
+	c, _ := newTestController(t, "data.csv", data)
+	before := c.GetCurrentData()
+
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := c.ExportToJSON(path); err != nil {
+		t.Fatalf("ExportToJSON: %v", err)
+	}
+	result, err := c.ImportFromJSON(path, ImportReplace)
+	if err != nil {
+		t.Fatalf("ImportFromJSON: %v", err)
+	}
+	if result.Rejected() != 0 {
+		t.Fatalf("отклонены записи: %+v", result.Issues)
+	}
+	if got := c.GetCurrentData(); !reflect.DeepEqual(got, before) {
+		t.Errorf("после обратного импорта\n%+v\nожидалось\n%+v", got, before)
+	}
+	if c.HasUnsavedChanges() {
+		t.Errorf("обратный импорт изменил записи: %+v", c.Changes())
+	}
+}
+
+// Замена данных с отклоненной записью не меняет данные
+func TestImportReplaceStopsOnRejected(t *testing.T) {
+	c, _ := newTestController(t, "data.csv", testData())
+	path := filepath.Join(t.TempDir(), "import.json")
+	data := `[{"id": 1, "name": "Alpha", "founded_year": 1990}, {"id": 2, "name": "Beta", "founded_year": 0}]`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := c.ImportFromJSON(path, ImportReplace)
+	if err == nil {
+		t.Fatal("замена с отклоненной записью не вернула ошибку")
+	}
+	if result == nil || result.Rejected() != 1 {
+		t.Errorf("отчет %+v", result)
+	}
+	if got := c.GetCurrentData(); !reflect.DeepEqual(got, testData()) || c.HasUnsavedChanges() {
+		t.Errorf("данные изменились: %+v", got)
+	}
+
+	// Добавление берет верные записи и сообщает об отклоненных
+	result, err = c.ImportFromJSON(path, ImportAppend)
+	if err != nil || len(result.Manufacturers) != 1 || len(c.GetCurrentData()) != 4 {
+		t.Errorf("добавление: %v, %+v", err, result)
+	}
+}
@@ -2,19 +2,21 @@ package controller
 
 import (
 	"bytes"
+	"cursovay/internal/address"
+	"cursovay/internal/country"
+	"cursovay/internal/currency"
 	"cursovay/internal/model"
+	"cursovay/internal/query"
 	"cursovay/internal/repository"
 	"cursovay/internal/service"
-	"encoding/csv"
+	"cursovay/internal/validation"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"image/color"
-	"io"
 	"os"
 	"sort"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -25,10 +27,22 @@ import (
 	"gonum.org/v1/plot/vg/draw"
 )
 
+// ManufacturerController работает с копией данных текущего файла:
+// записи загружаются из хранилища (Store, база SQLite или CSV), все
+// изменения применяются к этой копии с историей отмены и записываются
+// в файл целиком. Напрямую в хранилище контроллер не пишет,
+// поэтому копия и хранилище не расходятся из-за частичных изменений.
 type ManufacturerController struct {
 	service       *service.ManufacturerService
+	defaultStore  repository.Store
+	database      *repository.SQLiteStore // открытая база, если текущий файл — .db
 	manufacturers []model.Manufacturer
 	currentFile   string
+	files         map[string]*fileState // ID, изменения и история по каждому открытому файлу
+	undoDepth     int
+	rules         *validation.RuleSet // правила проверки записей
+	rates         *currency.Table     // курсы валют
+	currency      string              // валюта отчетов; пусто — базовая валюта курсов
 	mu            sync.RWMutex
 }
 
@@ -106,6 +120,9 @@ type ChartsLocalization struct {
 	FoundedBar    ChartLocalization `json:"founded_bar"`
 	ProductPie    ChartLocalization `json:"product_pie"`
 	RevenueTrend  ChartLocalization `json:"revenue_trend"`
+	RevenueGrowth ChartLocalization `json:"revenue_growth"`
+	RevenueCAGR   ChartLocalization `json:"revenue_cagr"`
+	CityBar       ChartLocalization `json:"city_bar"`
 }
 
 type Localization struct {
@@ -129,27 +146,38 @@ func (c *ManufacturerController) LoadLocalization(lang string) error {
 	return nil
 }
 
-func NewManufacturerController(repo *repository.ManufacturerRepository) *ManufacturerController {
+// NewManufacturerController создает контроллер поверх любого хранилища
+func NewManufacturerController(store repository.Store) *ManufacturerController {
 	return &ManufacturerController{
-		service:       service.NewManufacturerService(repo),
+		service:       service.NewManufacturerService(store),
+		defaultStore:  store,
+		files:         make(map[string]*fileState),
+		undoDepth:     DefaultUndoDepth,
+		rules:         validation.Default(),
+		rates:         currency.NewTable(currency.DefaultBase),
 		manufacturers: []model.Manufacturer{},
 		currentFile:   "",
 		mu:            sync.RWMutex{},
 	}
 }
 
+// GetAllManufacturers возвращает рабочие данные контроллера. Пока файл
+// не открыт, они один раз загружаются из хранилища через Store.
 func (c *ManufacturerController) GetAllManufacturers() ([]model.Manufacturer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	// Если данные уже загружены, возвращаем их
 	if len(c.manufacturers) > 0 {
 		return c.manufacturers, nil
 	}
 
 	// Иначе загружаем из сервиса
-	var err error
-	c.manufacturers, err = c.service.GetAll()
+	data, err := c.service.GetAll()
 	if err != nil {
 		return nil, err
 	}
+	c.manufacturers = data
 	return c.manufacturers, nil
 }
 
@@ -167,67 +195,37 @@ func (c *ManufacturerController) GetManufacturerByID(id int) (*model.Manufacture
 	return nil, fmt.Errorf("производитель с ID %d не найден", id)
 }
 
+// CreateManufacturer добавляет производителя так же, как AddManufacturer:
+// ID выдает контроллер по наибольшему ID текущего файла
 func (c *ManufacturerController) CreateManufacturer(m *model.Manufacturer) error {
-	if err := c.service.Create(m); err != nil {
-		return err
-	}
-	c.manufacturers = append(c.manufacturers, *m)
-	return nil
-
+	return c.AddManufacturer(m)
 }
 
 func (c *ManufacturerController) UpdateManufacturer(m *model.Manufacturer) error {
-	for i, item := range c.manufacturers {
-		if item.ID == m.ID {
-			c.manufacturers[i] = *m
-			// Сохраняем в файл, если он указан
-			if c.currentFile != "" {
-				return c.SaveToFile(c.currentFile)
-			}
-			return nil
-		}
-	}
-	return errors.New("manufacturer not found")
-}
-
-func (c *ManufacturerController) DeleteManufacturer(id int) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Удаляем через сервис
-	if err := c.service.Delete(id); err != nil {
-		return err
-	}
-
-	// Удаляем из локального кэша и находим индекс удаленного элемента
-	deletedIndex := -1
-	for i, item := range c.manufacturers {
-		if item.ID == id {
-			deletedIndex = i
-			c.manufacturers = append(c.manufacturers[:i], c.manufacturers[i+1:]...)
-			break
-		}
+	i := indexOfID(c.manufacturers, m.ID)
+	if i == -1 {
+		return errors.New("manufacturer not found")
 	}
-
-	if deletedIndex == -1 {
-		return fmt.Errorf("manufacturer with ID %d not found in cache", id)
-	}
-
-	// Перенумеровываем оставшиеся записи, начиная с удаленного индекса
-	for i := deletedIndex; i < len(c.manufacturers); i++ {
-		c.manufacturers[i].ID = i + 1 // ID начинаются с 1
+	address.Join(m)
+	normalizeRecordPhones(m)
+	m.SyncLatest()
+	if err := c.checkRecord(m); err != nil {
+		return err
 	}
+	return c.execute(&updateCommand{before: c.manufacturers[i], after: *m})
+}
 
-	// Сохраняем изменения, если файл указан
-	if c.currentFile != "" {
-		if err := c.SaveToFile(c.currentFile); err != nil {
-			// Восстанавливаем старые ID при ошибке сохранения
-			// (в реальном приложении нужно более сложное восстановление)
-			return fmt.Errorf("failed to save after deletion: %v", err)
-		}
-	}
+func (c *ManufacturerController) DeleteManufacturer(id int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return nil
+	// Удаляем из рабочих данных. ID остальных записей не меняются,
+	// а ID удаленной записи больше никогда не выдается, поэтому
+	// удаление можно отменить.
+	return c.execute(&deleteCommand{m: model.Manufacturer{ID: id}})
 }
 
 func (c *ManufacturerController) forceSaveToFile(filePath string) error {
@@ -239,35 +237,8 @@ func (c *ManufacturerController) forceSaveToFile(filePath string) error {
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-
-	// Записываем заголовки
-	headers := []string{"ID", "Name", "Country", "Address", "Phone", "Email", "ProductType", "FoundedYear", "Revenue"}
-	if err := writer.Write(headers); err != nil {
-		return fmt.Errorf("failed to write headers: %v", err)
-	}
-
-	// Записываем данные
-	for _, m := range c.manufacturers {
-		record := []string{
-			strconv.Itoa(m.ID),
-			m.Name,
-			m.Country,
-			m.Address,
-			m.Phone,
-			m.Email,
-			m.ProductType,
-			strconv.Itoa(m.FoundedYear),
-			strconv.FormatFloat(m.Revenue, 'f', 2, 64),
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("failed to write record: %v", err)
-		}
-	}
-
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("csv writer error: %v", err)
+	if err := repository.WriteCSV(file, c.manufacturers, c.fields(), c.metadata()); err != nil {
+		return fmt.Errorf("failed to write csv: %v", err)
 	}
 
 	// Закрываем файл перед переименованием
@@ -292,60 +263,26 @@ func (c *ManufacturerController) LoadFromFile(filePath string) ([]model.Manufact
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if IsDatabaseFile(filePath) {
+		return c.loadFromDatabase(filePath)
+	}
+
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка открытия файла: %v", err) // Изменено на return nil, error
 	}
 	defer file.Close()
 
-	// Создаем CSV reader
-	r := csv.NewReader(file)
-	r.Comma = ','             // Указываем разделитель
-	r.TrimLeadingSpace = true // Убираем пробелы в начале поля
-
-	// Читаем заголовок
-	header, err := r.Read()
+	// Колонки сопоставляются по заголовку, старые 9-колоночные файлы тоже читаются
+	manufacturers, meta, err := repository.ReadCSV(file)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка чтения заголовка: %v", err) // Изменено на return nil, error
-	}
-	if len(header) != 9 {
-		return nil, fmt.Errorf("невалидный CSV-заголовок") // Изменено на return nil, error
+		return nil, fmt.Errorf("ошибка чтения CSV: %v", err)
 	}
 
-	var manufacturers []model.Manufacturer
-	for {
-		record, err := r.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			continue // Пропускаем битые строки
-		}
-
-		if len(record) != 9 {
-			continue
-		}
-
-		// Парсинг полей
-		id, _ := strconv.Atoi(record[0])
-		year, _ := strconv.Atoi(record[7])
-		revenue, _ := strconv.ParseFloat(record[8], 64)
-
-		manufacturers = append(manufacturers, model.Manufacturer{
-			ID:          id,
-			Name:        record[1],
-			Country:     record[2],
-			Address:     record[3],
-			Phone:       record[4],
-			Email:       record[5],
-			ProductType: record[6],
-			FoundedYear: year,
-			Revenue:     revenue,
-		})
+	c.closeDatabase()
+	if err := c.loaded(filePath, manufacturers, meta); err != nil {
+		return nil, fmt.Errorf("ошибка чтения CSV: %v", err)
 	}
-
-	c.manufacturers = manufacturers
-	c.currentFile = filePath
 	return manufacturers, nil // Возвращаем оба значения
 }
 
@@ -355,50 +292,28 @@ func (c *ManufacturerController) GetCurrentData() []model.Manufacturer {
 	return c.manufacturers
 }
 
+// SaveToFile записывает данные, пользовательские поля и справочник типов
+// продукции в CSV или базу SQLite и сбрасывает список несохраненных изменений.
+// Изменения записей сами в файл не попадают: сохранение всегда явное.
 func (c *ManufacturerController) SaveToFile(filePath string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if IsDatabaseFile(filePath) {
+		return c.saveToDatabase(filePath)
+	}
+
 	file, err := os.Create(filePath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Записываем заголовки
-	headers := []string{
-		"ID",
-		"Name",
-		"Country",
-		"Address",
-		"Phone",
-		"Email",
-		"ProductType",
-		"FoundedYear",
-		"Revenue",
-	}
-	if err := writer.Write(headers); err != nil {
+	if err := repository.WriteCSV(file, c.manufacturers, c.fields(), c.metadata()); err != nil {
 		return err
 	}
 
-	// Записываем данные
-	for _, m := range c.manufacturers {
-		record := []string{
-			strconv.Itoa(m.ID),
-			m.Name,
-			m.Country,
-			m.Address,
-			m.Phone,
-			m.Email,
-			m.ProductType,
-			strconv.Itoa(m.FoundedYear),
-			strconv.FormatFloat(m.Revenue, 'f', 2, 64),
-		}
-		if err := writer.Write(record); err != nil {
-			return err
-		}
-	}
-
+	c.markSaved(filePath)
 	c.currentFile = filePath
 	return nil
 }
@@ -430,8 +345,15 @@ func (c *ManufacturerController) ExportToPDF(filePath string) error {
 	// Устанавливаем шрифт для таблицы
 	pdf.SetFont("Arial", "B", 12)
 
+	// Выручка в отчете — в валюте отчетов
+	report, err := c.convertRecords(c.manufacturers)
+	if err != nil {
+		return err
+	}
+	code := c.reportCurrency()
+
 	// Заголовки столбцов
-	headers := []string{"ID", "Name", "Country", "Revenue", "Product Type"}
+	headers := []string{"ID", "Name", "Country", "Revenue, " + code, "Product Type"}
 	widths := []float64{15, 50, 40, 30, 55}
 
 	// Рисуем заголовки
@@ -444,15 +366,28 @@ func (c *ManufacturerController) ExportToPDF(filePath string) error {
 	pdf.SetFont("Arial", "", 10)
 
 	// Заполняем таблицу данными
-	for _, m := range c.manufacturers {
+	total := 0.0
+	for _, m := range report {
+		total += m.Revenue
 		pdf.CellFormat(widths[0], 6, strconv.Itoa(m.ID), "1", 0, "", false, 0, "")
 		pdf.CellFormat(widths[1], 6, m.Name, "1", 0, "", false, 0, "")
-		pdf.CellFormat(widths[2], 6, m.Country, "1", 0, "", false, 0, "")
+		pdf.CellFormat(widths[2], 6, country.Display(m.Country, "en"), "1", 0, "", false, 0, "")
 		pdf.CellFormat(widths[3], 6, strconv.FormatFloat(m.Revenue, 'f', 2, 64), "1", 0, "R", false, 0, "")
 		pdf.CellFormat(widths[4], 6, m.ProductType, "1", 0, "", false, 0, "")
 		pdf.Ln(-1)
+		// Теги и пользовательские поля — отдельной строкой под записью
+		if extras := extrasLine(&m, c.file().schema); extras != "" {
+			pdf.MultiCell(widths[0]+widths[1]+widths[2]+widths[3]+widths[4], 5, extras, "1", "", false)
+		}
 	}
 
+	// Итог выручки
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2], 6, "Total", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[3], 6, strconv.FormatFloat(total, 'f', 2, 64), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[4], 6, "", "1", 0, "", false, 0, "")
+	pdf.Ln(-1)
+
 	// Сохраняем PDF в файл
 	return pdf.OutputFileAndClose(filePath)
 }
@@ -475,12 +410,47 @@ func (c *ManufacturerController) ExportToJSON(filePath string) error {
 	return nil
 }
 
+// ExportToCSV записывает текущие данные в отдельный CSV-файл без
+// метаданных, не меняя текущий файл контроллера
+func (c *ManufacturerController) ExportToCSV(filePath string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %v", err)
+	}
+	defer file.Close()
+
+	// Экспорт — обычный CSV для Excel и других программ, поэтому
+	// служебные строки "# ключ: значение" в него не пишутся
+	return repository.WriteCSV(file, c.manufacturers, c.fields(), nil)
+}
+
+// ExportToXLSX записывает текущие данные в книгу Excel
+func (c *ManufacturerController) ExportToXLSX(filePath string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create XLSX file: %v", err)
+	}
+	defer file.Close()
+
+	if err := repository.WriteXLSX(file, c.manufacturers, c.fields()); err != nil {
+		return fmt.Errorf("failed to write XLSX file: %v", err)
+	}
+	return nil
+}
+
 func (c *ManufacturerController) GenerateChart(params map[string]interface{}) ([]byte, error) {
 	// Получаем параметры
 	chartType := params["type"].(string)
 	colorScheme := params["colorScheme"].(string)
 	showValues := params["showValues"].(bool)
 	sortData := params["sortData"].(bool)
+	groupBy, _ := params["groupBy"].(string) // для графиков по истории выручки
 
 	// Получаем данные
 	manufacturers := c.GetCurrentData()
@@ -488,6 +458,27 @@ func (c *ManufacturerController) GenerateChart(params map[string]interface{}) ([
 		return nil, fmt.Errorf("no data available")
 	}
 
+	// Выручка пересчитывается в валюту отчетов; тогда порядок
+	// по выручке из базы не подходит
+	c.mu.RLock()
+	converting := c.needsConversion(manufacturers)
+	c.mu.RUnlock()
+	if converting {
+		converted, err := c.InReportCurrency(manufacturers)
+		if err != nil {
+			return nil, err
+		}
+		manufacturers = converted
+	}
+
+	// Для базы SQLite сортировку данных графика выполняет сама база
+	if sortData && !converting {
+		if sorted, ok := c.chartDataFromDatabase(chartType); ok {
+			manufacturers = sorted
+			sortData = false
+		}
+	}
+
 	switch chartType {
 	case "revenue_bar":
 		return c.generateRevenueBarChart(manufacturers, colorScheme, showValues, sortData)
@@ -496,7 +487,13 @@ func (c *ManufacturerController) GenerateChart(params map[string]interface{}) ([
 	case "product_pie":
 		return c.generateProductTypePieChart(manufacturers, colorScheme, showValues)
 	case "revenue_line":
-		return c.generateRevenueTrendChart(manufacturers, colorScheme, showValues, sortData)
+		return c.generateRevenueTrendChart(manufacturers, showValues, groupBy)
+	case "revenue_growth":
+		return c.generateRevenueGrowthChart(manufacturers, showValues, groupBy)
+	case "revenue_cagr":
+		return c.generateCAGRChart(manufacturers, colorScheme, showValues, sortData, groupBy)
+	case "city_bar":
+		return c.generateCityBarChart(manufacturers, colorScheme, showValues)
 	default:
 		return nil, fmt.Errorf("unknown chart type: %s", chartType)
 	}
@@ -520,7 +517,7 @@ func (c *ManufacturerController) generateRevenueBarChart(manufacturers []model.M
 	// Устанавливаем заголовки из локализации
 	p.Title.Text = currentLocalization.Charts.RevenueBar.Title
 	p.X.Label.Text = currentLocalization.Charts.RevenueBar.XLabel
-	p.Y.Label.Text = currentLocalization.Charts.RevenueBar.YLabel
+	p.Y.Label.Text = c.revenueLabel(currentLocalization.Charts.RevenueBar.YLabel)
 
 	// Подготавливаем данные
 	var values []float64
@@ -715,28 +712,12 @@ func (c *ManufacturerController) generateProductTypePieChart(manufacturers []mod
 	// Устанавливаем заголовки из локализации
 	p.Title.Text = currentLocalization.Charts.ProductPie.Title
 
-	// Создаем карту для подсчета количества каждого типа продукции
-	productCounts := make(map[string]float64)
-	for _, m := range manufacturers {
-		productCounts[m.ProductType]++
-	}
-
 	// Создаем данные для круговой диаграммы
 	var values plotter.Values
 	var labels []string
 
-	// Создаем слайсы для сортировки
-	type kv struct {
-		Key   string
-		Value float64
-	}
-	var sorted []kv
-	for k, v := range productCounts {
-		sorted = append(sorted, kv{k, v})
-	}
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].Value > sorted[j].Value
-	})
+	// Производитель с несколькими типами продукции входит в сектор каждого
+	sorted := c.countBy("productType", manufacturers)
 
 	// Добавляем секторы
 	colors := []color.Color{
@@ -750,17 +731,17 @@ func (c *ManufacturerController) generateProductTypePieChart(manufacturers []mod
 
 	total := 0.0
 	for _, item := range sorted {
-		total += item.Value
+		total += float64(item.Count)
 	}
 
 	for i, item := range sorted {
-		value := item.Value
+		value := float64(item.Count)
 		percentage := value / total
 		values = append(values, percentage)
-		labels = append(labels, item.Key)
+		labels = append(labels, item.Value)
 		colorIndex := i % len(colors)
 		if showValues {
-			label := fmt.Sprintf("%s\n%.1f%%", item.Key, percentage*100)
+			label := fmt.Sprintf("%s\n%.1f%%", item.Value, percentage*100)
 			// Create a custom legend entry with a colored box
 			p.Legend.Add(label, &ColoredBox{
 				Color: colors[colorIndex],
@@ -792,105 +773,13 @@ func (c *ManufacturerController) generateProductTypePieChart(manufacturers []mod
 	return w.Bytes(), nil
 }
 
-func (c *ManufacturerController) generateRevenueTrendChart(manufacturers []model.Manufacturer, colorScheme string, showValues, sortData bool) ([]byte, error) {
-	config := DefaultChartConfig()
-	// Для графика тренда делаем более широкий размер
-	config.Width = 1400
-	config.Height = 1000
-	
-	// Создаем новый график
-	p := plot.New()
-	
-	// Устанавливаем размеры и отступы
-	p.X.Label.TextStyle.Font.Size = config.FontSize
-	p.Y.Label.TextStyle.Font.Size = config.FontSize
-	p.Title.TextStyle.Font.Size = config.FontSize + 2
-	p.Legend.TextStyle.Font.Size = config.FontSize - 2
-	
-	p.X.Label.Padding = config.MarginBot
-	p.Y.Label.Padding = config.MarginLeft
-	
-	// Устанавливаем заголовки из локализации
-	p.Title.Text = currentLocalization.Charts.RevenueTrend.Title
-	p.X.Label.Text = currentLocalization.Charts.RevenueTrend.XLabel
-	p.Y.Label.Text = currentLocalization.Charts.RevenueTrend.YLabel
-
-	// Создаем точки для графика
-	pts := make(plotter.XYs, len(manufacturers))
-	for i, m := range manufacturers {
-		pts[i].X = float64(m.FoundedYear)
-		pts[i].Y = m.Revenue
-	}
-
-	// Сортируем точки по году основания если нужно
-	if sortData {
-		sort.Slice(pts, func(i, j int) bool {
-			return pts[i].X < pts[j].X
-		})
-	}
-
-	// Создаем линейный график
-	line, err := plotter.NewLine(pts)
-	if err != nil {
-		return nil, err
-	}
-
-	// Создаем точки на графике
-	scatter, err := plotter.NewScatter(pts)
-	if err != nil {
-		return nil, err
-	}
-
-	// Применяем цветовую схему
-	switch colorScheme {
-	case "Blue Theme":
-		line.Color = color.RGBA{0, 0, 255, 255}
-		scatter.Color = color.RGBA{0, 0, 200, 255}
-	case "Green Theme":
-		line.Color = color.RGBA{0, 255, 0, 255}
-		scatter.Color = color.RGBA{0, 200, 0, 255}
-	case "Rainbow":
-		line.Color = color.RGBA{255, 0, 0, 255}
-		scatter.Color = color.RGBA{0, 0, 255, 255}
-	default:
-		line.Color = color.RGBA{0, 0, 0, 255}
-		scatter.Color = color.RGBA{0, 0, 0, 255}
-	}
-
-	// Добавляем значения если нужно
-	if showValues {
-		labelStrings := make([]string, len(pts))
-		for i := range pts {
-			labelStrings[i] = fmt.Sprintf("%.2f", pts[i].Y)
-		}
-		labels, err := plotter.NewLabels(&ChartLabels{
-			XYs:    pts,
-			Labels: labelStrings,
-		})
-		if err != nil {
-			return nil, err
-		}
-		p.Add(labels)
-	}
-
-	p.Add(line, scatter)
-	p.Add(plotter.NewGrid())
-
-	// Сохраняем график в буфер с фиксированным размером
-	w := new(bytes.Buffer)
-	wt, err := p.WriterTo(config.Width, config.Height, "png")
-	if err != nil {
-		return nil, err
-	}
-	_, err = wt.WriteTo(w)
-	if err != nil {
-		return nil, err
-	}
-
-	return w.Bytes(), nil
+func (c *ManufacturerController) Sort(manufacturers []model.Manufacturer, column string, ascending bool) ([]model.Manufacturer, error) {
+	return c.SortBy(manufacturers, []repository.SortKey{{Field: column, Ascending: ascending}})
 }
 
-func (c *ManufacturerController) Sort(manufacturers []model.Manufacturer, column string, ascending bool) ([]model.Manufacturer, error) {
+// SortBy устойчиво сортирует записи по нескольким ключам (например,
+// страна по возрастанию, затем выручка по убыванию). Исходный срез не меняется.
+func (c *ManufacturerController) SortBy(manufacturers []model.Manufacturer, keys []repository.SortKey) ([]model.Manufacturer, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -898,96 +787,48 @@ func (c *ManufacturerController) Sort(manufacturers []model.Manufacturer, column
 		return manufacturers, nil
 	}
 
-	// Создаем копию для сортировки, чтобы не менять оригинальный массив
-	sorted := make([]model.Manufacturer, len(manufacturers))
-	copy(sorted, manufacturers)
-
-	var sortErr error
-	sort.Slice(sorted, func(i, j int) bool {
-		switch column {
-		case "id":
-			return c.compareInt(sorted[i].ID, sorted[j].ID, ascending)
-		case "name":
-			return c.compareString(sorted[i].Name, sorted[j].Name, ascending)
-		case "country":
-			return c.compareString(sorted[i].Country, sorted[j].Country, ascending)
-		case "address":
-			return c.compareString(sorted[i].Address, sorted[j].Address, ascending)
-		case "phone":
-			return c.compareString(sorted[i].Phone, sorted[j].Phone, ascending)
-		case "email":
-			return c.compareString(sorted[i].Email, sorted[j].Email, ascending)
-		case "productType":
-			return c.compareString(sorted[i].ProductType, sorted[j].ProductType, ascending)
-		case "foundedYear":
-			return c.compareInt(sorted[i].FoundedYear, sorted[j].FoundedYear, ascending)
-		case "revenue":
-			return c.compareFloat(sorted[i].Revenue, sorted[j].Revenue, ascending)
-		default:
-			sortErr = fmt.Errorf("неизвестный столбец для сортировки: %s", column)
-			return false
-		}
-	})
-
-	return sorted, sortErr
-}
-
-// Вспомогательные методы для сравнения разных типов данных
-func (c *ManufacturerController) compareInt(a, b int, ascending bool) bool {
-	if ascending {
-		return a < b
+	// Выручка в разных валютах сравнивается в валюте отчетов
+	if sortsByRevenue(keys) && c.needsConversion(manufacturers) {
+		return c.sortConverted(manufacturers, keys)
 	}
-	return a > b
-}
 
-func (c *ManufacturerController) compareString(a, b string, ascending bool) bool {
-	aLower := strings.ToLower(a)
-	bLower := strings.ToLower(b)
-	if ascending {
-		return aLower < bLower
+	// Для базы SQLite порядок задает ORDER BY
+	if db := c.currentDatabase(); db != nil {
+		if sorted, ok := sortInDatabase(db, manufacturers, keys); ok {
+			return sorted, nil
+		}
 	}
-	return aLower > bLower
-}
 
-func (c *ManufacturerController) compareFloat(a, b float64, ascending bool) bool {
-	if ascending {
-		return a < b
-	}
-	return a > b
+	return repository.SortManufacturers(manufacturers, keys, c.fields())
 }
 
 func (c *ManufacturerController) NewDatabase() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.closeDatabase()
 	c.manufacturers = []model.Manufacturer{}
 	c.currentFile = ""
+	c.resetFile("", 0)
 }
 
 func (c *ManufacturerController) AddManufacturer(m *model.Manufacturer) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Генерируем новый ID (либо 1 если список пустой, либо maxID + 1)
-	if len(c.manufacturers) == 0 {
-		m.ID = 1
-	} else {
-		maxID := c.manufacturers[len(c.manufacturers)-1].ID
-		m.ID = maxID + 1
+	address.Join(m)
+	normalizeRecordPhones(m)
+	m.SyncLatest()
+	if err := c.checkRecord(m); err != nil {
+		return err
 	}
 
-	// Добавляем в список
-	c.manufacturers = append(c.manufacturers, *m)
-
-	// Автоматически сохраняем, если файл указан
-	if c.currentFile != "" {
-		if err := c.SaveToFile(c.currentFile); err != nil {
-			// Удаляем добавленный элемент при ошибке сохранения
-			c.manufacturers = c.manufacturers[:len(c.manufacturers)-1]
-			return fmt.Errorf("failed to save after adding: %v", err)
-		}
-	}
+	// Новый ID больше любого когда-либо выданного в этом файле,
+	// поэтому ID удаленных записей не переиспользуются
+	m.ID = c.nextID()
+	c.file().lastID = m.ID
 
-	return nil
+	// Добавляем в список; в файл запись попадет при сохранении
+	return c.execute(&addCommand{m: *m})
 }
 
 func (c *ManufacturerController) SetCurrentFile(path string) {
@@ -998,15 +839,10 @@ func (c *ManufacturerController) GetCurrentFile() string {
 	return c.currentFile
 }
 
+// HasUnsavedChanges сообщает, есть ли в текущем файле записи, добавленные,
+// измененные или удаленные после загрузки или последнего сохранения
 func (c *ManufacturerController) HasUnsavedChanges() bool {
-	if c.currentFile == "" {
-		// Если файл не загружен, считаем что есть несохраненные изменения
-		return len(c.manufacturers) > 0
-	}
-
-	// Для реальной проверки можно сравнить текущие данные с содержимым файла
-	// Здесь упрощенная версия - всегда считаем что есть изменения после редактирования
-	return true
+	return !c.Changes().Empty()
 }
 
 func (c *ManufacturerController) GetManufacturerByRow(row int) (*model.Manufacturer, error) {
@@ -1020,10 +856,26 @@ func (c *ManufacturerController) GetNextID() int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if len(c.manufacturers) == 0 {
-		return 1
+	return c.nextID()
+}
+
+// nextID возвращает ID для новой записи. Учитывает и сохраненный максимум,
+// и текущие данные (их могли подменить через SetManufacturers).
+func (c *ManufacturerController) nextID() int {
+	lastID := c.file().lastID
+	if maxID := repository.MaxID(c.manufacturers); maxID > lastID {
+		lastID = maxID
 	}
-	return c.manufacturers[len(c.manufacturers)-1].ID + 1
+	return lastID + 1
+}
+
+// metadata возвращает метаданные для записи в файл
+func (c *ManufacturerController) metadata() repository.Metadata {
+	meta := repository.Metadata{}
+	meta.SetLastID(c.nextID() - 1)
+	meta.SetSchema(c.file().schema)
+	meta.SetProductTypes(c.file().productTypes)
+	return meta
 }
 
 func (c *ManufacturerController) GetManufacturerByIndex(index int) (*model.Manufacturer, error) {
@@ -1044,27 +896,27 @@ func (c *ManufacturerController) GetManufacturers() []model.Manufacturer {
 	return manufacturers
 }
 
-func (c *ManufacturerController) Search(query string) ([]model.Manufacturer, error) {
+// Search выполняет запрос на языке пакета query (например,
+// `country:Russia revenue>1000`). Ошибка разбора — *query.SyntaxError.
+func (c *ManufacturerController) Search(text string) ([]model.Manufacturer, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	query = strings.ToLower(query)
-	var results []model.Manufacturer
+	q, err := query.Parse(text, c.fields())
+	if err != nil {
+		return nil, err
+	}
 
-	for _, m := range c.manufacturers {
-		if strings.Contains(strings.ToLower(m.Name), query) ||
-			strings.Contains(strings.ToLower(m.Country), query) ||
-			strings.Contains(strings.ToLower(m.Address), query) ||
-			strings.Contains(m.Phone, query) ||
-			strings.Contains(strings.ToLower(m.Email), query) ||
-			strings.Contains(strings.ToLower(m.ProductType), query) ||
-			strings.Contains(fmt.Sprintf("%d", m.FoundedYear), query) ||
-			strings.Contains(fmt.Sprintf("%.2f", m.Revenue), query) {
-			results = append(results, m)
+	// Простой поиск подстроки в базе SQLite выполняется запросом к базе.
+	// Пользовательские поля база не просматривает, поэтому при их наличии
+	// поиск идет по данным в памяти.
+	if plain, ok := q.PlainText(); ok && len(c.file().schema) == 0 {
+		if db := c.currentDatabase(); db != nil {
+			return db.Query(repository.Query{Text: plain})
 		}
 	}
 
-	return results, nil
+	return q.Filter(c.manufacturers), nil
 }
 
 // SetManufacturers устанавливает список производителей
@@ -1081,6 +933,10 @@ func (c *ManufacturerController) GetPrintableData() ([]byte, error) {
 	if len(c.manufacturers) == 0 {
 		return nil, errors.New("база данных пуста")
 	}
+	report, err := c.convertRecords(c.manufacturers)
+	if err != nil {
+		return nil, err
+	}
 
 	// Создаем PDF документ
 	pdf := gofpdf.New("P", "mm", "A4", "")
@@ -1090,7 +946,7 @@ func (c *ManufacturerController) GetPrintableData() ([]byte, error) {
 	pdf.Ln(12)
 
 	// Заголовки таблицы
-	headers := []string{"ID", "Название", "Страна", "Адрес", "Телефон", "Email", "Тип продукции", "Год осн.", "Доход"}
+	headers := []string{"ID", "Название", "Страна", "Адрес", "Телефон", "Email", "Тип продукции", "Год осн.", "Доход, " + c.reportCurrency()}
 	widths := []float64{10, 30, 20, 40, 25, 40, 30, 15, 20}
 
 	// Устанавливаем шрифт для таблицы
@@ -1102,10 +958,12 @@ func (c *ManufacturerController) GetPrintableData() ([]byte, error) {
 
 	// Данные таблицы
 	pdf.SetFont("Arial", "", 8)
-	for _, m := range c.manufacturers {
+	total := 0.0
+	for _, m := range report {
+		total += m.Revenue
 		pdf.CellFormat(widths[0], 6, strconv.Itoa(m.ID), "1", 0, "", false, 0, "")
 		pdf.CellFormat(widths[1], 6, m.Name, "1", 0, "", false, 0, "")
-		pdf.CellFormat(widths[2], 6, m.Country, "1", 0, "", false, 0, "")
+		pdf.CellFormat(widths[2], 6, country.Display(m.Country, "en"), "1", 0, "", false, 0, "")
 		pdf.CellFormat(widths[3], 6, m.Address, "1", 0, "", false, 0, "")
 		pdf.CellFormat(widths[4], 6, m.Phone, "1", 0, "", false, 0, "")
 		pdf.CellFormat(widths[5], 6, m.Email, "1", 0, "", false, 0, "")
@@ -1113,11 +971,24 @@ func (c *ManufacturerController) GetPrintableData() ([]byte, error) {
 		pdf.CellFormat(widths[7], 6, strconv.Itoa(m.FoundedYear), "1", 0, "", false, 0, "")
 		pdf.CellFormat(widths[8], 6, fmt.Sprintf("%.2f", m.Revenue), "1", 0, "R", false, 0, "")
 		pdf.Ln(-1)
+		if extras := extrasLine(&m, c.file().schema); extras != "" {
+			var width float64
+			for _, w := range widths {
+				width += w
+			}
+			pdf.MultiCell(width, 5, extras, "1", "", false)
+		}
 	}
 
+	// Итог выручки
+	pdf.SetFont("Arial", "B", 8)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2]+widths[3]+widths[4]+widths[5]+widths[6]+widths[7], 6, "Итого", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[8], 6, fmt.Sprintf("%.2f", total), "1", 0, "R", false, 0, "")
+	pdf.Ln(-1)
+
 	// Сохраняем PDF в буфер
 	var buf bytes.Buffer
-	err := pdf.Output(&buf)
+	err = pdf.Output(&buf)
 	if err != nil {
 		return nil, err
 	}
@@ -1125,29 +996,3 @@ func (c *ManufacturerController) GetPrintableData() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// GetUniqueProductTypes возвращает список уникальных типов продукции
-func (c *ManufacturerController) GetUniqueProductTypes() []string {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	// Используем map для хранения уникальных значений
-	uniqueTypes := make(map[string]bool)
-	
-	// Собираем все уникальные типы продукции
-	for _, m := range c.manufacturers {
-		if m.ProductType != "" {
-			uniqueTypes[m.ProductType] = true
-		}
-	}
-
-	// Преобразуем map в slice
-	result := make([]string, 0, len(uniqueTypes))
-	for productType := range uniqueTypes {
-		result = append(result, productType)
-	}
-
-	// Сортируем результат для удобства использования
-	sort.Strings(result)
-
-	return result
-}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"cursovay/internal/phone"
+	"reflect"
+)
+
+// NormalizePhones приводит телефоны всех записей текущего файла и их
+// контактов к E.164 одним действием "Normalize phones", которое можно
+// отменить. Возвращает число измененных записей и ID записей с телефонами,
+// которые не удалось разобрать: такие телефоны остаются без изменений.
+func (c *ManufacturerController) NormalizePhones() (int, []int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	after := cloneManufacturers(c.manufacturers)
+	changed := 0
+	var invalid []int
+	for i := range after {
+		m := &after[i]
+		number, ok := phone.Normalize(m.Phone, m.Country)
+		contacts, contactsOK := normalizeContactPhones(m.Contacts, m.Country)
+		if !ok || !contactsOK {
+			invalid = append(invalid, m.ID)
+		}
+		if !ok {
+			number = m.Phone
+		}
+		if number != m.Phone || !reflect.DeepEqual(contacts, m.Contacts) {
+			m.Phone = number
+			m.Contacts = contacts
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, invalid, nil
+	}
+
+	err := c.execute(&replaceCommand{
+		label:  "Normalize phones",
+		before: cloneManufacturers(c.manufacturers),
+		after:  after,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return changed, invalid, nil
+}
+
+// normalizeRecordPhones приводит телефоны записи и ее контактов к E.164
+// при добавлении и изменении из окна, CLI и API. Неразбираемые номера
+// остаются как введены: о них предупреждает правило проверки телефона.
+func normalizeRecordPhones(m *model.Manufacturer) {
+	m.Phone, _ = phone.Normalize(m.Phone, m.Country)
+	m.Contacts, _ = normalizeContactPhones(m.Contacts, m.Country)
+}
+
+// normalizeContactPhones возвращает копию контактов с телефонами в E.164.
+// Неразбираемые телефоны остаются как есть, тогда ok — false.
+func normalizeContactPhones(contacts model.Contacts, countryName string) (model.Contacts, bool) {
+	if len(contacts) == 0 {
+		return contacts, true
+	}
+	result := make(model.Contacts, len(contacts))
+	ok := true
+	for i, contact := range contacts {
+		number, valid := phone.Normalize(contact.Phone, countryName)
+		if valid {
+			contact.Phone = number
+		} else {
+			ok = false
+		}
+		result[i] = contact
+	}
+	return result, ok
+}
+
+// InvalidPhones перечисляет телефоны записи и ее контактов, которые
+// не удалось разобрать по стране записи
+func InvalidPhones(m *model.Manufacturer) []string {
+	var invalid []string
+	if _, ok := phone.Normalize(m.Phone, m.Country); !ok {
+		invalid = append(invalid, m.Phone)
+	}
+	for _, contact := range m.Contacts {
+		if _, ok := phone.Normalize(contact.Phone, m.Country); !ok {
+			invalid = append(invalid, contact.Phone)
+		}
+	}
+	return invalid
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"cursovay/internal/repository"
+	"testing"
+)
+
+func TestAddAndUpdateNormalizePhones(t *testing.T) {
+	c := NewManufacturerController(repository.NewMemoryStore(nil))
+	m := &model.Manufacturer{
+		Name: "Alpha", Country: "RU", FoundedYear: 1990, Phone: "8 (999) 123-45-67",
+		Contacts: model.Contacts{{Name: "Ivan", Phone: "8-999-765-43-21"}},
+	}
+	if err := c.AddManufacturer(m); err != nil {
+		t.Fatalf("AddManufacturer: %v", err)
+	}
+	if m.Phone != "+79991234567" || m.Contacts[0].Phone != "+79997654321" {
+		t.Errorf("после добавления телефоны %q, %q", m.Phone, m.Contacts[0].Phone)
+	}
+
+	// Номер с кодом страны не зависит от страны записи
+	m.Country = "DE"
+	m.Phone = "+7 999 123-45-67"
+	if err := c.UpdateManufacturer(m); err != nil {
+		t.Fatalf("UpdateManufacturer: %v", err)
+	}
+	if m.Phone != "+79991234567" {
+		t.Errorf("после изменения телефон %q, ожидался +79991234567", m.Phone)
+	}
+
+	// Неразбираемый номер сохраняется как введен
+	m.Phone = "доб. 123"
+	if err := c.UpdateManufacturer(m); err != nil {
+		t.Fatalf("UpdateManufacturer: %v", err)
+	}
+	if got, _ := c.GetManufacturerByID(m.ID); got.Phone != "доб. 123" {
+		t.Errorf("неразбираемый телефон изменился: %q", got.Phone)
+	}
+}
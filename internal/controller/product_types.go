@@ -0,0 +1,251 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"cursovay/internal/repository"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ProductTypes возвращает справочник типов продукции текущего файла
+// по алфавиту. В справочнике есть все типы, которые указаны у записей,
+// и типы, добавленные вручную, даже если они еще ни у кого не указаны.
+func (c *ManufacturerController) ProductTypes() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return withProductTypes(c.fileFor(c.currentFile).productTypes, c.manufacturers)
+}
+
+// ProductTypeUsage возвращает справочник типов продукции с числом
+// производителей каждого типа; производитель с несколькими типами
+// учитывается в каждом из них
+func (c *ManufacturerController) ProductTypeUsage() []repository.GroupCount {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for i := range c.manufacturers {
+		for _, t := range c.manufacturers[i].ProductTypes() {
+			counts[strings.ToLower(t)]++
+		}
+	}
+	types := withProductTypes(c.fileFor(c.currentFile).productTypes, c.manufacturers)
+	result := make([]repository.GroupCount, len(types))
+	for i, t := range types {
+		result[i] = repository.GroupCount{Value: t, Count: counts[strings.ToLower(t)]}
+	}
+	return result
+}
+
+// AddProductType добавляет тип продукции в справочник текущего файла
+func (c *ManufacturerController) AddProductType(name string) error {
+	name, err := checkProductType(name)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.file()
+	if model.HasItem(state.productTypes, name) {
+		return fmt.Errorf("тип продукции %s уже есть в справочнике", name)
+	}
+	state.productTypes = sortProductTypes(append(append([]string(nil), state.productTypes...), name))
+	state.settings = true
+	return nil
+}
+
+// RemoveProductType удаляет из справочника тип продукции, который
+// не указан ни у одной записи. Используемый тип сначала нужно
+// объединить с другим.
+func (c *ManufacturerController) RemoveProductType(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.file()
+	state.productTypes = withProductTypes(state.productTypes, c.manufacturers)
+	if !model.HasItem(state.productTypes, name) {
+		return fmt.Errorf("нет типа продукции %s", name)
+	}
+	used := 0
+	for i := range c.manufacturers {
+		if c.manufacturers[i].HasProductType(name) {
+			used++
+		}
+	}
+	if used > 0 {
+		return fmt.Errorf("тип продукции %s указан у %d производителей; объедините его с другим типом", name, used)
+	}
+
+	var kept []string
+	for _, t := range state.productTypes {
+		if !strings.EqualFold(t, name) {
+			kept = append(kept, t)
+		}
+	}
+	state.productTypes = kept
+	state.settings = true
+	return nil
+}
+
+// RenameProductType переименовывает тип продукции во всех записях
+// и в справочнике. Переименование в уже существующий тип объединяет
+// их. Действие можно отменить. Возвращает число измененных записей.
+func (c *ManufacturerController) RenameProductType(oldName, newName string) (int, error) {
+	return c.mergeProductTypes("Rename product type", []string{oldName}, newName)
+}
+
+// MergeProductTypes заменяет типы продукции sources типом target во всех
+// записях и в справочнике. У записи, где были указаны несколько из
+// объединяемых типов, target остается один раз. Действие можно отменить.
+// Возвращает число измененных записей.
+func (c *ManufacturerController) MergeProductTypes(sources []string, target string) (int, error) {
+	return c.mergeProductTypes("Merge product types", sources, target)
+}
+
+func (c *ManufacturerController) mergeProductTypes(label string, sources []string, target string) (int, error) {
+	target, err := checkProductType(target)
+	if err != nil {
+		return 0, err
+	}
+	if len(sources) == 0 {
+		return 0, errors.New("не выбраны типы продукции для объединения")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.file()
+	state.productTypes = withProductTypes(state.productTypes, c.manufacturers)
+	for _, source := range sources {
+		if !model.HasItem(state.productTypes, source) {
+			return 0, fmt.Errorf("нет типа продукции %s", source)
+		}
+	}
+	// Заменяемые типы уходят из справочника, target занимает их место
+	var catalogue []string
+	for _, t := range state.productTypes {
+		if !model.HasItem(sources, t) && !strings.EqualFold(t, target) {
+			catalogue = append(catalogue, t)
+		}
+	}
+	catalogue = sortProductTypes(append(catalogue, target))
+
+	after := cloneManufacturers(c.manufacturers)
+	changed := 0
+	for i := range after {
+		m := &after[i]
+		types := m.ProductTypes()
+		replaced := make([]string, len(types))
+		for j, t := range types {
+			if model.HasItem(sources, t) {
+				t = target
+			}
+			replaced[j] = t
+		}
+		before := m.ProductType
+		m.SetProductTypes(replaced)
+		if m.ProductType != before {
+			changed++
+		}
+	}
+
+	previous := state.productTypes
+	state.productTypes = catalogue
+	if changed == 0 {
+		state.settings = true
+		return 0, nil
+	}
+	err = c.execute(&replaceCommand{
+		label:  label,
+		before: cloneManufacturers(c.manufacturers),
+		after:  after,
+	})
+	if err != nil {
+		state.productTypes = previous
+		return 0, err
+	}
+	state.settings = true
+	return changed, nil
+}
+
+// catalogueStep добавляет в справочник новые типы продукции из after.
+// Если spell, типы у записей, которые добавила или изменила команда (их нет
+// в before или там они другие), приводятся к написанию из справочника
+// ("dye" -> "Dye"); остальные записи остаются как есть. Вызывается под c.mu.
+func (c *ManufacturerController) catalogueStep(before, after []model.Manufacturer, spell bool) {
+	state := c.file()
+	if spell {
+		known := make(map[string]string, len(state.productTypes))
+		for _, t := range state.productTypes {
+			known[strings.ToLower(t)] = t
+		}
+		old := make(map[int]model.Manufacturer, len(before))
+		for _, m := range before {
+			old[m.ID] = m
+		}
+		for i := range after {
+			m := &after[i]
+			if prev, ok := old[m.ID]; ok && reflect.DeepEqual(prev, *m) {
+				continue
+			}
+			types := m.ProductTypes()
+			for j, t := range types {
+				if canonical, ok := known[strings.ToLower(t)]; ok {
+					types[j] = canonical
+				}
+			}
+			m.SetProductTypes(types)
+		}
+	}
+	state.productTypes = withProductTypes(state.productTypes, after)
+}
+
+// typesOf возвращает типы продукции записи для итогов по типам: запись
+// с несколькими типами входит в итог каждого, запись без типа — в итог
+// пустого типа
+func typesOf(m *model.Manufacturer) []string {
+	types := m.ProductTypes()
+	if len(types) == 0 {
+		return []string{""}
+	}
+	return types
+}
+
+// withProductTypes дополняет справочник типами, указанными у записей
+func withProductTypes(catalogue []string, manufacturers []model.Manufacturer) []string {
+	result := append([]string(nil), catalogue...)
+	for i := range manufacturers {
+		for _, t := range manufacturers[i].ProductTypes() {
+			if !model.HasItem(result, t) {
+				result = append(result, t)
+			}
+		}
+	}
+	return sortProductTypes(result)
+}
+
+// sortProductTypes упорядочивает справочник по алфавиту без учета регистра
+func sortProductTypes(types []string) []string {
+	sort.SliceStable(types, func(i, j int) bool {
+		return strings.ToLower(types[i]) < strings.ToLower(types[j])
+	})
+	return types
+}
+
+// checkProductType проверяет название типа продукции: типы записи
+// перечисляются через запятую, поэтому в названии ее быть не может
+func checkProductType(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("тип продукции не может быть пустым")
+	}
+	if strings.ContainsAny(name, ",;") {
+		return "", fmt.Errorf("в названии типа продукции не может быть запятой и точки с запятой: %s", name)
+	}
+	return name, nil
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"testing"
+)
+
+// Написание типа из справочника получают только записи, которые меняет
+// команда; остальные записи файла остаются как есть, а отмена возвращает
+// запись в прежнем написании
+func TestProductTypeSpelling(t *testing.T) {
+	data := testData()
+	data[2].ProductType = "dye"
+	c, _ := newTestController(t, "types.csv", data)
+
+	types := func() map[int]string {
+		result := make(map[int]string)
+		for _, m := range c.GetCurrentData() {
+			result[m.ID] = m.ProductType
+		}
+		return result
+	}
+
+	stored, _ := c.GetManufacturerByID(2)
+	edited := *stored
+	edited.Name = "Beta 2"
+	if err := c.UpdateManufacturer(&edited); err != nil {
+		t.Fatalf("UpdateManufacturer: %v", err)
+	}
+	if got := types()[3]; got != "dye" {
+		t.Errorf("правка другой записи изменила тип записи 3: %q", got)
+	}
+
+	if err := c.AddManufacturer(&model.Manufacturer{Name: "Delta", Country: "US", FoundedYear: 2010, ProductType: "DYE"}); err != nil {
+		t.Fatalf("AddManufacturer: %v", err)
+	}
+	if got := types()[4]; got != "Dye" {
+		t.Errorf("тип новой записи %q, ожидался Dye", got)
+	}
+
+	stored, _ = c.GetManufacturerByID(3)
+	edited = *stored
+	edited.Revenue = 40
+	if err := c.UpdateManufacturer(&edited); err != nil {
+		t.Fatalf("UpdateManufacturer: %v", err)
+	}
+	if got := types()[3]; got != "Dye" {
+		t.Errorf("тип измененной записи %q, ожидался Dye", got)
+	}
+
+	if _, err := c.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if got := types()[3]; got != "dye" {
+		t.Errorf("после отмены тип записи 3 %q, ожидался dye", got)
+	}
+}
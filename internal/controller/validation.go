@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"cursovay/internal/model"
+	"cursovay/internal/validation"
+)
+
+// SetRules задает правила проверки записей; nil возвращает правила
+// по умолчанию
+func (c *ManufacturerController) SetRules(rules *validation.RuleSet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rules == nil {
+		rules = validation.Default()
+	}
+	c.rules = rules
+}
+
+// Rules возвращает действующие правила проверки
+func (c *ManufacturerController) Rules() *validation.RuleSet {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rules
+}
+
+// ValidateManufacturer возвращает все нарушения правил для записи,
+// включая повторы уникальных полей в других записях текущего файла.
+// Ничего не меняет: так окно редактирования показывает нарушения
+// до сохранения.
+func (c *ManufacturerController) ValidateManufacturer(m *model.Manufacturer) validation.Violations {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rules.NewChecker(c.manufacturers, c.fields()).Check(m)
+}
+
+// ValidateAll проверяет все записи текущего файла
+func (c *ManufacturerController) ValidateAll() validation.Violations {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rules.CheckAll(c.manufacturers, c.fields())
+}
+
+// ImportChecker возвращает проверку для импорта в режиме mode.
+// При замене данных уникальность сверяется только внутри файла импорта.
+func (c *ManufacturerController) ImportChecker(mode ImportMode) *validation.Checker {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if mode == ImportReplace {
+		return c.rules.NewChecker(nil, c.fields())
+	}
+	return c.rules.NewChecker(c.manufacturers, c.fields())
+}
+
+// checkRecord возвращает ошибки проверки записи как validation.Violations
+// или nil; предупреждения не мешают сохранению. Вызывается под c.mu.
+func (c *ManufacturerController) checkRecord(m *model.Manufacturer) error {
+	return c.rules.NewChecker(c.manufacturers, c.fields()).Check(m).Err()
+}
@@ -0,0 +1,226 @@
+// Package country — справочник стран ISO 3166-1 с названиями на английском
+// и русском языках. Lookup сопоставляет произвольному тексту из файлов
+// (названия, коды, устаревшие и разговорные варианты, крупные города)
+// код страны, чтобы записи одной страны группировались вместе.
+package country
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Country — страна из справочника ISO 3166-1
+type Country struct {
+	Code   string `json:"code"`   // alpha-2, например RU
+	Alpha3 string `json:"alpha3"` // alpha-3, например RUS
+	NameEN string `json:"name_en"`
+	NameRU string `json:"name_ru"`
+}
+
+// Name возвращает название страны на языке интерфейса lang
+func (c Country) Name(lang string) string {
+	if lang == "ru" {
+		return c.NameRU
+	}
+	return c.NameEN
+}
+
+//go:embed countries.csv
+var countriesCSV string
+
+// aliases.csv: другие названия стран (USA, РФ, Голландия)
+//
+//go:embed aliases.csv
+var aliasesCSV string
+
+// cities.csv: крупные города, которые встречаются в поле страны вместо нее
+//
+//go:embed cities.csv
+var citiesCSV string
+
+var (
+	countries []Country         // по возрастанию кода
+	byCode    map[string]int    // alpha-2 и alpha-3 -> индекс в countries
+	byName    map[string]string // нормализованное название или псевдоним -> alpha-2
+	byCity    map[string]string // нормализованное название города -> alpha-2
+)
+
+func init() {
+	rows := readCSV(countriesCSV)
+	byCode = make(map[string]int, 2*len(rows))
+	byName = make(map[string]string, 3*len(rows))
+	for _, row := range rows {
+		c := Country{Code: row[0], Alpha3: row[1], NameEN: row[2], NameRU: row[3]}
+		byCode[c.Code] = len(countries)
+		byCode[c.Alpha3] = len(countries)
+		byName[normalize(c.NameEN)] = c.Code
+		byName[normalize(c.NameRU)] = c.Code
+		countries = append(countries, c)
+	}
+	byName = readAliases(aliasesCSV, byName)
+	byCity = readAliases(citiesCSV, make(map[string]string))
+}
+
+// readAliases добавляет в names пары "название -> код" из встроенной таблицы
+func readAliases(data string, names map[string]string) map[string]string {
+	for _, row := range readCSV(data) {
+		if _, ok := byCode[row[1]]; !ok {
+			panic(fmt.Sprintf("country: %q ссылается на неизвестный код %s", row[0], row[1]))
+		}
+		names[normalize(row[0])] = row[1]
+	}
+	return names
+}
+
+// readCSV читает встроенную таблицу без строки заголовка
+func readCSV(data string) [][]string {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comment = '#'
+	rows, err := r.ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("country: неверная встроенная таблица: %v", err))
+	}
+	return rows[1:]
+}
+
+// All возвращает все страны по возрастанию кода
+func All() []Country {
+	return append([]Country(nil), countries...)
+}
+
+// ByCode ищет страну по коду alpha-2 или alpha-3 без учета регистра
+func ByCode(code string) (Country, bool) {
+	i, ok := byCode[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Country{}, false
+	}
+	return countries[i], true
+}
+
+// ByName ищет страну по коду, названию на любом из языков или псевдониму.
+// В отличие от Lookup, города не учитываются.
+func ByName(text string) (Country, bool) {
+	if c, ok := ByCode(text); ok {
+		return c, true
+	}
+	if code, ok := byName[normalize(text)]; ok {
+		return ByCode(code)
+	}
+	return Country{}, false
+}
+
+// City возвращает страну для известного крупного города ("г. Уфа" -> RU)
+func City(text string) (Country, bool) {
+	if code, ok := byCity[normalize(text)]; ok {
+		return ByCode(code)
+	}
+	return Country{}, false
+}
+
+// Lookup определяет страну по произвольному тексту: коду, названию
+// на любом из языков, псевдониму или городу. Текст вида
+// "г. Ярославль, Россия" разбирается по частям через запятую.
+func Lookup(text string) (Country, bool) {
+	if c, ok := ByName(text); ok {
+		return c, true
+	}
+	if c, ok := City(text); ok {
+		return c, true
+	}
+	parts := strings.Split(text, ",")
+	if len(parts) == 1 {
+		return Country{}, false
+	}
+	// Страну обычно пишут в конце адреса, поэтому идем с конца
+	for i := len(parts) - 1; i >= 0; i-- {
+		if c, ok := Lookup(parts[i]); ok {
+			return c, true
+		}
+	}
+	return Country{}, false
+}
+
+// Normalize возвращает код страны для текста и true или исходный текст
+// и false, если страну определить не удалось
+func Normalize(text string) (string, bool) {
+	if strings.TrimSpace(text) == "" {
+		return "", true
+	}
+	c, ok := Lookup(text)
+	if !ok {
+		return text, false
+	}
+	return c.Code, true
+}
+
+// Display показывает значение поля страны на языке lang: код —
+// названием страны, остальной текст — как есть
+func Display(value, lang string) string {
+	if c, ok := ByCode(value); ok && len(strings.TrimSpace(value)) == 2 {
+		return c.Name(lang)
+	}
+	return value
+}
+
+// Names возвращает значение поля и, если это известная страна, ее код
+// и названия на всех языках: по ним ищут и фильтруют записи
+func Names(value string) []string {
+	c, ok := Lookup(value)
+	if !ok {
+		return []string{value}
+	}
+	return []string{value, c.Code, c.NameEN, c.NameRU}
+}
+
+// Search возвращает страны, у которых код или название на любом языке
+// содержит query, упорядоченные по названию на языке lang. Страны,
+// название которых начинается с query, идут первыми.
+func Search(query, lang string) []Country {
+	q := normalize(query)
+	var prefix, other []Country
+	for _, c := range countries {
+		names := []string{normalize(c.Name(lang)), normalize(c.NameEN), normalize(c.NameRU)}
+		switch {
+		case q == "" || strings.HasPrefix(names[0], q) || strings.EqualFold(c.Code, q) || strings.EqualFold(c.Alpha3, q):
+			prefix = append(prefix, c)
+		case strings.Contains(names[0], q) || strings.Contains(names[1], q) || strings.Contains(names[2], q):
+			other = append(other, c)
+		}
+	}
+	for _, list := range [][]Country{prefix, other} {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Name(lang) < list[j].Name(lang) })
+	}
+	if q != "" {
+		// Псевдоним или город: его страна — первая в списке
+		if c, ok := Lookup(query); ok {
+			result := []Country{c}
+			for _, list := range [][]Country{prefix, other} {
+				for _, x := range list {
+					if x.Code != c.Code {
+						result = append(result, x)
+					}
+				}
+			}
+			return result
+		}
+	}
+	return append(prefix, other...)
+}
+
+// normalize приводит текст к виду для сравнения: нижний регистр, ё как е,
+// без пунктуации, сокращений "г." и "город" и лишних пробелов
+func normalize(text string) string {
+	text = strings.ToLower(strings.TrimSpace(text))
+	text = strings.NewReplacer("ё", "е", "’", "'", "-", " ", "—", " ").Replace(text)
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	if len(fields) > 1 && (fields[0] == "г" || fields[0] == "город") {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, " ")
+}
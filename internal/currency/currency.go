@@ -0,0 +1,231 @@
+// Package currency пересчитывает суммы между валютами по таблице курсов
+// из локального файла. Таблица хранит курсы на даты, поэтому выручку
+// прошлых лет можно пересчитать по курсу того года без доступа к сети.
+//
+// Формат файла — CSV с заголовком date,currency,rate: курс — стоимость
+// единицы валюты в базовой валюте таблицы. Базовая валюта задается
+// строкой метаданных "# base: RUB" (по умолчанию RUB):
+//
+//	# base: RUB
+//	date,currency,rate
+//	2023-12-29,USD,89.6883
+//	2023-12-29,EUR,99.1919
+package currency
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBase — базовая валюта таблицы без строки "# base:"
+const DefaultBase = "RUB"
+
+// dateLayout — формат дат в файле курсов
+const dateLayout = "2006-01-02"
+
+// Rate — курс валюты на дату: сколько единиц базовой валюты стоит единица
+type Rate struct {
+	Date  time.Time
+	Value float64
+}
+
+// Table — курсы валют к базовой валюте по датам
+type Table struct {
+	base  string
+	rates map[string][]Rate // по возрастанию даты
+}
+
+// NewTable возвращает пустую таблицу: пересчитать можно только базовую валюту
+func NewTable(base string) *Table {
+	if code, ok := Normalize(base); ok {
+		base = code
+	} else {
+		base = DefaultBase
+	}
+	return &Table{base: base, rates: make(map[string][]Rate)}
+}
+
+// Load читает таблицу курсов из файла
+func Load(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return t, nil
+}
+
+// Parse разбирает таблицу курсов в формате файла курсов
+func Parse(data []byte) (*Table, error) {
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	t := NewTable(DefaultBase)
+
+	var body bytes.Buffer
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#") {
+			body.WriteString(line + "\n")
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(trimmed, "#")), ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "base") {
+			code, ok := Normalize(value)
+			if !ok {
+				return nil, fmt.Errorf("неверная базовая валюта %q", strings.TrimSpace(value))
+			}
+			t.base = code
+		}
+	}
+
+	r := csv.NewReader(&body)
+	r.FieldsPerRecord = 3
+	r.TrimLeadingSpace = true
+	line := 0
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("строка %d: %v", parseErr.Line, parseErr.Err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		line++
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: неверная дата %q", line, record[0])
+		}
+		code, ok := Normalize(record[1])
+		if !ok {
+			return nil, fmt.Errorf("строка %d: неверный код валюты %q", line, record[1])
+		}
+		value, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(record[2]), ",", ".", 1), 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("строка %d: неверный курс %q", line, record[2])
+		}
+		if code != t.base {
+			t.rates[code] = append(t.rates[code], Rate{Date: date, Value: value})
+		}
+	}
+
+	for code, rates := range t.rates {
+		sort.SliceStable(rates, func(i, j int) bool { return rates[i].Date.Before(rates[j].Date) })
+		t.rates[code] = rates
+	}
+	return t, nil
+}
+
+// Base возвращает базовую валюту таблицы
+func (t *Table) Base() string {
+	return t.base
+}
+
+// Codes перечисляет валюты таблицы: базовую и все, для которых есть курсы
+func (t *Table) Codes() []string {
+	codes := []string{t.base}
+	for code := range t.rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes[1:])
+	return codes
+}
+
+// Rate возвращает курс валюты к базовой на дату: последний известный
+// курс не позже date, а для дат раньше таблицы — самый ранний курс
+func (t *Table) Rate(code string, date time.Time) (float64, error) {
+	code, ok := Normalize(code)
+	if !ok {
+		return 0, fmt.Errorf("неверный код валюты %q", code)
+	}
+	if code == t.base {
+		return 1, nil
+	}
+	rates := t.rates[code]
+	if len(rates) == 0 {
+		return 0, fmt.Errorf("нет курса %s", code)
+	}
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].Date.After(date) })
+	if i == 0 {
+		return rates[0].Value, nil
+	}
+	return rates[i-1].Value, nil
+}
+
+// Convert пересчитывает сумму из валюты from в валюту to по курсам на дату.
+// Пустая валюта — базовая валюта таблицы.
+func (t *Table) Convert(amount float64, from, to string, date time.Time) (float64, error) {
+	if from == "" {
+		from = t.base
+	}
+	if to == "" {
+		to = t.base
+	}
+	if strings.EqualFold(from, to) || amount == 0 {
+		return amount, nil
+	}
+	fromRate, err := t.Rate(from, date)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := t.Rate(to, date)
+	if err != nil {
+		return 0, err
+	}
+	return amount * fromRate / toRate, nil
+}
+
+// symbols — обозначения валют, которые встречаются в файлах поставщиков
+var symbols = map[string]string{
+	"₽": "RUB", "РУБ": "RUB", "РУБ.": "RUB", "Р.": "RUB", "RUR": "RUB",
+	"$": "USD", "US$": "USD",
+	"€": "EUR", "ЕВРО": "EUR",
+}
+
+// Normalize приводит код или обозначение валюты к коду ISO 4217
+// из трех латинских букв: "usd" -> USD, "₽" -> RUB, "€" -> EUR
+func Normalize(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if iso, ok := symbols[code]; ok {
+		return iso, true
+	}
+	if len(code) != 3 {
+		return code, false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return code, false
+		}
+	}
+	return code, true
+}
+
+// YearEnd — дата, по курсу на которую пересчитывается выручка за год
+func YearEnd(year int) time.Time {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+}
+
+// Format записывает сумму с кодом валюты: "1234.50 USD"
+func Format(amount float64, code string) string {
+	if code == "" {
+		return strconv.FormatFloat(amount, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(amount, 'f', 2, 64) + " " + code
+}
@@ -0,0 +1,132 @@
+package currency
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+const testRates = "\xEF\xBB\xBF# base: RUB\n" +
+	"date,currency,rate\n" +
+	"2023-12-29,USD,89.6883\n" +
+	"2022-12-30, usd, \"70,3375\"\n" +
+	"2023-12-29,€,99.1919\n" +
+	"2023-12-29,RUB,1\n"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantBase  string
+		wantCodes []string
+		wantErr   bool
+	}{
+		{"пустой файл", "", "RUB", []string{"RUB"}, false},
+		{"курсы с BOM и обозначениями", testRates, "RUB", []string{"RUB", "EUR", "USD"}, false},
+		{"другая базовая валюта", "# base: usd\n2023-12-29,RUB,0.0111\n", "USD", []string{"USD", "RUB"}, false},
+		{"комментарий без base", "# источник: ЦБ\ndate,currency,rate\n", "RUB", []string{"RUB"}, false},
+		{"неверная базовая валюта", "# base: рубли\n", "", nil, true},
+		{"неверная дата", "29.12.2023,USD,89\n", "", nil, true},
+		{"неверный код", "2023-12-29,US,89\n", "", nil, true},
+		{"неположительный курс", "2023-12-29,USD,0\n", "", nil, true},
+		{"лишний столбец", "2023-12-29,USD,89,1\n", "", nil, true},
+	}
+	for _, tt := range tests {
+		table, err := Parse([]byte(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ошибка %v, ожидалась %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if table.Base() != tt.wantBase || !reflect.DeepEqual(table.Codes(), tt.wantCodes) {
+			t.Errorf("%s: база %s, валюты %v; ожидалось %s, %v", tt.name, table.Base(), table.Codes(), tt.wantBase, tt.wantCodes)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	table, err := Parse([]byte(testRates))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	tests := []struct {
+		code    string
+		date    string
+		want    float64
+		wantErr bool
+	}{
+		{"RUB", "2023-12-29", 1, false},
+		{"₽", "2000-01-01", 1, false},
+		{"USD", "2023-12-29", 89.6883, false},
+		{"usd", "2023-06-30", 70.3375, false},
+		{"USD", "2024-05-01", 89.6883, false},
+		{"USD", "2020-01-01", 70.3375, false}, // раньше таблицы — самый ранний курс
+		{"EUR", "2023-12-31", 99.1919, false},
+		{"CNY", "2023-12-29", 0, true},
+		{"доллар", "2023-12-29", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := table.Rate(tt.code, date(tt.date))
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Rate(%q, %s) = %v, %v; ожидалось %v, ошибка %v", tt.code, tt.date, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	table, err := Parse([]byte(testRates))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	day := date("2023-12-29")
+	tests := []struct {
+		amount   float64
+		from, to string
+		want     float64
+		wantErr  bool
+	}{
+		{100, "USD", "", 8968.83, false},
+		{8968.83, "", "USD", 100, false},
+		{100, "EUR", "USD", 100 * 99.1919 / 89.6883, false},
+		{100, "CNY", "CNY", 100, false},
+		{0, "CNY", "USD", 0, false},
+		{100, "CNY", "USD", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := table.Convert(tt.amount, tt.from, tt.to, day)
+		if (err != nil) != tt.wantErr || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Convert(%v, %q, %q) = %v, %v; ожидалось %v, ошибка %v", tt.amount, tt.from, tt.to, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		code   string
+		want   string
+		wantOK bool
+	}{
+		{"usd", "USD", true},
+		{" ₽ ", "RUB", true},
+		{"руб.", "RUB", true},
+		{"€", "EUR", true},
+		{"US", "US", false},
+		{"US1", "US1", false},
+	}
+	for _, tt := range tests {
+		if got, ok := Normalize(tt.code); got != tt.want || ok != tt.wantOK {
+			t.Errorf("Normalize(%q) = %q, %v; ожидалось %q, %v", tt.code, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Package dedup находит вероятные дубликаты производителей и сливает
+// их в одну запись. Кандидаты — записи с похожими названиями или
+// с одинаковыми email, телефоном или сайтом после нормализации.
+package dedup
+
+import (
+	"cursovay/internal/model"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Options задает, какие записи считать дубликатами
+type Options struct {
+	NameSimilarity float64 // порог похожести названий от 0 до 1; 0 — не сравнивать
+	MatchEmail     bool
+	MatchPhone     bool
+	MatchWebsite   bool
+}
+
+// DefaultOptions — настройки по умолчанию
+func DefaultOptions() Options {
+	return Options{NameSimilarity: 0.9, MatchEmail: true, MatchPhone: true, MatchWebsite: true}
+}
+
+// Group — записи, которые, вероятно, описывают одного производителя
+type Group struct {
+	Members []model.Manufacturer // по возрастанию ID
+	Reasons []string             // почему записи попали в группу, например "email"
+}
+
+// Find разбивает записи на группы вероятных дубликатов. Записи
+// объединяются транзитивно: если A похожа на B, а B на C, все три
+// окажутся в одной группе. Группы упорядочены по наименьшему ID.
+func Find(data []model.Manufacturer, opts Options) []Group {
+	parent := make([]int, len(data))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	reasons := make(map[int]map[string]bool)
+	union := func(i, j int, reason string) {
+		ri, rj := find(i), find(j)
+		if ri != rj {
+			parent[rj] = ri
+			for r := range reasons[rj] {
+				addReason(reasons, ri, r)
+			}
+		}
+		addReason(reasons, ri, reason)
+	}
+
+	// Равенство нормализованных значений — через индекс, без перебора пар
+	exact := []struct {
+		reason  string
+		enabled bool
+		key     func(*model.Manufacturer) string
+	}{
+		{"email", opts.MatchEmail, func(m *model.Manufacturer) string { return NormalizeEmail(m.Email) }},
+		{"phone", opts.MatchPhone, func(m *model.Manufacturer) string { return NormalizePhone(m.Phone) }},
+		{"website", opts.MatchWebsite, func(m *model.Manufacturer) string { return NormalizeWebsite(m.Website) }},
+	}
+	for _, e := range exact {
+		if !e.enabled {
+			continue
+		}
+		first := make(map[string]int)
+		for i := range data {
+			k := e.key(&data[i])
+			if k == "" {
+				continue
+			}
+			if j, ok := first[k]; ok {
+				union(j, i, e.reason)
+			} else {
+				first[k] = i
+			}
+		}
+	}
+
+	if opts.NameSimilarity > 0 {
+		names := make([][]rune, len(data))
+		for i := range data {
+			names[i] = []rune(NormalizeName(data[i].Name))
+		}
+		for i := range data {
+			for j := i + 1; j < len(data); j++ {
+				if len(names[i]) == 0 || len(names[j]) == 0 {
+					continue
+				}
+				if Similarity(names[i], names[j]) >= opts.NameSimilarity {
+					union(i, j, "name")
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]model.Manufacturer)
+	for i := range data {
+		root := find(i)
+		members[root] = append(members[root], data[i])
+	}
+	var groups []Group
+	for root, ms := range members {
+		if len(ms) < 2 {
+			continue
+		}
+		sort.Slice(ms, func(a, b int) bool { return ms[a].ID < ms[b].ID })
+		g := Group{Members: ms}
+		for r := range reasons[root] {
+			g.Reasons = append(g.Reasons, r)
+		}
+		sort.Strings(g.Reasons)
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(a, b int) bool {
+		return groups[a].Members[0].ID < groups[b].Members[0].ID
+	})
+	return groups
+}
+
+// moneyFields — выручка, ее история и валюта. Они имеют смысл только
+// вместе: сумма без своей валюты означала бы другую выручку.
+var moneyFields = map[string]bool{"revenue": true, "history": true, "currency": true}
+
+// Merge сливает записи группы в одну с наименьшим ID. Значения берутся
+// из записи с наименьшим ID, пустые поля заполняются из остальных
+// записей в порядке возрастания ID. Выручка, история и валюта берутся
+// вместе из первой записи, где есть выручка или история. Контакты и теги
+// всех записей объединяются.
+// fields — поля файла (model.Schema.Fields), которые заполняются.
+func Merge(members []model.Manufacturer, fields []model.Field) model.Manufacturer {
+	sorted := append([]model.Manufacturer(nil), members...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].ID < sorted[b].ID })
+
+	merged := sorted[0]
+	for _, f := range fields {
+		if f.List {
+			var items []string
+			for i := range sorted {
+				items = append(items, model.SplitList(f.Get(&sorted[i]))...)
+			}
+			f.Set(&merged, model.JoinList(items))
+			continue
+		}
+		if f.Key == "id" || f.Key == "contacts" || moneyFields[f.Key] || !isEmpty(f, &merged) {
+			continue
+		}
+		for i := 1; i < len(sorted); i++ {
+			if !isEmpty(f, &sorted[i]) {
+				f.Set(&merged, f.Get(&sorted[i]))
+				break
+			}
+		}
+	}
+	for i := range sorted {
+		if hasMoney(&sorted[i]) {
+			merged.Revenue = sorted[i].Revenue
+			merged.History = append(model.History(nil), sorted[i].History...)
+			merged.Currency = sorted[i].Currency
+			break
+		}
+	}
+	merged.Contacts = nil
+	for _, m := range sorted {
+		for _, c := range m.Contacts {
+			if !hasContact(merged.Contacts, c) {
+				merged.Contacts = append(merged.Contacts, c)
+			}
+		}
+	}
+	return merged
+}
+
+// hasMoney сообщает, указана ли у записи выручка или ее история
+func hasMoney(m *model.Manufacturer) bool {
+	return m.Revenue != 0 || len(m.History) > 0
+}
+
+// hasContact сообщает, есть ли среди контактов тот же человек:
+// с тем же именем и email или полностью совпадающий
+func hasContact(contacts model.Contacts, c model.Contact) bool {
+	for _, existing := range contacts {
+		if existing == c || (strings.EqualFold(existing.Name, c.Name) && strings.EqualFold(existing.Email, c.Email)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmpty(f model.Field, m *model.Manufacturer) bool {
+	v := strings.TrimSpace(f.Get(m))
+	if f.Numeric {
+		return strings.Trim(v, "0.") == ""
+	}
+	return v == ""
+}
+
+func addReason(reasons map[int]map[string]bool, root int, reason string) {
+	if reasons[root] == nil {
+		reasons[root] = make(map[string]bool)
+	}
+	reasons[root][reason] = true
+}
+
+// legalForms — организационно-правовые формы, которые не различают
+// производителей: "Concrete LLC" и "Concrete" — одно название
+var legalForms = map[string]bool{
+	"llc": true, "ltd": true, "inc": true, "corp": true, "co": true, "company": true,
+	"gmbh": true, "ag": true, "sa": true, "srl": true, "spa": true, "bv": true, "plc": true,
+	"ооо": true, "оао": true, "зао": true, "пао": true, "ао": true, "ип": true, "нпо": true,
+}
+
+// NormalizeName приводит название к сравнимому виду: нижний регистр,
+// без кавычек, знаков препинания и организационно-правовой формы
+func NormalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	kept := words[:0]
+	for _, w := range words {
+		if !legalForms[w] {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// NormalizeEmail убирает регистр и пробелы
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone оставляет только цифры. Российские номера с 8
+// приводятся к коду страны 7, чтобы "8 (999) 123-45-67"
+// совпадал с "+7 999 123 45 67".
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	d := digits.String()
+	if len(d) == 11 && d[0] == '8' {
+		d = "7" + d[1:]
+	}
+	if len(d) < 5 {
+		return "" // слишком короткий номер ни о чем не говорит
+	}
+	return d
+}
+
+// NormalizeWebsite убирает схему, www и завершающий слеш
+func NormalizeWebsite(site string) string {
+	site = strings.ToLower(strings.TrimSpace(site))
+	site = strings.TrimPrefix(site, "https://")
+	site = strings.TrimPrefix(site, "http://")
+	site = strings.TrimPrefix(site, "www.")
+	return strings.TrimRight(site, "/")
+}
+
+// Similarity возвращает похожесть строк от 0 до 1 по расстоянию
+// Левенштейна: 1 — строки совпадают
+func Similarity(a, b []rune) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
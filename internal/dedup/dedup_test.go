@@ -0,0 +1,144 @@
+package dedup
+
+import (
+	"cursovay/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		normalize func(string) string
+		in, want  string
+	}{
+		{"правовая форма", NormalizeName, `ООО "Бетон-Строй"`, "бетон строй"},
+		{"форма латиницей", NormalizeName, "Concrete Co., Ltd.", "concrete"},
+		{"email", NormalizeEmail, " Info@Example.COM ", "info@example.com"},
+		{"телефон с 8", NormalizePhone, "8 (999) 123-45-67", "79991234567"},
+		{"телефон с +7", NormalizePhone, "+7 999 123 45 67", "79991234567"},
+		{"короткий номер", NormalizePhone, "12-34", ""},
+		{"сайт", NormalizeWebsite, "https://www.Example.com/", "example.com"},
+	}
+	for _, tt := range tests {
+		if got := tt.normalize(tt.in); got != tt.want {
+			t.Errorf("%s: %q -> %q, ожидалось %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"abcd", "abcd", 1},
+		{"abcd", "abce", 0.75},
+		{"abcd", "", 0},
+	}
+	for _, tt := range tests {
+		if got := Similarity([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %v, ожидалось %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	data := []model.Manufacturer{
+		{ID: 1, Name: "Alpha LLC", Email: "info@alpha.com"},
+		{ID: 2, Name: "Beta", Email: "INFO@alpha.com", Phone: "8 999 123-45-67"},
+		{ID: 3, Name: "Gamma", Phone: "+7 (999) 1234567"},
+		{ID: 4, Name: "Alpha", Website: "delta.com"},
+		{ID: 5, Name: "Delta", Website: "https://www.delta.com/"},
+		{ID: 6, Name: "Omega"},
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want [][]int
+	}{
+		{"все признаки, группы транзитивны", DefaultOptions(), [][]int{{1, 2, 3, 4, 5}}},
+		{"только email", Options{MatchEmail: true}, [][]int{{1, 2}}},
+		{"только названия", Options{NameSimilarity: 0.9}, [][]int{{1, 4}}},
+		{"email и сайт", Options{MatchEmail: true, MatchWebsite: true}, [][]int{{1, 2}, {4, 5}}},
+		{"ничего не сравнивать", Options{}, nil},
+	}
+	for _, tt := range tests {
+		var got [][]int
+		for _, g := range Find(data, tt.opts) {
+			var ids []int
+			for _, m := range g.Members {
+				ids = append(ids, m.ID)
+			}
+			got = append(got, ids)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: группы %v, ожидались %v", tt.name, got, tt.want)
+		}
+	}
+
+	groups := Find(data[:2], DefaultOptions())
+	if len(groups) != 1 || !reflect.DeepEqual(groups[0].Reasons, []string{"email"}) {
+		t.Errorf("причины группы: %+v", groups)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	fields := model.Schema(nil).Fields()
+	history := model.History{{Year: 2020, Revenue: 90}, {Year: 2021, Revenue: 100}}
+
+	tests := []struct {
+		name    string
+		members []model.Manufacturer
+		want    func(m model.Manufacturer) bool
+	}{
+		{
+			"пустые поля заполняются из следующих записей",
+			[]model.Manufacturer{
+				{ID: 3, Name: "Alpha Ltd", Phone: "+7 999", Email: "b@alpha.com"},
+				{ID: 1, Name: "Alpha", Email: "a@alpha.com"},
+			},
+			func(m model.Manufacturer) bool {
+				return m.ID == 1 && m.Name == "Alpha" && m.Email == "a@alpha.com" && m.Phone == "+7 999"
+			},
+		},
+		{
+			"валюта не приписывается чужой выручке",
+			[]model.Manufacturer{
+				{ID: 1, Name: "Alpha", Revenue: 100},
+				{ID: 2, Name: "Alpha", Revenue: 5, Currency: "USD"},
+			},
+			func(m model.Manufacturer) bool {
+				return m.Revenue == 100 && m.Currency == ""
+			},
+		},
+		{
+			"выручка, история и валюта берутся из одной записи",
+			[]model.Manufacturer{
+				{ID: 1, Name: "Alpha", Currency: "EUR"},
+				{ID: 2, Name: "Alpha", Revenue: 100, History: history, Currency: "USD"},
+				{ID: 3, Name: "Alpha", Revenue: 7, Currency: "RUB"},
+			},
+			func(m model.Manufacturer) bool {
+				return m.Revenue == 100 && m.Currency == "USD" && reflect.DeepEqual(m.History, history)
+			},
+		},
+		{
+			"теги и контакты объединяются",
+			[]model.Manufacturer{
+				{ID: 1, Name: "Alpha", Tags: []string{"paint"}, Contacts: model.Contacts{{Name: "Ivan", Email: "ivan@alpha.com"}}},
+				{ID: 2, Name: "Alpha", Tags: []string{"Paint", "glue"}, Contacts: model.Contacts{{Name: "ivan", Email: "IVAN@alpha.com", Phone: "1"}, {Name: "Olga"}}},
+			},
+			func(m model.Manufacturer) bool {
+				return reflect.DeepEqual(m.Tags, []string{"paint", "glue"}) && len(m.Contacts) == 2
+			},
+		},
+	}
+	for _, tt := range tests {
+		if got := Merge(tt.members, fields); !tt.want(got) {
+			t.Errorf("%s: %+v", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package diff сравнивает два списка производителей по ID
+// и описывает различия с точностью до поля.
+package diff
+
+import (
+	"bufio"
+	"cursovay/internal/model"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Kind — вид изменения записи
+type Kind int
+
+const (
+	Added Kind = iota
+	Removed
+	Modified
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return "modified"
+	}
+}
+
+// MarshalText позволяет выводить вид изменения в JSON словом
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// FieldChange — изменение одного поля
+type FieldChange struct {
+	Field string `json:"field"` // ключ поля model.Field
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// RecordChange — изменение одной записи. Для Added заполнено только New,
+// для Removed — только Old.
+type RecordChange struct {
+	Kind   Kind                `json:"kind"`
+	ID     int                 `json:"id"`
+	Old    *model.Manufacturer `json:"old,omitempty"`
+	New    *model.Manufacturer `json:"new,omitempty"`
+	Fields []FieldChange       `json:"fields,omitempty"` // для Modified — только измененные поля
+}
+
+// Diff — все изменения, упорядоченные по ID
+type Diff struct {
+	Changes []RecordChange
+
+	fields []model.Field // поля, по которым сравнивались записи
+}
+
+// Compare сравнивает old и new по ID записей по полям файла fields
+// (model.Schema.Fields). Изменение одного только порядка записей
+// различием не считается.
+func Compare(old, new []model.Manufacturer, fields []model.Field) *Diff {
+	before := make(map[int]*model.Manufacturer, len(old))
+	for i := range old {
+		before[old[i].ID] = &old[i]
+	}
+
+	d := &Diff{fields: fields}
+	seen := make(map[int]bool, len(new))
+	for i := range new {
+		m := &new[i]
+		seen[m.ID] = true
+		prev, ok := before[m.ID]
+		if !ok {
+			d.Changes = append(d.Changes, RecordChange{Kind: Added, ID: m.ID, New: m})
+			continue
+		}
+		if changed := compareFields(prev, m, fields); len(changed) > 0 {
+			d.Changes = append(d.Changes, RecordChange{Kind: Modified, ID: m.ID, Old: prev, New: m, Fields: changed})
+		}
+	}
+	for i := range old {
+		if !seen[old[i].ID] {
+			d.Changes = append(d.Changes, RecordChange{Kind: Removed, ID: old[i].ID, Old: &old[i]})
+		}
+	}
+
+	sort.SliceStable(d.Changes, func(i, j int) bool {
+		return d.Changes[i].ID < d.Changes[j].ID
+	})
+	return d
+}
+
+// Empty сообщает, что списки совпадают
+func (d *Diff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// Count возвращает число записей с изменением данного вида
+func (d *Diff) Count(kind Kind) int {
+	n := 0
+	for _, c := range d.Changes {
+		if c.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteUnified записывает различия в виде, похожем на unified diff:
+// для каждой записи блок "@@ ID n @@", строки полей с "-" и "+"
+func (d *Diff) WriteUnified(w io.Writer, oldName, newName string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "--- %s\n+++ %s\n", oldName, newName)
+	for _, c := range d.Changes {
+		fmt.Fprintf(bw, "@@ ID %d %s @@\n", c.ID, c.Kind)
+		switch c.Kind {
+		case Added:
+			writeRecord(bw, "+", c.New, d.fields)
+		case Removed:
+			writeRecord(bw, "-", c.Old, d.fields)
+		default:
+			for _, f := range c.Fields {
+				header := fieldHeader(f.Field, d.fields)
+				fmt.Fprintf(bw, "-%s: %s\n+%s: %s\n", header, f.Old, header, f.New)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func writeRecord(w io.Writer, prefix string, m *model.Manufacturer, fields []model.Field) {
+	for _, f := range fields {
+		if value := f.Get(m); value != "" {
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, f.Header, value)
+		}
+	}
+}
+
+func compareFields(old, new *model.Manufacturer, fields []model.Field) []FieldChange {
+	var changes []FieldChange
+	for _, f := range fields {
+		if a, b := f.Get(old), f.Get(new); a != b {
+			changes = append(changes, FieldChange{Field: f.Key, Old: a, New: b})
+		}
+	}
+	return changes
+}
+
+func fieldHeader(key string, fields []model.Field) string {
+	if f, ok := model.LookupField(fields, key); ok {
+		return f.Header
+	}
+	return key
+}
@@ -0,0 +1,293 @@
+// Package importer импортирует CSV-файлы и книги Excel поставщиков:
+// определяет разделитель и кодировку, сопоставляет столбцы полям
+// производителя и составляет отчет об ошибках по строкам вместо
+// молчаливого пропуска.
+package importer
+
+import (
+	"bytes"
+	"cursovay/internal/model"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Поддерживаемые кодировки
+const (
+	EncodingUTF8        = "UTF-8"
+	EncodingWindows1251 = "Windows-1251"
+	EncodingKOI8R       = "KOI8-R"
+)
+
+// Encodings перечисляет кодировки для выбора в мастере импорта
+var Encodings = []string{EncodingUTF8, EncodingWindows1251, EncodingKOI8R}
+
+// Delimiters перечисляет разделители, которые распознает Sniff
+var Delimiters = []rune{',', ';', '\t', '|'}
+
+// Format описывает, как читать файл
+type Format struct {
+	Delimiter rune
+	Encoding  string
+	HasHeader bool
+}
+
+// Row — строка исходного файла
+type Row struct {
+	Line   int      // номер строки в файле, с 1
+	Values []string // значения столбцов
+	Err    error    // ошибка разбора строки (например, незакрытая кавычка)
+}
+
+// Table — содержимое файла после разбора
+type Table struct {
+	Header  []string // заголовки столбцов; без заголовка — "Столбец N"
+	Rows    []Row
+	Skipped []Row // строки метаданных "# ключ: значение" перед заголовком
+}
+
+// Sniff определяет кодировку, разделитель и наличие заголовка
+func Sniff(data []byte) Format {
+	f := Format{Encoding: sniffEncoding(data), Delimiter: ','}
+
+	text, err := Decode(data, f.Encoding)
+	if err != nil {
+		return f
+	}
+	lines := sampleLines(text, 10)
+	f.Delimiter = sniffDelimiter(lines)
+
+	if len(lines) > 0 {
+		r := csv.NewReader(strings.NewReader(lines[0]))
+		r.Comma = f.Delimiter
+		r.LazyQuotes = true
+		if header, err := r.Read(); err == nil {
+			f.HasHeader = looksLikeHeader(header)
+		}
+	}
+	return f
+}
+
+// Decode переводит данные из кодировки файла в UTF-8
+func Decode(data []byte, encoding string) (string, error) {
+	switch encoding {
+	case EncodingUTF8, "":
+		data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+		if !utf8.Valid(data) {
+			return "", errors.New("файл не в кодировке UTF-8")
+		}
+		return string(data), nil
+	case EncodingWindows1251:
+		decoded, err := charmap.Windows1251.NewDecoder().Bytes(data)
+		return string(decoded), err
+	case EncodingKOI8R:
+		decoded, err := charmap.KOI8R.NewDecoder().Bytes(data)
+		return string(decoded), err
+	default:
+		return "", fmt.Errorf("неизвестная кодировка: %s", encoding)
+	}
+}
+
+// ReadTable разбирает файл в заданном формате. Строки с ошибками
+// разбора не пропускаются, а попадают в таблицу с заполненным Err.
+// Пропускаются только пустые строки и строки метаданных перед
+// заголовком; пропущенные метаданные перечислены в Skipped.
+// Незакрытая кавычка портит только свою строку: если запись из
+// нескольких строк не разбирается, чтение продолжается со следующей.
+func ReadTable(data []byte, f Format) (*Table, error) {
+	text, err := Decode(data, f.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+
+	t := &Table{}
+	columns := 0
+	preamble := true // строки до заголовка или первой строки данных
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			i++
+			continue
+		}
+		// Метаданные "# key: value" из наших файлов бывают только в начале;
+		// дальше строка с # — обычные данные ("#1 Paints Ltd")
+		if preamble && isMetadataLine(line) {
+			t.Skipped = append(t.Skipped, Row{Line: i + 1, Values: []string{line}})
+			i++
+			continue
+		}
+		preamble = false
+
+		end := i
+		record := line
+		for strings.Count(record, `"`)%2 == 1 && end+1 < len(lines) {
+			end++
+			record += "\n" + lines[end]
+		}
+
+		values, err := parseRecord(record, f.Delimiter)
+		if end > i && (err != nil || (len(t.Header) > 0 && len(values) != len(t.Header))) {
+			values, err = parseRecord(line, f.Delimiter)
+			end = i
+		}
+		if err != nil {
+			t.Rows = append(t.Rows, Row{Line: i + 1, Err: err})
+			i = end + 1
+			continue
+		}
+
+		if f.HasHeader && t.Header == nil {
+			t.Header = trimAll(values)
+		} else {
+			t.Rows = append(t.Rows, Row{Line: i + 1, Values: trimAll(values)})
+			if len(values) > columns {
+				columns = len(values)
+			}
+		}
+		i = end + 1
+	}
+
+	if t.Header == nil {
+		for i := 0; i < columns; i++ {
+			t.Header = append(t.Header, fmt.Sprintf("Столбец %d", i+1))
+		}
+	}
+	return t, nil
+}
+
+// parseRecord разбирает одну запись CSV
+func parseRecord(record string, delimiter rune) ([]string, error) {
+	r := csv.NewReader(strings.NewReader(record))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	values, err := r.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, parseErr.Err
+	}
+	if err == io.EOF {
+		return nil, errors.New("пустая запись")
+	}
+	return values, err
+}
+
+// sniffEncoding отличает UTF-8 от однобайтовых кириллических кодировок.
+// В русском тексте больше строчных букв: в Windows-1251 они занимают
+// байты 0xE0–0xFF, а в KOI8-R — 0xC0–0xDF.
+func sniffEncoding(data []byte) string {
+	if bytes.HasPrefix(data, []byte("\xEF\xBB\xBF")) || utf8.Valid(data) {
+		return EncodingUTF8
+	}
+
+	var upperHalf, lowerHalf int
+	for _, b := range data {
+		switch {
+		case b >= 0xE0:
+			upperHalf++
+		case b >= 0xC0:
+			lowerHalf++
+		}
+	}
+	if lowerHalf > upperHalf {
+		return EncodingKOI8R
+	}
+	return EncodingWindows1251
+}
+
+// sniffDelimiter выбирает разделитель, который встречается одинаковое
+// ненулевое число раз в наибольшем числе строк
+func sniffDelimiter(lines []string) rune {
+	best, bestScore, bestCount := ',', 0, 0
+	for _, d := range Delimiters {
+		score, count := 0, 0
+		for i, line := range lines {
+			n := countOutsideQuotes(line, d)
+			if i == 0 {
+				count = n
+			}
+			if n > 0 && n == count {
+				score++
+			}
+		}
+		if score > bestScore || (score == bestScore && count > bestCount) {
+			best, bestScore, bestCount = d, score, count
+		}
+	}
+	return best
+}
+
+func countOutsideQuotes(line string, d rune) int {
+	n, quoted := 0, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == d && !quoted:
+			n++
+		}
+	}
+	return n
+}
+
+// sampleLines возвращает первые непустые строки, кроме строк метаданных
+// в начале файла
+func sampleLines(text string, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || (len(lines) == 0 && isMetadataLine(line)) {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == n {
+			break
+		}
+	}
+	return lines
+}
+
+// isMetadataLine распознает строку метаданных "# key: value": ключ —
+// одно слово без разделителей столбцов, поэтому заголовок "#ID,Name"
+// метаданными не считается
+func isMetadataLine(line string) bool {
+	if !strings.HasPrefix(line, "#") {
+		return false
+	}
+	key, _, ok := strings.Cut(strings.TrimPrefix(line, "#"), ":")
+	key = strings.TrimSpace(key)
+	return ok && key != "" && !strings.ContainsAny(key, " \t,;|\"")
+}
+
+// looksLikeHeader считает строку заголовком, если в ней есть известное
+// название поля или все значения — непустой текст, а не числа
+func looksLikeHeader(values []string) bool {
+	text := true
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if _, ok := model.FieldByName(v); ok && v != "" {
+			return true
+		}
+		if _, err := strconv.ParseFloat(strings.Replace(v, ",", ".", 1), 64); err == nil || v == "" {
+			text = false
+		}
+	}
+	return text
+}
+
+func trimAll(values []string) []string {
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
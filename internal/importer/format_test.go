@@ -0,0 +1,162 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestReadTable(t *testing.T) {
+	csvFormat := Format{Delimiter: ',', Encoding: EncodingUTF8, HasHeader: true}
+	tests := []struct {
+		name        string
+		data        string
+		f           Format
+		wantHeader  []string
+		wantRows    [][]string // nil — строка с ошибкой разбора
+		wantLines   []int
+		wantSkipped int
+	}{
+		{
+			name:       "заголовок и данные",
+			data:       "Name,Country\r\nAlpha, RU\r\n\r\nBeta,DE\r\n",
+			f:          csvFormat,
+			wantHeader: []string{"Name", "Country"},
+			wantRows:   [][]string{{"Alpha", "RU"}, {"Beta", "DE"}},
+			wantLines:  []int{2, 4},
+		},
+		{
+			name:        "метаданные перед заголовком пропускаются",
+			data:        "# lastID: 5\n# schema: []\nName,Country\n#1 Paints,RU\n# note: x,DE\n",
+			f:           csvFormat,
+			wantHeader:  []string{"Name", "Country"},
+			wantRows:    [][]string{{"#1 Paints", "RU"}, {"# note: x", "DE"}},
+			wantLines:   []int{4, 5},
+			wantSkipped: 2,
+		},
+		{
+			name:       "заголовок с # не метаданные",
+			data:       "#ID,Name\n1,Alpha\n",
+			f:          csvFormat,
+			wantHeader: []string{"#ID", "Name"},
+			wantRows:   [][]string{{"1", "Alpha"}},
+			wantLines:  []int{2},
+		},
+		{
+			name:       "без заголовка",
+			data:       "Alpha;RU;1990\nBeta;DE\n",
+			f:          Format{Delimiter: ';', Encoding: EncodingUTF8},
+			wantHeader: []string{"Столбец 1", "Столбец 2", "Столбец 3"},
+			wantRows:   [][]string{{"Alpha", "RU", "1990"}, {"Beta", "DE"}},
+			wantLines:  []int{1, 2},
+		},
+		{
+			name:       "значение в несколько строк",
+			data:       "Name,Address\nAlpha,\"ул. Ленина, 5\nофис 3\"\nBeta,Berlin\n",
+			f:          csvFormat,
+			wantHeader: []string{"Name", "Address"},
+			wantRows:   [][]string{{"Alpha", "ул. Ленина, 5\nофис 3"}, {"Beta", "Berlin"}},
+			wantLines:  []int{2, 4},
+		},
+		{
+			name:       "незакрытая кавычка портит только свою строку",
+			data:       "Name,Address\nAlpha,\"ул. Ленина\nBeta,Berlin\n",
+			f:          csvFormat,
+			wantHeader: []string{"Name", "Address"},
+			wantRows:   [][]string{nil, {"Beta", "Berlin"}},
+			wantLines:  []int{2, 3},
+		},
+	}
+	for _, tt := range tests {
+		table, err := ReadTable([]byte(tt.data), tt.f)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(table.Header, tt.wantHeader) {
+			t.Errorf("%s: заголовок %q, ожидался %q", tt.name, table.Header, tt.wantHeader)
+		}
+		if len(table.Skipped) != tt.wantSkipped {
+			t.Errorf("%s: пропущено %d строк, ожидалось %d", tt.name, len(table.Skipped), tt.wantSkipped)
+		}
+		if len(table.Rows) != len(tt.wantRows) {
+			t.Errorf("%s: %d строк, ожидалось %d", tt.name, len(table.Rows), len(tt.wantRows))
+			continue
+		}
+		for i, row := range table.Rows {
+			if row.Line != tt.wantLines[i] {
+				t.Errorf("%s: строка %d в файле на строке %d, ожидалась %d", tt.name, i, row.Line, tt.wantLines[i])
+			}
+			if tt.wantRows[i] == nil {
+				if row.Err == nil {
+					t.Errorf("%s: строка %d разобрана без ошибки: %q", tt.name, row.Line, row.Values)
+				}
+				continue
+			}
+			if row.Err != nil || !reflect.DeepEqual(row.Values, tt.wantRows[i]) {
+				t.Errorf("%s: строка %d = %q, %v; ожидалось %q", tt.name, row.Line, row.Values, row.Err, tt.wantRows[i])
+			}
+		}
+	}
+}
+
+func TestReadTableEncodings(t *testing.T) {
+	text := "Название;Страна\nКраска;Россия\n"
+	win, err := charmap.Windows1251.NewEncoder().String(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	koi, err := charmap.KOI8R.NewEncoder().String(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range []string{"\xEF\xBB\xBF" + text, win, koi} {
+		f := Sniff([]byte(data))
+		table, err := ReadTable([]byte(data), f)
+		if err != nil {
+			t.Errorf("%s: %v", f.Encoding, err)
+			continue
+		}
+		if f.Delimiter != ';' || !f.HasHeader || len(table.Rows) != 1 || table.Rows[0].Values[1] != "Россия" {
+			t.Errorf("%s: формат %+v, строки %+v", f.Encoding, f, table.Rows)
+		}
+	}
+	if _, err := ReadTable([]byte(win), Format{Delimiter: ';', Encoding: EncodingUTF8}); err == nil {
+		t.Error("файл Windows-1251 прочитан как UTF-8 без ошибки")
+	}
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := []struct {
+		v       string
+		want    string
+		wantErr bool
+	}{
+		{"1234.50", "1234.50", false},
+		{"1 234,50", "1234.50", false},
+		{"1 234", "1234", false},
+		{"1.234,50", "1234.50", false},
+		{"1,234.50", "1234.50", false},
+		{"1'234'567", "1234567", false},
+		{"1.234.567", "1234567", false},
+		{"1,234,567", "1234567", false},
+		{"1,5", "1.5", false},
+		{"1,25", "1.25", false},
+		{"1,234", "", true},
+		{"1.234", "", true},
+		{"-1.234", "", true},
+		{"1.5", "1.5", false},
+		{"1.2345", "1.2345", false},
+		{"0.125", "0.125", false},
+		{"0,125", "0.125", false},
+		{"1500.000", "1500.000", false},
+		{"1234,567", "1234.567", false},
+	}
+	for _, tt := range tests {
+		got, err := normalizeNumber(tt.v)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("normalizeNumber(%q) = %q, %v; ожидалось %q, ошибка %v", tt.v, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"cursovay/internal/address"
+	"cursovay/internal/model"
+	"cursovay/internal/validation"
+	"encoding/json"
+	"fmt"
+)
+
+// ReadJSON читает записи в формате ExportToJSON (массив объектов)
+// или JSON Lines (по объекту в строке). Записи, которые не разбираются
+// или содержат ошибки проверки checker, не импортируются и попадают
+// в отчет; предупреждения попадают в отчет вместе с записью. Для массива Line в отчете — индекс записи с 0,
+// для JSON Lines — номер строки файла. Значения пользовательских полей
+// проверяются по schema — пользовательским полям файла, в который идет импорт.
+func ReadJSON(data []byte, schema model.Schema, checker *validation.Checker) (*Result, error) {
+	if checker == nil {
+		checker = validation.Default().NewChecker(nil, schema.Fields())
+	}
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("файл пуст")
+	}
+
+	result := &Result{}
+	if trimmed[0] == '[' {
+		var records []json.RawMessage
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("неверный JSON: %v", err)
+		}
+		result.Indexed = true
+		for i, raw := range records {
+			result.add(checker, schema, i, raw)
+		}
+		return result, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		result.add(checker, schema, line, append(json.RawMessage(nil), raw...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// add разбирает одну запись и проверяет ее
+func (r *Result) add(checker *validation.Checker, schema model.Schema, line int, raw json.RawMessage) {
+	r.Rows++
+
+	var m model.Manufacturer
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&m); err != nil {
+		r.Issues = append(r.Issues, Issue{Line: line, Message: err.Error(), Severity: Rejected})
+		return
+	}
+	// Старые выгрузки содержат только адрес одной строкой. Если части
+	// адреса в записи есть, хотя бы пустые, адрес не разбирается: иначе
+	// выгрузка и обратный импорт добавили бы улицу и город
+	if hasAddressParts(raw) || !address.Split(&m) {
+		address.Join(&m)
+	}
+	m.SyncLatest()
+	m.Contacts = m.Contacts.Trimmed()
+	m.Tags = model.SplitList(model.JoinList(m.Tags))
+	// Значения пользовательских полей приводятся к типам полей файла,
+	// в который идет импорт; поля, которых в нем нет, — такая же ошибка,
+	// как неизвестный ключ
+	custom := m.Custom
+	m.Custom = nil
+	ok := true
+	for key, value := range custom {
+		field, found := schema.Lookup(key)
+		if !found {
+			r.Issues = append(r.Issues, Issue{
+				Line: line, Column: key, Value: value,
+				Message: "неизвестное пользовательское поле", Severity: Rejected,
+			})
+			ok = false
+			continue
+		}
+		if err := field.Field().Set(&m, value); err != nil {
+			r.Issues = append(r.Issues, Issue{
+				Line: line, Column: key, Value: value, Message: err.Error(), Severity: Rejected,
+			})
+			ok = false
+		}
+	}
+	if !ok {
+		return
+	}
+	if !r.check(checker, line, &m, nil, schema.Fields()) {
+		return
+	}
+	r.Manufacturers = append(r.Manufacturers, m)
+	r.Lines = append(r.Lines, line)
+}
+
+// hasAddressParts сообщает, что в записи JSON есть поле части адреса
+func hasAddressParts(raw json.RawMessage) bool {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		return false
+	}
+	for _, key := range []string{"street", "city", "region", "postal_code"} {
+		if _, ok := keys[key]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"cursovay/internal/model"
+	"testing"
+)
+
+func TestReadJSONAddress(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantStreet string
+		wantCity   string
+		wantAddr   string
+	}{
+		{
+			name:       "старая выгрузка без частей адреса",
+			data:       `[{"name": "Alpha", "founded_year": 1990, "address": "150000, Россия, г. Ярославль, ул. Ленина, д. 5"}]`,
+			wantStreet: "ул. Ленина, д. 5", wantCity: "Ярославль", wantAddr: "ул. Ленина, д. 5, Ярославль, 150000",
+		},
+		{
+			name:     "выгрузка с пустыми частями адреса",
+			data:     `[{"name": "Alpha", "founded_year": 1990, "address": "150000, Россия, г. Ярославль, ул. Ленина, д. 5", "street": "", "city": "", "region": "", "postal_code": ""}]`,
+			wantAddr: "150000, Россия, г. Ярославль, ул. Ленина, д. 5",
+		},
+		{
+			name:       "части адреса собираются в строку",
+			data:       `{"name": "Alpha", "founded_year": 1990, "address": "старый", "street": "ул. Ленина, 5", "city": "Ярославль"}`,
+			wantStreet: "ул. Ленина, 5", wantCity: "Ярославль", wantAddr: "ул. Ленина, 5, Ярославль",
+		},
+	}
+	for _, tt := range tests {
+		result, err := ReadJSON([]byte(tt.data), nil, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(result.Manufacturers) != 1 {
+			t.Errorf("%s: импортировано %d записей: %+v", tt.name, len(result.Manufacturers), result.Issues)
+			continue
+		}
+		m := result.Manufacturers[0]
+		if m.Street != tt.wantStreet || m.City != tt.wantCity || m.Address != tt.wantAddr {
+			t.Errorf("%s: улица %q, город %q, адрес %q", tt.name, m.Street, m.City, m.Address)
+		}
+	}
+}
+
+func TestReadJSONRejects(t *testing.T) {
+	data := "{\"name\": \"Alpha\", \"founded_year\": 1990}\n" +
+		"{\"name\": \"Beta\", \"founded_year\": 0}\n" +
+		"\n" +
+		"{\"name\": \"Gamma\", \"unknown\": 1}\n" +
+		"{\"name\": \"Delta\", \"founded_year\": 2000, \"custom\": {\"grade\": \"x\"}}\n"
+	schema := model.Schema{{Key: "grade", Type: model.TypeNumber}}
+	result, err := ReadJSON([]byte(data), schema, nil)
+	if err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	if len(result.Manufacturers) != 1 || result.Rows != 4 || result.Rejected() != 3 || result.Indexed {
+		t.Errorf("записей %d из %d, отклонено %d: %+v", len(result.Manufacturers), result.Rows, result.Rejected(), result.Issues)
+	}
+	for _, issue := range result.Issues {
+		if issue.Line == 3 {
+			t.Errorf("пустая строка попала в отчет: %+v", issue)
+		}
+	}
+}
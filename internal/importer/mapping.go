@@ -0,0 +1,307 @@
+package importer
+
+import (
+	"cursovay/internal/address"
+	"cursovay/internal/country"
+	"cursovay/internal/currency"
+	"cursovay/internal/model"
+	"cursovay/internal/phone"
+	"cursovay/internal/validation"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Mapping сопоставляет каждому столбцу файла ключ поля model.Field;
+// пустая строка — столбец не импортируется
+type Mapping []string
+
+// ImportFields — поля файла fields (model.Schema.Fields), в которые можно
+// импортировать столбцы. ID при импорте всегда назначаются заново,
+// поэтому его здесь нет.
+func ImportFields(fields []model.Field) []model.Field {
+	result := make([]model.Field, 0, len(fields))
+	for _, f := range fields {
+		if f.Key != "id" {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// GuessMapping сопоставляет столбцы полям файла fields по заголовкам
+// и псевдонимам полей. Без заголовка столбцы идут в порядке полей файла.
+func GuessMapping(t *Table, f Format, fields []model.Field) Mapping {
+	mapping := make(Mapping, len(t.Header))
+	used := make(map[string]bool)
+	for i, name := range t.Header {
+		var key string
+		if f.HasHeader {
+			if field, ok := model.LookupField(fields, name); ok {
+				key = field.Key
+			}
+		} else if i < len(fields) {
+			key = fields[i].Key
+		}
+		if key == "id" || used[key] {
+			continue
+		}
+		mapping[i] = key
+		used[key] = true
+	}
+	return mapping
+}
+
+// Severity — насколько серьезна проблема в строке
+type Severity int
+
+const (
+	// Warning — строка импортирована, но не проходит проверку
+	Warning Severity = iota
+	// Rejected — строка не импортирована
+	Rejected
+	// Skipped — строка метаданных в начале файла, а не данные
+	Skipped
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Rejected:
+		return "rejected"
+	case Skipped:
+		return "skipped"
+	}
+	return "warning"
+}
+
+// Issue — проблема в одной строке файла
+type Issue struct {
+	Line     int
+	Column   string // заголовок столбца, если проблема в конкретном значении
+	Value    string
+	Message  string
+	Severity Severity
+}
+
+// Result — итог преобразования таблицы
+type Result struct {
+	Manufacturers []model.Manufacturer
+	Lines         []int // номер строки файла для каждой записи
+	Issues        []Issue
+	Rows          int  // всего строк данных в файле
+	Indexed       bool // Line — индекс записи в массиве JSON, а не номер строки
+}
+
+// Rejected возвращает число строк, которые не удалось импортировать
+func (r *Result) Rejected() int {
+	lines := make(map[int]bool)
+	for _, issue := range r.Issues {
+		if issue.Severity == Rejected {
+			lines[issue.Line] = true
+		}
+	}
+	return len(lines)
+}
+
+// Convert превращает строки таблицы в записи. Строки с неверными
+// значениями и с ошибками проверки checker не импортируются, но каждая
+// ошибка попадает в отчет; нарушения-предупреждения тоже попадают
+// в отчет, а запись импортируется. Страны приводятся к кодам ISO 3166,
+// валюты — к кодам ISO 4217, телефоны записи и ее контактов — к E.164,
+// адрес разбирается на улицу, город, регион и индекс.
+// Столбцы сопоставляются полям файла fileFields (model.Schema.Fields).
+// Без checker применяются правила validation.Default.
+func Convert(t *Table, mapping Mapping, fileFields []model.Field, checker *validation.Checker) (*Result, error) {
+	fields := make([]*model.Field, len(mapping))
+	mapped := false
+	for i, key := range mapping {
+		if key == "" {
+			continue
+		}
+		field, ok := model.LookupField(fileFields, key)
+		if !ok {
+			return nil, fmt.Errorf("неизвестное поле: %s", key)
+		}
+		fields[i] = &field
+		mapped = true
+	}
+	if !mapped {
+		return nil, fmt.Errorf("ни один столбец не сопоставлен полю")
+	}
+
+	if checker == nil {
+		checker = validation.Default().NewChecker(nil, fileFields)
+	}
+	columns := make(map[string]string)
+	for i, field := range fields {
+		if field != nil {
+			columns[field.Key] = t.Header[i]
+		}
+	}
+
+	result := &Result{Rows: len(t.Rows)}
+	for _, row := range t.Skipped {
+		result.Issues = append(result.Issues, Issue{
+			Line: row.Line, Value: strings.Join(row.Values, " "),
+			Message: "строка метаданных пропущена", Severity: Skipped,
+		})
+	}
+	for _, row := range t.Rows {
+		if row.Err != nil {
+			result.Issues = append(result.Issues, Issue{
+				Line: row.Line, Message: row.Err.Error(), Severity: Rejected,
+			})
+			continue
+		}
+
+		var m model.Manufacturer
+		ok := true
+		for i, field := range fields {
+			if field == nil || i >= len(row.Values) {
+				continue
+			}
+			value := row.Values[i]
+			var err error
+			if field.Numeric {
+				value, err = normalizeNumber(value)
+			}
+			if err == nil {
+				err = field.Set(&m, value)
+			}
+			if err != nil {
+				result.Issues = append(result.Issues, Issue{
+					Line: row.Line, Column: t.Header[i], Value: row.Values[i],
+					Message: err.Error(), Severity: Rejected,
+				})
+				ok = false
+			}
+		}
+		if !ok {
+			continue
+		}
+		// Страны приводятся к кодам ISO 3166, телефоны — к E.164;
+		// нераспознанные значения остаются как есть и попадают в отчет
+		// по правилам проверки. Адрес одной строкой разбирается на части,
+		// если в файле их нет; выручка берется из истории по годам, если она есть.
+		m.Country, _ = country.Normalize(m.Country)
+		m.Currency, _ = currency.Normalize(m.Currency)
+		if !address.Split(&m) {
+			address.Join(&m)
+		}
+		m.SyncLatest()
+		m.Phone, _ = phone.Normalize(m.Phone, m.Country)
+		for i := range m.Contacts {
+			m.Contacts[i].Phone, _ = phone.Normalize(m.Contacts[i].Phone, m.Country)
+		}
+
+		if !result.check(checker, row.Line, &m, columns, fileFields) {
+			continue
+		}
+		result.Manufacturers = append(result.Manufacturers, m)
+		result.Lines = append(result.Lines, row.Line)
+	}
+	return result, nil
+}
+
+// check проверяет запись и добавляет нарушения в отчет. Возвращает false,
+// если среди нарушений есть ошибки и запись импортировать нельзя.
+// columns сопоставляет ключ поля заголовку столбца; поля без столбца
+// подписываются заголовком из полей файла fields.
+func (r *Result) check(checker *validation.Checker, line int, m *model.Manufacturer, columns map[string]string, fields []model.Field) bool {
+	violations := checker.Check(m)
+	for _, v := range violations {
+		severity := Warning
+		if v.Severity == validation.Error {
+			severity = Rejected
+		}
+		column, ok := columns[v.Field]
+		if !ok {
+			column = v.Field
+			if field, found := model.LookupField(fields, v.Field); found {
+				column = field.Header
+			}
+		}
+		r.Issues = append(r.Issues, Issue{
+			Line: line, Column: column, Value: v.Value, Message: v.Message, Severity: severity,
+		})
+	}
+	if violations.Err() != nil {
+		return false
+	}
+	checker.Add(m)
+	return true
+}
+
+// normalizeNumber приводит числа вида "1 234,50", "1.234,50" и "1,234.50"
+// к виду "1234.50". Из двух разных разделителей дробную часть отделяет
+// последний, несколько одинаковых разделяют тысячи. Один разделитель
+// перед ровно тремя цифрами ("1,234" или "1.234") может быть и тем и другим,
+// такое число не угадывается, а отклоняется. Если до разделителя ноль
+// или больше трех цифр ("0.125", "1500.000"), это дробная часть.
+func normalizeNumber(v string) (string, error) {
+	v = strings.NewReplacer(" ", "", "\u00a0", "", "'", "").Replace(v)
+	comma, dot := strings.LastIndex(v, ","), strings.LastIndex(v, ".")
+	switch {
+	case comma == -1:
+		switch {
+		case strings.Count(v, ".") > 1:
+			v = strings.ReplaceAll(v, ".", "")
+		case dot != -1 && ambiguousSeparator(v, dot):
+			return "", fmt.Errorf("неоднозначное число %q: точка может отделять и тысячи, и дробную часть", v)
+		}
+	case dot == -1:
+		switch {
+		case strings.Count(v, ",") > 1:
+			v = strings.ReplaceAll(v, ",", "")
+		case ambiguousSeparator(v, comma):
+			return "", fmt.Errorf("неоднозначное число %q: запятая может отделять и тысячи, и дробную часть", v)
+		default:
+			v = strings.Replace(v, ",", ".", 1)
+		}
+	case comma > dot:
+		v = strings.Replace(strings.ReplaceAll(v, ".", ""), ",", ".", 1)
+	default:
+		v = strings.ReplaceAll(v, ",", "")
+	}
+	return v, nil
+}
+
+// ambiguousSeparator сообщает, что единственный разделитель в позиции sep
+// может отделять тысячи: после него ровно три цифры, а до него от одной
+// до трех цифр без ведущего нуля
+func ambiguousSeparator(v string, sep int) bool {
+	whole, fraction := strings.TrimLeft(v[:sep], "+-"), v[sep+1:]
+	return len(fraction) == 3 && isDigits(fraction) &&
+		len(whole) >= 1 && len(whole) <= 3 && isDigits(whole) && whole[0] != '0'
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteReport записывает отчет об ошибках в CSV
+func (r *Result) WriteReport(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	position := "Line"
+	if r.Indexed {
+		position = "Index"
+	}
+	if err := writer.Write([]string{position, "Column", "Value", "Severity", "Message"}); err != nil {
+		return err
+	}
+	for _, issue := range r.Issues {
+		record := []string{strconv.Itoa(issue.Line), issue.Column, issue.Value, issue.Severity.String(), issue.Message}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"cursovay/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	fields := model.Schema{{Key: "grade", Name: "Оценка", Type: model.TypeNumber}}.Fields()
+	data := "Name,Country,FoundedYear,Revenue,Оценка,Примечание\n" +
+		"Alpha,Россия,1990,\"1 234,50\",5,x\n" +
+		"Beta,DE,1995,\"1,234\",,\n" +
+		",FR,2001,10,,\n" +
+		"Gamma,Атлантида,2001,,много,\n" +
+		"Delta,US,1700,,,\n"
+	f := Format{Delimiter: ',', Encoding: EncodingUTF8, HasHeader: true}
+	table, err := ReadTable([]byte(data), f)
+	if err != nil {
+		t.Fatalf("ReadTable: %v", err)
+	}
+	mapping := GuessMapping(table, f, fields)
+	if want := (Mapping{"name", "country", "foundedYear", "revenue", "grade", ""}); !reflect.DeepEqual(mapping, want) {
+		t.Fatalf("GuessMapping = %q, ожидалось %q", mapping, want)
+	}
+
+	result, err := Convert(table, mapping, fields, nil)
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	if len(result.Manufacturers) != 1 || result.Rows != 5 {
+		t.Fatalf("импортировано %d из %d строк, отчет %+v", len(result.Manufacturers), result.Rows, result.Issues)
+	}
+	m := result.Manufacturers[0]
+	if m.Name != "Alpha" || m.Country != "RU" || m.Revenue != 1234.5 || m.Custom["grade"] != "5" {
+		t.Errorf("запись %+v", m)
+	}
+	if result.Rejected() != 4 {
+		t.Errorf("отклонено %d строк, ожидалось 4: %+v", result.Rejected(), result.Issues)
+	}
+
+	// Каждая проблема подписана строкой файла и заголовком столбца
+	want := map[int]string{3: "Revenue", 4: "Name", 5: "Оценка", 6: "FoundedYear"}
+	for line, column := range want {
+		found := false
+		for _, issue := range result.Issues {
+			if issue.Line == line && issue.Column == column && issue.Severity == Rejected {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("нет ошибки в строке %d, столбце %s: %+v", line, column, result.Issues)
+		}
+	}
+}
+
+func TestConvertWithoutMapping(t *testing.T) {
+	table := &Table{Header: []string{"Столбец 1"}, Rows: []Row{{Line: 1, Values: []string{"Alpha"}}}}
+	if _, err := Convert(table, Mapping{""}, model.Schema(nil).Fields(), nil); err == nil {
+		t.Error("Convert без сопоставленных столбцов не вернул ошибку")
+	}
+	if _, err := Convert(table, Mapping{"grade"}, model.Schema(nil).Fields(), nil); err == nil {
+		t.Error("Convert с полем не из файла не вернул ошибку")
+	}
+}
@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Workbook — книга Excel, из листов которой импортируются записи
+type Workbook struct {
+	file *excelize.File
+}
+
+// OpenWorkbook открывает книгу .xlsx из памяти
+func OpenWorkbook(data []byte) (*Workbook, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть книгу Excel: %v", err)
+	}
+	return &Workbook{file: f}, nil
+}
+
+// Close освобождает ресурсы книги
+func (wb *Workbook) Close() error {
+	return wb.file.Close()
+}
+
+// Sheets возвращает листы книги по порядку
+func (wb *Workbook) Sheets() []string {
+	return wb.file.GetSheetList()
+}
+
+// Sniff определяет, есть ли на листе заголовок. Разделитель
+// и кодировка для Excel не нужны.
+func (wb *Workbook) Sniff(sheet string) Format {
+	f := Format{Encoding: EncodingUTF8}
+	rows, err := wb.rows(sheet)
+	if err != nil {
+		return f
+	}
+	for _, values := range rows {
+		if !isBlank(values) {
+			f.HasHeader = looksLikeHeader(values)
+			break
+		}
+	}
+	return f
+}
+
+// ReadSheet читает лист в таблицу для того же сопоставления столбцов,
+// что и CSV. Номер строки в таблице — номер строки листа.
+func (wb *Workbook) ReadSheet(sheet string, f Format) (*Table, error) {
+	rows, err := wb.rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{}
+	columns := 0
+	for i, values := range rows {
+		if isBlank(values) {
+			continue
+		}
+		values = trimAll(values)
+		if f.HasHeader && t.Header == nil {
+			t.Header = values
+			continue
+		}
+		t.Rows = append(t.Rows, Row{Line: i + 1, Values: values})
+		if len(values) > columns {
+			columns = len(values)
+		}
+	}
+	// Заголовок может быть короче строк данных
+	for i := len(t.Header); i < columns; i++ {
+		t.Header = append(t.Header, fmt.Sprintf("Столбец %d", i+1))
+	}
+	return t, nil
+}
+
+// rows читает значения ячеек без форматирования: "1234.5", а не "1 234,50"
+func (wb *Workbook) rows(sheet string) ([][]string, error) {
+	rows, err := wb.file.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать лист %q: %v", sheet, err)
+	}
+	return rows, nil
+}
+
+func isBlank(values []string) bool {
+	for _, v := range values {
+		if v != "" {
+			return false
+		}
+	}
+	return true
+}
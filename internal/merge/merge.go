@@ -0,0 +1,203 @@
+// Package merge сопоставляет записи двух файлов производителей
+// и собирает итоговый список с выбором версии для каждого поля.
+package merge
+
+import (
+	"cursovay/internal/model"
+	"fmt"
+	"strings"
+)
+
+// MatchBy — как находить одну и ту же запись в двух файлах
+type MatchBy int
+
+const (
+	ByID MatchBy = iota
+	ByNameCountry
+	ByEmail
+)
+
+// ParseMatchBy разбирает название способа сопоставления: id, name или email
+func ParseMatchBy(name string) (MatchBy, error) {
+	switch strings.ToLower(name) {
+	case "id":
+		return ByID, nil
+	case "name", "name+country":
+		return ByNameCountry, nil
+	case "email":
+		return ByEmail, nil
+	default:
+		return 0, fmt.Errorf("неизвестный способ сопоставления: %s (id, name или email)", name)
+	}
+}
+
+// Side — чья версия поля попадает в результат
+type Side int
+
+const (
+	Base Side = iota
+	Other
+)
+
+// Pair — запись, найденная в обоих файлах
+type Pair struct {
+	Base   model.Manufacturer
+	Other  model.Manufacturer
+	Fields []string        // ключи полей с разными значениями, кроме id
+	Choice map[string]Side // выбор для полей из Fields, по умолчанию Base
+
+	fields []model.Field // поля файла, по которым сравнивались записи
+}
+
+// Entry — запись только из одного файла
+type Entry struct {
+	Record  model.Manufacturer
+	Include bool // включить запись в результат
+}
+
+// Plan — результат сравнения двух файлов
+type Plan struct {
+	Matched []*Pair  // в порядке базового файла
+	Added   []*Entry // есть только во втором файле
+	Removed []*Entry // есть только в базовом файле
+
+	order []int // ID записей базового файла по порядку
+}
+
+// Compare сопоставляет записи other с записями base. Каждая запись
+// участвует не больше чем в одной паре. По умолчанию результат
+// совпадает с base плюс записи, которых в base нет. Записи сравниваются
+// по полям базового файла fields (model.Schema.Fields).
+func Compare(base, other []model.Manufacturer, by MatchBy, fields []model.Field) *Plan {
+	index := make(map[string][]int)
+	for i := range other {
+		if k := key(&other[i], by); k != "" {
+			index[k] = append(index[k], i)
+		}
+	}
+
+	plan := &Plan{}
+	used := make([]bool, len(other))
+	for i := range base {
+		plan.order = append(plan.order, base[i].ID)
+		k := key(&base[i], by)
+		match := -1
+		for _, j := range index[k] {
+			if !used[j] {
+				match = j
+				break
+			}
+		}
+		if match == -1 {
+			plan.Removed = append(plan.Removed, &Entry{Record: base[i], Include: true})
+			continue
+		}
+		used[match] = true
+		plan.Matched = append(plan.Matched, newPair(base[i], other[match], fields))
+	}
+	for j := range other {
+		if !used[j] {
+			plan.Added = append(plan.Added, &Entry{Record: other[j], Include: true})
+		}
+	}
+	return plan
+}
+
+// Conflicts возвращает пары, в которых значения полей различаются
+func (p *Plan) Conflicts() []*Pair {
+	var conflicts []*Pair
+	for _, pair := range p.Matched {
+		if len(pair.Fields) > 0 {
+			conflicts = append(conflicts, pair)
+		}
+	}
+	return conflicts
+}
+
+// PreferAll выбирает одну сторону для всех конфликтующих полей
+func (p *Plan) PreferAll(side Side) {
+	for _, pair := range p.Matched {
+		for _, key := range pair.Fields {
+			pair.Choice[key] = side
+		}
+	}
+}
+
+// Result собирает итоговый список: записи базового файла в прежнем
+// порядке с выбранными значениями полей, затем добавленные записи.
+// Добавленные записи получают ID 0 — новый ID назначает контроллер.
+func (p *Plan) Result() []model.Manufacturer {
+	pairs := make(map[int]*Pair, len(p.Matched))
+	for _, pair := range p.Matched {
+		pairs[pair.Base.ID] = pair
+	}
+	removed := make(map[int]*Entry, len(p.Removed))
+	for _, entry := range p.Removed {
+		removed[entry.Record.ID] = entry
+	}
+
+	var result []model.Manufacturer
+	for _, id := range p.order {
+		if pair, ok := pairs[id]; ok {
+			result = append(result, pair.Resolved())
+		} else if entry := removed[id]; entry.Include {
+			result = append(result, entry.Record)
+		}
+	}
+	for _, entry := range p.Added {
+		if entry.Include {
+			m := entry.Record
+			m.ID = 0
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// Resolved возвращает запись базового файла с полями, для которых
+// выбрана версия второго файла
+func (pair *Pair) Resolved() model.Manufacturer {
+	m := pair.Base
+	for _, key := range pair.Fields {
+		if pair.Choice[key] != Other {
+			continue
+		}
+		field, _ := model.LookupField(pair.fields, key)
+		field.Set(&m, field.Get(&pair.Other))
+	}
+	return m
+}
+
+func newPair(base, other model.Manufacturer, fields []model.Field) *Pair {
+	pair := &Pair{Base: base, Other: other, Choice: make(map[string]Side), fields: fields}
+	for _, f := range fields {
+		if f.Key != "id" && f.Get(&base) != f.Get(&other) {
+			pair.Fields = append(pair.Fields, f.Key)
+			pair.Choice[f.Key] = Base
+		}
+	}
+	return pair
+}
+
+// key возвращает ключ сопоставления; пустой ключ ни с чем не совпадает
+func key(m *model.Manufacturer, by MatchBy) string {
+	switch by {
+	case ByNameCountry:
+		if normalize(m.Name) == "" {
+			return ""
+		}
+		return normalize(m.Name) + "\x00" + normalize(m.Country)
+	case ByEmail:
+		return normalize(m.Email)
+	default:
+		if m.ID <= 0 {
+			return ""
+		}
+		return fmt.Sprint(m.ID)
+	}
+}
+
+// normalize убирает регистр и лишние пробелы
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
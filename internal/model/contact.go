@@ -0,0 +1,71 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Contact — сотрудник производителя, с которым мы работаем:
+// отдел продаж, логистика, бухгалтерия
+type Contact struct {
+	Name  string `json:"name"`
+	Role  string `json:"role,omitempty"`
+	Phone string `json:"phone,omitempty"`
+	Email string `json:"email,omitempty"`
+	Notes string `json:"notes,omitempty"`
+}
+
+// Empty сообщает, что у контакта не заполнено ни одно поле
+func (c Contact) Empty() bool {
+	return c == Contact{}
+}
+
+// Contacts — контакты производителя. В CSV и SQLite хранятся одной
+// строкой в формате JSON: [{"name":"Иванов","role":"Продажи",...}].
+type Contacts []Contact
+
+// ParseContacts разбирает контакты из строки формата Contacts.String.
+// Пустые контакты отбрасываются, пробелы по краям значений убираются.
+func ParseContacts(s string) (Contacts, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	var contacts Contacts
+	if err := json.Unmarshal([]byte(s), &contacts); err != nil {
+		return nil, fmt.Errorf("ожидается список контактов в JSON: %v", err)
+	}
+	return contacts.Trimmed(), nil
+}
+
+// String записывает контакты одной строкой JSON; без контактов — пустая строка
+func (cs Contacts) String() string {
+	if len(cs) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(cs)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Trimmed возвращает копию контактов без пробелов по краям значений
+// и без пустых контактов
+func (cs Contacts) Trimmed() Contacts {
+	var result Contacts
+	for _, c := range cs {
+		c = Contact{
+			Name:  strings.TrimSpace(c.Name),
+			Role:  strings.TrimSpace(c.Role),
+			Phone: strings.TrimSpace(c.Phone),
+			Email: strings.TrimSpace(c.Email),
+			Notes: strings.TrimSpace(c.Notes),
+		}
+		if !c.Empty() {
+			result = append(result, c)
+		}
+	}
+	return result
+}
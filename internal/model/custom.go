@@ -0,0 +1,316 @@
+package model
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType — тип пользовательского поля
+type FieldType string
+
+const (
+	TypeText   FieldType = "text"
+	TypeNumber FieldType = "number"
+	TypeDate   FieldType = "date" // ГГГГ-ММ-ДД
+	TypeEnum   FieldType = "enum" // одно из Options
+	TypeBool   FieldType = "bool" // true или false
+)
+
+// FieldTypes перечисляет типы пользовательских полей
+var FieldTypes = []FieldType{TypeText, TypeNumber, TypeDate, TypeEnum, TypeBool}
+
+// DateLayout — формат дат в значениях пользовательских полей
+const DateLayout = "2006-01-02"
+
+// CustomField — поле, объявленное пользователем в конкретном файле базы.
+// Key используется в запросах и правилах проверки, Name — заголовок
+// столбца в таблице и CSV.
+type CustomField struct {
+	Key     string    `json:"key"`
+	Name    string    `json:"name,omitempty"`
+	Type    FieldType `json:"type"`
+	Options []string  `json:"options,omitempty"` // допустимые значения для enum
+}
+
+// Title возвращает заголовок поля: Name или Key
+func (f CustomField) Title() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return f.Key
+}
+
+// Schema — пользовательские поля файла. Хранится в метаданных файла
+// одной строкой JSON.
+type Schema []CustomField
+
+// customKey — допустимый ключ: его можно написать в запросе без кавычек
+var customKey = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// ParseSchema разбирает и проверяет описание пользовательских полей
+func ParseSchema(s string) (Schema, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	var schema Schema
+	if err := json.Unmarshal([]byte(s), &schema); err != nil {
+		return nil, fmt.Errorf("неверное описание пользовательских полей: %v", err)
+	}
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// String записывает описание полей одной строкой JSON; без полей — пустая строка
+func (s Schema) String() string {
+	if len(s) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Validate проверяет, что ключи и заголовки полей уникальны, не совпадают
+// со встроенными полями, а у перечислений есть допустимые значения
+func (s Schema) Validate() error {
+	fields := append([]Field(nil), builtinFields...)
+	for _, f := range s {
+		if !customKey.MatchString(f.Key) {
+			return fmt.Errorf("поле %q: ключ должен начинаться с латинской буквы и состоять из латинских букв, цифр и _", f.Key)
+		}
+		for _, name := range []string{f.Key, f.Title()} {
+			if existing, ok := LookupField(fields, name); ok {
+				return fmt.Errorf("поле %q: имя %q уже занято полем %s", f.Key, name, existing.Key)
+			}
+		}
+		switch f.Type {
+		case TypeText, TypeNumber, TypeDate, TypeBool:
+		case TypeEnum:
+			if len(f.Options) == 0 {
+				return fmt.Errorf("поле %q: для перечисления нужны допустимые значения", f.Key)
+			}
+		default:
+			return fmt.Errorf("поле %q: неизвестный тип %q (text, number, date, enum, bool)", f.Key, f.Type)
+		}
+		fields = append(fields, f.Field())
+	}
+	return nil
+}
+
+// Fields возвращает поля файла: встроенные и за ними пользовательские.
+// Все, что зависит от набора полей (колонки CSV, поиск, сортировка,
+// проверка), получает этот список, а не общее состояние пакета.
+func (s Schema) Fields() []Field {
+	fields := append([]Field(nil), builtinFields...)
+	for _, f := range s {
+		fields = append(fields, f.Field())
+	}
+	return fields
+}
+
+// IsCustomKey сообщает, что key годится в ключи пользовательского поля
+func IsCustomKey(key string) bool {
+	return customKey.MatchString(key)
+}
+
+// Lookup ищет пользовательское поле по ключу
+func (s Schema) Lookup(key string) (CustomField, bool) {
+	for _, f := range s {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return CustomField{}, false
+}
+
+// Field описывает пользовательское поле так же, как встроенные:
+// значения хранятся в Manufacturer.Custom и приводятся к виду своего типа
+func (f CustomField) Field() Field {
+	key := f.Key
+	return Field{
+		Key:     key,
+		Header:  f.Title(),
+		Numeric: f.Type == TypeNumber,
+		Date:    f.Type == TypeDate,
+		Get:     func(m *Manufacturer) string { return m.Custom[key] },
+		Set: func(m *Manufacturer, v string) error {
+			value, err := f.Normalize(v)
+			if err != nil {
+				return err
+			}
+			m.SetCustom(key, value)
+			return nil
+		},
+		Compare: func(a, b *Manufacturer) int { return f.compare(a.Custom[key], b.Custom[key]) },
+	}
+}
+
+// Normalize приводит значение к виду типа поля: число без лишних нулей,
+// дату к ГГГГ-ММ-ДД, да/нет к true/false, перечисление — к написанию
+// из Options. Пустое значение допустимо для любого типа.
+func (f CustomField) Normalize(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return "", nil
+	}
+	switch f.Type {
+	case TypeNumber:
+		n, err := parseFloat(strings.Replace(strings.NewReplacer(" ", "", "\u00a0", "").Replace(v), ",", ".", 1))
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case TypeDate:
+		date, err := ParseDate(v)
+		if err != nil {
+			return "", err
+		}
+		return date.Format(DateLayout), nil
+	case TypeBool:
+		switch strings.ToLower(v) {
+		case "true", "yes", "y", "1", "+", "да", "д":
+			return "true", nil
+		case "false", "no", "n", "0", "-", "нет", "н":
+			return "false", nil
+		}
+		return "", fmt.Errorf("ожидается да или нет, получено %q", v)
+	case TypeEnum:
+		for _, option := range f.Options {
+			if strings.EqualFold(option, v) {
+				return option, nil
+			}
+		}
+		return "", fmt.Errorf("допустимые значения: %s", strings.Join(f.Options, ", "))
+	}
+	return v, nil
+}
+
+// compare сравнивает значения поля: числа и даты — по величине,
+// перечисления — по порядку Options, остальное — как текст
+func (f CustomField) compare(a, b string) int {
+	switch f.Type {
+	case TypeNumber:
+		x, _ := parseFloat(a)
+		y, _ := parseFloat(b)
+		return cmp.Compare(x, y)
+	case TypeEnum:
+		return cmp.Compare(f.optionIndex(a), f.optionIndex(b))
+	}
+	return compareFold(a, b)
+}
+
+// optionIndex — номер значения в Options; пустое значение идет первым
+func (f CustomField) optionIndex(v string) int {
+	for i, option := range f.Options {
+		if option == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// dateLayouts — форматы дат, которые принимает ParseDate
+var dateLayouts = []string{DateLayout, "02.01.2006", "2006/01/02", "2.1.2006"}
+
+// ParseDate разбирает дату в формате ГГГГ-ММ-ДД или ДД.ММ.ГГГГ
+func ParseDate(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неверная дата %q, ожидается ГГГГ-ММ-ДД", v)
+}
+
+// SetCustom задает значение пользовательского поля; пустое значение
+// удаляет его. Записи делят карту значений после копирования, поэтому
+// карта каждый раз создается заново.
+func (m *Manufacturer) SetCustom(key, value string) {
+	custom := make(map[string]string, len(m.Custom)+1)
+	for k, v := range m.Custom {
+		custom[k] = v
+	}
+	if value == "" {
+		delete(custom, key)
+	} else {
+		custom[key] = value
+	}
+	if len(custom) == 0 {
+		custom = nil
+	}
+	m.Custom = custom
+}
+
+// ParseCustom разбирает значения пользовательских полей, записанные
+// CustomString
+func ParseCustom(s string) (map[string]string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	var custom map[string]string
+	if err := json.Unmarshal([]byte(s), &custom); err != nil {
+		return nil, fmt.Errorf("неверные значения пользовательских полей: %v", err)
+	}
+	if len(custom) == 0 {
+		return nil, nil
+	}
+	return custom, nil
+}
+
+// CustomString записывает значения пользовательских полей одной строкой
+// JSON; без значений — пустая строка
+func CustomString(custom map[string]string) string {
+	if len(custom) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(custom)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// SplitList разбирает список через запятую или точку с запятой:
+// пробелы по краям и пустые элементы отбрасываются, повторы
+// (без учета регистра) остаются один раз
+func SplitList(v string) []string {
+	var items []string
+	seen := make(map[string]bool)
+	for _, item := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' }) {
+		item = strings.TrimSpace(item)
+		key := strings.ToLower(item)
+		if item == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		items = append(items, item)
+	}
+	return items
+}
+
+// JoinList записывает список через запятую
+func JoinList(items []string) string {
+	return strings.Join(items, ", ")
+}
+
+// HasItem сообщает, есть ли в списке элемент без учета регистра
+func HasItem(items []string, item string) bool {
+	for _, existing := range items {
+		if strings.EqualFold(existing, item) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package model
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Field описывает одно поле производителя: имя колонки в CSV,
+// ключ для сортировки/поиска и функции чтения/записи значения
+type Field struct {
+	Key     string   // ключ поля (совпадает с именами столбцов сортировки)
+	Header  string   // заголовок колонки в CSV
+	Aliases []string // альтернативные заголовки, встречающиеся в файлах
+	Numeric bool     // значение — число (ID, год, выручка, сотрудники)
+	Date    bool     // значение — дата в формате ГГГГ-ММ-ДД
+	List    bool     // значение — список через запятую (теги)
+	Get     func(m *Manufacturer) string
+	Set     func(m *Manufacturer, value string) error
+	Compare func(a, b *Manufacturer) int // <0, 0, >0 как в strings.Compare
+}
+
+// builtinFields — поля, которые есть у производителя в любом файле,
+// в порядке колонок CSV. Первые девять полей совпадают со старым
+// форматом файлов. Поля конкретного файла — Schema.Fields().
+var builtinFields = []Field{
+	{
+		Key:     "id",
+		Header:  "ID",
+		Numeric: true,
+		Get:     func(m *Manufacturer) string { return strconv.Itoa(m.ID) },
+		Set: func(m *Manufacturer, v string) error {
+			id, err := parseInt(v)
+			m.ID = id
+			return err
+		},
+		Compare: func(a, b *Manufacturer) int { return cmp.Compare(a.ID, b.ID) },
+	},
+	{
+		Key:     "name",
+		Header:  "Name",
+		Aliases: []string{"Название"},
+		Get:     func(m *Manufacturer) string { return m.Name },
+		Set:     func(m *Manufacturer, v string) error { m.Name = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.Name, b.Name) },
+	},
+	{
+		Key:     "country",
+		Header:  "Country",
+		Aliases: []string{"Страна"},
+		Get:     func(m *Manufacturer) string { return m.Country },
+		Set:     func(m *Manufacturer, v string) error { m.Country = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.Country, b.Country) },
+	},
+	{
+		Key:     "address",
+		Header:  "Address",
+		Aliases: []string{"Адрес"},
+		Get:     func(m *Manufacturer) string { return m.Address },
+		Set:     func(m *Manufacturer, v string) error { m.Address = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.Address, b.Address) },
+	},
+	{
+		Key:     "phone",
+		Header:  "Phone",
+		Aliases: []string{"Телефон"},
+		Get:     func(m *Manufacturer) string { return m.Phone },
+		Set:     func(m *Manufacturer, v string) error { m.Phone = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.Phone, b.Phone) },
+	},
+	{
+		Key:     "email",
+		Header:  "Email",
+		Aliases: []string{"E-mail"},
+		Get:     func(m *Manufacturer) string { return m.Email },
+		Set:     func(m *Manufacturer, v string) error { m.Email = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.Email, b.Email) },
+	},
+	{
+		Key:     "productType",
+		Header:  "ProductType",
+		Aliases: []string{"ProductTypes", "Тип продукции", "Типы продукции"},
+		List:    true,
+		Get:     func(m *Manufacturer) string { return m.ProductType },
+		Set:     func(m *Manufacturer, v string) error { m.SetProductTypes(SplitList(v)); return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.ProductType, b.ProductType) },
+	},
+	{
+		Key:     "foundedYear",
+		Header:  "FoundedYear",
+		Aliases: []string{"Founded", "Год основания"},
+		Numeric: true,
+		Get:     func(m *Manufacturer) string { return strconv.Itoa(m.FoundedYear) },
+		Set: func(m *Manufacturer, v string) error {
+			year, err := parseInt(v)
+			m.FoundedYear = year
+			return err
+		},
+		Compare: func(a, b *Manufacturer) int { return cmp.Compare(a.FoundedYear, b.FoundedYear) },
+	},
+	{
+		Key:     "revenue",
+		Header:  "Revenue",
+		Aliases: []string{"Доход", "Выручка"},
+		Numeric: true,
+		Get:     func(m *Manufacturer) string { return strconv.FormatFloat(m.Revenue, 'f', 2, 64) },
+		Set: func(m *Manufacturer, v string) error {
+			revenue, err := parseFloat(v)
+			m.Revenue = revenue
+			return err
+		},
+		Compare: func(a, b *Manufacturer) int { return cmp.Compare(a.Revenue, b.Revenue) },
+	},
+	{
+		Key:     "employees",
+		Header:  "Employees",
+		Aliases: []string{"Сотрудники"},
+		Numeric: true,
+		Get:     func(m *Manufacturer) string { return strconv.Itoa(m.Employees) },
+		Set: func(m *Manufacturer, v string) error {
+			employees, err := parseInt(v)
+			m.Employees = employees
+			return err
+		},
+		Compare: func(a, b *Manufacturer) int { return cmp.Compare(a.Employees, b.Employees) },
+	},
+	{
+		Key:     "website",
+		Header:  "Website",
+		Aliases: []string{"Сайт"},
+		Get:     func(m *Manufacturer) string { return m.Website },
+		Set:     func(m *Manufacturer, v string) error { m.Website = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.Website, b.Website) },
+	},
+	// Части адреса; Address остается адресом одной строкой для экспорта
+	{
+		Key:     "street",
+		Header:  "Street",
+		Aliases: []string{"Улица"},
+		Get:     func(m *Manufacturer) string { return m.Street },
+		Set:     func(m *Manufacturer, v string) error { m.Street = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.Street, b.Street) },
+	},
+	{
+		Key:     "city",
+		Header:  "City",
+		Aliases: []string{"Город", "Town"},
+		Get:     func(m *Manufacturer) string { return m.City },
+		Set:     func(m *Manufacturer, v string) error { m.City = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.City, b.City) },
+	},
+	{
+		Key:     "region",
+		Header:  "Region",
+		Aliases: []string{"Регион", "Область", "State", "Province"},
+		Get:     func(m *Manufacturer) string { return m.Region },
+		Set:     func(m *Manufacturer, v string) error { m.Region = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.Region, b.Region) },
+	},
+	{
+		Key:     "postalCode",
+		Header:  "PostalCode",
+		Aliases: []string{"Postcode", "ZIP", "Индекс", "Почтовый индекс"},
+		Get:     func(m *Manufacturer) string { return m.PostalCode },
+		Set:     func(m *Manufacturer, v string) error { m.PostalCode = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.PostalCode, b.PostalCode) },
+	},
+	// Выручка и сотрудники по годам; записи сравниваются по среднегодовому
+	// росту выручки, записи без него идут первыми
+	{
+		Key:     "history",
+		Header:  "History",
+		Aliases: []string{"Revenue History", "История", "История выручки"},
+		Get:     func(m *Manufacturer) string { return m.History.String() },
+		Set: func(m *Manufacturer, v string) error {
+			history, err := ParseHistory(v)
+			m.History = history
+			return err
+		},
+		Compare: func(a, b *Manufacturer) int {
+			ga, okA := a.History.CAGR()
+			gb, okB := b.History.CAGR()
+			if okA != okB {
+				if okA {
+					return 1
+				}
+				return -1
+			}
+			return cmp.Compare(ga, gb)
+		},
+	},
+	{
+		Key:     "currency",
+		Header:  "Currency",
+		Aliases: []string{"Валюта"},
+		Get:     func(m *Manufacturer) string { return m.Currency },
+		Set:     func(m *Manufacturer, v string) error { m.Currency = v; return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(a.Currency, b.Currency) },
+	},
+	// Контакты хранятся одной строкой JSON; записи сравниваются
+	// по числу контактов
+	{
+		Key:     "contacts",
+		Header:  "Contacts",
+		Aliases: []string{"Контакты", "Контактные лица"},
+		Get:     func(m *Manufacturer) string { return m.Contacts.String() },
+		Set: func(m *Manufacturer, v string) error {
+			contacts, err := ParseContacts(v)
+			m.Contacts = contacts
+			return err
+		},
+		Compare: func(a, b *Manufacturer) int { return cmp.Compare(len(a.Contacts), len(b.Contacts)) },
+	},
+	{
+		Key:     "tags",
+		Header:  "Tags",
+		Aliases: []string{"Tag", "Теги", "Метки"},
+		List:    true,
+		Get:     func(m *Manufacturer) string { return JoinList(m.Tags) },
+		Set:     func(m *Manufacturer, v string) error { m.Tags = SplitList(v); return nil },
+		Compare: func(a, b *Manufacturer) int { return compareFold(JoinList(a.Tags), JoinList(b.Tags)) },
+	},
+}
+
+// BuiltinFields возвращает копию встроенных полей: поля файла
+// без пользовательских полей
+func BuiltinFields() []Field {
+	return append([]Field(nil), builtinFields...)
+}
+
+// FieldByName ищет встроенное поле по ключу, заголовку или псевдониму
+// без учета регистра, пробелов и подчеркиваний ("product_type" ==
+// "ProductType"). Пользовательские поля файла ищет LookupField.
+func FieldByName(name string) (Field, bool) {
+	return LookupField(builtinFields, name)
+}
+
+// LookupField ищет поле среди fields так же, как FieldByName
+func LookupField(fields []Field, name string) (Field, bool) {
+	norm := normalizeFieldName(name)
+	for _, f := range fields {
+		if normalizeFieldName(f.Key) == norm || normalizeFieldName(f.Header) == norm {
+			return f, true
+		}
+		for _, alias := range f.Aliases {
+			if normalizeFieldName(alias) == norm {
+				return f, true
+			}
+		}
+	}
+	return Field{}, false
+}
+
+// compareFold сравнивает строки без учета регистра
+func compareFold(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func normalizeFieldName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer("_", "", " ", "", "-", "").Replace(name)
+}
+
+// Пустое значение числового поля считаем нулем
+func parseInt(v string) (int, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("неверное целое число %q", v)
+	}
+	return n, nil
+}
+
+func parseFloat(v string) (float64, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("неверное число %q", v)
+	}
+	return f, nil
+}
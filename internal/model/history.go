@@ -0,0 +1,133 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// YearStat — выручка и численность сотрудников за один год
+type YearStat struct {
+	Year      int     `json:"year"`
+	Revenue   float64 `json:"revenue"`
+	Employees int     `json:"employees,omitempty"`
+}
+
+// History — показатели производителя по годам, по возрастанию года.
+// В CSV и SQLite хранится одной строкой: "2019=1200.50/45; 2020=1310.00/47"
+// (численность после "/" можно не указывать).
+type History []YearStat
+
+// ParseHistory разбирает историю из строки формата History.String.
+// Годы упорядочиваются; при повторе года остается последнее значение.
+func ParseHistory(s string) (History, error) {
+	var h History
+	for _, item := range strings.Split(s, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		yearText, values, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("ожидается год=выручка, получено %q", item)
+		}
+		year, err := strconv.Atoi(strings.TrimSpace(yearText))
+		if err != nil || year <= 0 {
+			return nil, fmt.Errorf("неверный год %q", yearText)
+		}
+		revenueText, employeesText, _ := strings.Cut(values, "/")
+		revenue, err := parseFloat(strings.Replace(strings.TrimSpace(revenueText), ",", ".", 1))
+		if err != nil {
+			return nil, fmt.Errorf("%d: %v", year, err)
+		}
+		employees, err := parseInt(employeesText)
+		if err != nil {
+			return nil, fmt.Errorf("%d: %v", year, err)
+		}
+		h = append(h, YearStat{Year: year, Revenue: revenue, Employees: employees})
+	}
+	return h.Sorted(), nil
+}
+
+// String записывает историю одной строкой
+func (h History) String() string {
+	parts := make([]string, len(h))
+	for i, s := range h {
+		parts[i] = fmt.Sprintf("%d=%.2f", s.Year, s.Revenue)
+		if s.Employees != 0 {
+			parts[i] += "/" + strconv.Itoa(s.Employees)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Sorted возвращает копию истории по возрастанию года без повторов года
+// (остается последнее из повторяющихся значений)
+func (h History) Sorted() History {
+	if len(h) == 0 {
+		return nil
+	}
+	sorted := make(History, len(h))
+	copy(sorted, h)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Year < sorted[j].Year })
+	result := sorted[:0]
+	for _, s := range sorted {
+		if n := len(result); n > 0 && result[n-1].Year == s.Year {
+			result[n-1] = s
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// Latest возвращает показатели последнего года
+func (h History) Latest() (YearStat, bool) {
+	if len(h) == 0 {
+		return YearStat{}, false
+	}
+	return h[len(h)-1], true
+}
+
+// Growth возвращает рост выручки к предыдущему году в долях (0.1 — +10%)
+// для каждого года, у которого есть предыдущий год с ненулевой выручкой
+func (h History) Growth() map[int]float64 {
+	growth := make(map[int]float64)
+	for i := 1; i < len(h); i++ {
+		prev, cur := h[i-1], h[i]
+		if prev.Revenue > 0 && cur.Year == prev.Year+1 {
+			growth[cur.Year] = cur.Revenue/prev.Revenue - 1
+		}
+	}
+	return growth
+}
+
+// CAGR — среднегодовой темп роста выручки между первым и последним годом
+// в долях. false, если лет меньше двух или выручка не положительна.
+func (h History) CAGR() (float64, bool) {
+	if len(h) < 2 {
+		return 0, false
+	}
+	first, last := h[0], h[len(h)-1]
+	if first.Revenue <= 0 || last.Revenue <= 0 || last.Year <= first.Year {
+		return 0, false
+	}
+	return math.Pow(last.Revenue/first.Revenue, 1/float64(last.Year-first.Year)) - 1, true
+}
+
+// SyncLatest упорядочивает историю и переносит показатели последнего года
+// в Revenue и Employees, чтобы сортировка, поиск и экспорт по текущей
+// выручке работали как раньше. Запись без истории не меняется.
+func (m *Manufacturer) SyncLatest() {
+	m.History = m.History.Sorted()
+	latest, ok := m.History.Latest()
+	if !ok {
+		return
+	}
+	m.Revenue = latest.Revenue
+	if latest.Employees != 0 {
+		m.Employees = latest.Employees
+	}
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseHistory(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    History
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"2019=1200.50/45; 2020=1310.00/47", History{{2019, 1200.5, 45}, {2020, 1310, 47}}, false},
+		{"2020=10; 2019=5", History{{2019, 5, 0}, {2020, 10, 0}}, false},
+		{"2019=5; 2019=7/3", History{{2019, 7, 3}}, false},
+		{"2019=1,5;", History{{2019, 1.5, 0}}, false},
+		{"2019", nil, true},
+		{"год=5", nil, true},
+		{"0=5", nil, true},
+		{"2019=много", nil, true},
+		{"2019=5/три", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHistory(tt.s)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseHistory(%q) = %v, %v; ожидалось %v, ошибка %v", tt.s, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHistoryStringRoundTrip(t *testing.T) {
+	h := History{{2019, 1200.5, 45}, {2020, 1310, 0}}
+	got, err := ParseHistory(h.String())
+	if err != nil || !reflect.DeepEqual(got, h) {
+		t.Errorf("ParseHistory(%q) = %v, %v", h.String(), got, err)
+	}
+}
+
+func TestCAGR(t *testing.T) {
+	tests := []struct {
+		name   string
+		h      History
+		want   float64
+		wantOK bool
+	}{
+		{"нет истории", nil, 0, false},
+		{"один год", History{{2020, 100, 0}}, 0, false},
+		{"рост за два года", History{{2018, 100, 0}, {2019, 150, 0}, {2020, 121, 0}}, 0.1, true},
+		{"падение", History{{2019, 100, 0}, {2020, 50, 0}}, -0.5, true},
+		{"нулевая выручка в начале", History{{2019, 0, 0}, {2020, 50, 0}}, 0, false},
+		{"отрицательная выручка в конце", History{{2019, 10, 0}, {2020, -5, 0}}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.h.CAGR()
+		if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: CAGR() = %v, %v; ожидалось %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
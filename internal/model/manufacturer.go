@@ -1,42 +1,40 @@
 package model
 
-import (
-	"errors"
-	"regexp"
-	"time"
-)
-
 type Manufacturer struct {
-	ID          int     `json:"id" csv:"id"`
-	Name        string  `json:"name" csv:"name"`
-	Country     string  `json:"country" csv:"country"`
-	Address     string  `json:"address" csv:"address"`
-	Phone       string  `json:"phone" csv:"phone"`
-	Email       string  `json:"email" csv:"email"`
-	ProductType string  `json:"product_type" csv:"product_type"`
-	FoundedYear int     `json:"founded_year" csv:"founded_year"`
-	Revenue     float64 `json:"revenue" csv:"revenue"`
-	Employees   int     `json:"employees" csv:"employees"`
-	Website     string  `json:"website" csv:"website"`
+	ID          int               `json:"id" csv:"id"`
+	Name        string            `json:"name" csv:"name"`
+	Country     string            `json:"country" csv:"country"`
+	Address     string            `json:"address" csv:"address"` // адрес одной строкой; при заполненных частях адреса собирается из них
+	Phone       string            `json:"phone" csv:"phone"`
+	Email       string            `json:"email" csv:"email"`
+	ProductType string            `json:"product_type" csv:"product_type"` // типы продукции через запятую: "Dye, Ink"
+	FoundedYear int               `json:"founded_year" csv:"founded_year"`
+	Revenue     float64           `json:"revenue" csv:"revenue"`
+	Employees   int               `json:"employees" csv:"employees"`
+	Website     string            `json:"website" csv:"website"`
+	Street      string            `json:"street" csv:"street"` // части адреса выгружаются в JSON и пустыми: так импорт отличает их от старых выгрузок
+	City        string            `json:"city" csv:"city"`
+	Region      string            `json:"region" csv:"region"`
+	PostalCode  string            `json:"postal_code" csv:"postal_code"`
+	History     History           `json:"history,omitempty" csv:"history"`   // выручка и сотрудники по годам; Revenue и Employees — последний год
+	Currency    string            `json:"currency,omitempty" csv:"currency"` // код ISO 4217 выручки и истории; пусто — базовая валюта таблицы курсов
+	Contacts    Contacts          `json:"contacts,omitempty" csv:"contacts"` // контактные лица; Phone и Email — общие телефон и почта
+	Tags        []string          `json:"tags,omitempty" csv:"tags"`         // произвольные метки: preferred, ISO9001
+	Custom      map[string]string `json:"custom,omitempty" csv:"-"`          // значения пользовательских полей файла по ключу поля
 }
 
-func (m *Manufacturer) Validate() error {
-	if m.Name == "" {
-		return errors.New("name is required")
-	}
-
-	if m.FoundedYear < 1800 || m.FoundedYear > time.Now().Year() {
-		return errors.New("invalid founded year")
-	}
-
-	if m.Revenue < 0 {
-		return errors.New("revenue cannot be negative")
-	}
+// ProductTypes возвращает типы продукции производителя
+func (m *Manufacturer) ProductTypes() []string {
+	return SplitList(m.ProductType)
+}
 
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(m.Email) {
-		return errors.New("invalid email format")
-	}
+// SetProductTypes задает типы продукции производителя
+func (m *Manufacturer) SetProductTypes(types []string) {
+	m.ProductType = JoinList(SplitList(JoinList(types)))
+}
 
-	return nil
+// HasProductType сообщает, относится ли производитель к типу продукции
+// (без учета регистра)
+func (m *Manufacturer) HasProductType(productType string) bool {
+	return HasItem(m.ProductTypes(), productType)
 }
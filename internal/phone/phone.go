@@ -0,0 +1,101 @@
+// Package phone разбирает телефоны в любом написании (89999998899,
+// +7 (999) 123-45-67, 8-999-123-45-67) и приводит их к E.164.
+// Регион для номеров без кода страны определяется по стране производителя.
+package phone
+
+import (
+	"cursovay/internal/country"
+	"errors"
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// DefaultRegion — регион для номеров без кода страны, если страна
+// производителя не указана или неизвестна
+const DefaultRegion = "RU"
+
+// ErrInvalid — номер не разбирается или не существует в плане нумерации
+var ErrInvalid = errors.New("неверный номер телефона")
+
+// Region возвращает код региона ISO 3166 для страны производителя: кода,
+// названия или города (см. country.Lookup). Для неизвестной страны
+// или страны без телефонного кода возвращает пустую строку.
+func Region(name string) string {
+	c, ok := country.Lookup(name)
+	if !ok || phonenumbers.GetCountryCodeForRegion(c.Code) == 0 {
+		return ""
+	}
+	return c.Code
+}
+
+// Parse приводит номер к E.164 (+79991234567). Номер без кода страны
+// разбирается по правилам страны countryName. Пустой номер остается пустым.
+func Parse(raw, countryName string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	region := Region(countryName)
+	if region == "" {
+		region = DefaultRegion
+	}
+	number, err := phonenumbers.Parse(raw, region)
+	if err != nil || !phonenumbers.IsValidNumber(number) {
+		return "", ErrInvalid
+	}
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
+
+// Normalize возвращает номер в E.164 и true или исходную строку и false,
+// если номер не разбирается: неверные номера не теряются
+func Normalize(raw, countryName string) (string, bool) {
+	number, err := Parse(raw, countryName)
+	if err != nil {
+		return raw, false
+	}
+	return number, true
+}
+
+// localeRegions — регион пользователя для языка интерфейса
+var localeRegions = map[string]string{
+	"ru": "RU",
+}
+
+// Format показывает номер для языка интерфейса lang: номера своего региона —
+// в национальном формате (8 (999) 123-45-67), остальные — в международном
+// (+49 30 123456). Неразбираемые номера возвращаются как есть.
+func Format(number, lang string) string {
+	parsed, ok := parseE164(number)
+	if !ok {
+		return number
+	}
+	if region := localeRegions[lang]; region != "" && phonenumbers.GetRegionCodeForNumber(parsed) == region {
+		return phonenumbers.Format(parsed, phonenumbers.NATIONAL)
+	}
+	return phonenumbers.Format(parsed, phonenumbers.INTERNATIONAL)
+}
+
+// International показывает номер в E.164 в международном формате
+// (+7 999 123-45-67). В отличие от национального формата, такая строка
+// разбирается Parse в тот же номер при любой стране записи, поэтому
+// ее можно подставлять в поле ввода. Неразбираемые номера возвращаются как есть.
+func International(number string) string {
+	parsed, ok := parseE164(number)
+	if !ok {
+		return number
+	}
+	return phonenumbers.Format(parsed, phonenumbers.INTERNATIONAL)
+}
+
+// parseE164 разбирает номер с кодом страны
+func parseE164(number string) (*phonenumbers.PhoneNumber, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(number), "+") {
+		return nil, false
+	}
+	parsed, err := phonenumbers.Parse(number, DefaultRegion)
+	if err != nil || !phonenumbers.IsValidNumber(parsed) {
+		return nil, false
+	}
+	return parsed, true
+}
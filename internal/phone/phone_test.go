@@ -0,0 +1,57 @@
+package phone
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw, country string
+		want         string
+		wantErr      bool
+	}{
+		{"", "RU", "", false},
+		{"89991234567", "RU", "+79991234567", false},
+		{"+7 (999) 123-45-67", "DE", "+79991234567", false},
+		{"030 1234567", "Germany", "+49301234567", false},
+		{"030 1234567", "", "", true},
+		{"abc", "RU", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.raw, tt.country)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Parse(%q, %q) = %q, %v; ожидалось %q, ошибка %v", tt.raw, tt.country, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// Номер, показанный в поле ввода, должен разбираться в тот же номер
+// при любой стране записи
+func TestInternationalRoundTrip(t *testing.T) {
+	numbers := []string{"+79991234567", "+49301234567", "+12025550123"}
+	for _, number := range numbers {
+		for _, country := range []string{"RU", "DE", "US", ""} {
+			got, err := Parse(International(number), country)
+			if err != nil || got != number {
+				t.Errorf("Parse(International(%q), %q) = %q, %v", number, country, got, err)
+			}
+		}
+	}
+	if got := International("8 999"); got != "8 999" {
+		t.Errorf("неразбираемый номер изменился: %q", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		number, lang, want string
+	}{
+		{"+79991234567", "ru", "8 (999) 123-45-67"},
+		{"+79991234567", "en", "+7 999 123-45-67"},
+		{"+49301234567", "ru", "+49 30 1234567"},
+		{"12345", "ru", "12345"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.number, tt.lang); got != tt.want {
+			t.Errorf("Format(%q, %q) = %q, ожидалось %q", tt.number, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+// Package query разбирает и выполняет поисковые запросы вида
+//
+//	country:Russia revenue>1000 founded:1990..2005 -productType:Dye "exact phrase"
+//
+// Условия разделяются пробелами и должны выполняться все сразу:
+//
+//	слово            подстрока в любом поле
+//	"фраза"          фраза целиком в любом поле
+//	поле:текст       подстрока в поле (для числовых полей и дат — равенство)
+//	поле="текст"     значение поля целиком
+//	поле>N, >=, <, <=  сравнение числового поля или даты (ГГГГ-ММ-ДД)
+//	поле:A..B        диапазон числового поля или дат включительно (A.. или ..B — без границы)
+//	-условие         отрицание
+//
+// Слово с двоеточием, где до двоеточия не название поля (http://example.com,
+// 10:30), ищется как обычное слово. Одиночный минус без условия пропускается.
+//
+// В полях-списках (теги) условие выполняется, если ему подходит хотя бы
+// один элемент: tags=preferred находит записи с тегом preferred.
+// Регистр букв не учитывается. Поля называются так же, как в model.LookupField:
+// поиск идет по полям файла, включая его пользовательские поля.
+package query
+
+import (
+	"cursovay/internal/country"
+	"cursovay/internal/model"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Op — вид сравнения в условии
+type Op int
+
+const (
+	OpContains Op = iota
+	OpEqual
+	OpLess
+	OpLessEqual
+	OpGreater
+	OpGreaterEqual
+	OpRange
+)
+
+// Term — одно условие запроса
+type Term struct {
+	Field  *model.Field // nil — любое поле
+	Op     Op
+	Value  string  // текст для OpContains и OpEqual
+	Number float64 // число для сравнений числового поля
+	Low    *float64
+	High   *float64 // границы для OpRange, nil — без границы
+	Negate bool
+}
+
+// Query — разобранный запрос
+type Query struct {
+	Terms []Term
+
+	fields []model.Field // поля файла для условий без поля
+}
+
+// SyntaxError — ошибка в тексте запроса с позицией (в символах, с 1)
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("ошибка в запросе (позиция %d): %s", e.Pos, e.Msg)
+}
+
+// Parse разбирает текст запроса для файла с полями fields
+// (model.Schema.Fields). Пустой запрос подходит всем записям.
+func Parse(input string, fields []model.Field) (*Query, error) {
+	p := &parser{input: []rune(input), fields: fields}
+	q := &Query{fields: fields}
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return q, nil
+		}
+		if p.loneMinus() {
+			p.pos++
+			continue
+		}
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		q.Terms = append(q.Terms, term)
+	}
+}
+
+// Empty сообщает, что в запросе нет условий
+func (q *Query) Empty() bool {
+	return len(q.Terms) == 0
+}
+
+// PlainText возвращает текст, если запрос — одно слово без поля и отрицания.
+// Такой запрос совпадает с обычным поиском подстроки по всем полям.
+func (q *Query) PlainText() (string, bool) {
+	if len(q.Terms) != 1 {
+		return "", false
+	}
+	t := q.Terms[0]
+	if t.Field != nil || t.Negate || t.Op != OpContains {
+		return "", false
+	}
+	return t.Value, true
+}
+
+// Match проверяет, что запись удовлетворяет всем условиям
+func (q *Query) Match(m *model.Manufacturer) bool {
+	for _, t := range q.Terms {
+		if t.match(m, q.fields) == t.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter возвращает подходящие записи в исходном порядке
+func (q *Query) Filter(data []model.Manufacturer) []model.Manufacturer {
+	result := make([]model.Manufacturer, 0, len(data))
+	for i := range data {
+		if q.Match(&data[i]) {
+			result = append(result, data[i])
+		}
+	}
+	return result
+}
+
+func (t *Term) match(m *model.Manufacturer, fields []model.Field) bool {
+	if t.Field == nil {
+		for _, f := range fields {
+			if containsFold(f.Get(m), t.Value) {
+				return true
+			}
+		}
+		for _, name := range country.Names(m.Country) {
+			if containsFold(name, t.Value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	value := t.Field.Get(m)
+	if !t.Field.Numeric && !t.Field.Date {
+		if t.Field.Key == "country" {
+			return matchCountry(value, t)
+		}
+		if t.Field.List {
+			for _, item := range model.SplitList(value) {
+				if t.matchText(item) {
+					return true
+				}
+			}
+			return false
+		}
+		return t.matchText(value)
+	}
+
+	n, err := fieldNumber(t.Field, value)
+	if err != nil {
+		return false
+	}
+	switch t.Op {
+	case OpLess:
+		return n < t.Number
+	case OpLessEqual:
+		return n <= t.Number
+	case OpGreater:
+		return n > t.Number
+	case OpGreaterEqual:
+		return n >= t.Number
+	case OpRange:
+		return (t.Low == nil || n >= *t.Low) && (t.High == nil || n <= *t.High)
+	default:
+		return n == t.Number
+	}
+}
+
+// matchText сравнивает текстовое значение: целиком для OpEqual,
+// иначе как подстроку
+func (t *Term) matchText(value string) bool {
+	if t.Op == OpEqual {
+		return strings.EqualFold(strings.TrimSpace(value), t.Value)
+	}
+	return containsFold(value, t.Value)
+}
+
+// matchCountry сравнивает страну и по коду, и по названиям: country:Russia
+// находит записи с RU, "Россия" и "Yaroslavl"
+func matchCountry(value string, t *Term) bool {
+	if want, ok := country.Lookup(t.Value); ok {
+		if got, ok := country.Lookup(value); ok {
+			return got.Code == want.Code
+		}
+	}
+	for _, name := range country.Names(value) {
+		if t.Op == OpEqual && strings.EqualFold(strings.TrimSpace(name), t.Value) ||
+			t.Op != OpEqual && containsFold(name, t.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+type parser struct {
+	input  []rune
+	pos    int
+	fields []model.Field
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.input) }
+
+func (p *parser) peek() rune { return p.input[p.pos] }
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// loneMinus сообщает, что в текущей позиции минус, за которым нет условия
+func (p *parser) loneMinus() bool {
+	return p.peek() == '-' && (p.pos+1 == len(p.input) || unicode.IsSpace(p.input[p.pos+1]))
+}
+
+func (p *parser) errorf(pos int, format string, args ...interface{}) error {
+	return &SyntaxError{Pos: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) term() (Term, error) {
+	var t Term
+	if p.peek() == '-' {
+		t.Negate = true
+		p.pos++
+	}
+
+	if p.peek() == '"' {
+		phrase, err := p.quoted()
+		if err != nil {
+			return t, err
+		}
+		t.Value = phrase
+		return t, nil
+	}
+
+	// Имя поля или просто слово — до оператора или пробела
+	start := p.pos
+	for !p.eof() && !unicode.IsSpace(p.peek()) && !isOperator(p.peek()) {
+		p.pos++
+	}
+	name := string(p.input[start:p.pos])
+	if p.eof() || unicode.IsSpace(p.peek()) {
+		t.Value = name
+		return t, nil
+	}
+
+	if name == "" {
+		return t, p.errorf(start, "перед %q не указано поле", string(p.peek()))
+	}
+	field, ok := model.LookupField(p.fields, name)
+	if !ok {
+		// Не поле, а часть слова: адрес сайта, время и т.п.
+		for !p.eof() && !unicode.IsSpace(p.peek()) {
+			p.pos++
+		}
+		t.Value = string(p.input[start:p.pos])
+		return t, nil
+	}
+	t.Field = &field
+
+	opPos := p.pos
+	op := p.operator()
+
+	valuePos := p.pos
+	var value string
+	if !p.eof() && p.peek() == '"' {
+		quoted, err := p.quoted()
+		if err != nil {
+			return t, err
+		}
+		value = quoted
+	} else {
+		for !p.eof() && !unicode.IsSpace(p.peek()) {
+			p.pos++
+		}
+		value = string(p.input[valuePos:p.pos])
+	}
+	if value == "" {
+		return t, p.errorf(valuePos, "не указано значение для поля %s", field.Key)
+	}
+
+	return t, p.condition(&t, op, value, opPos, valuePos)
+}
+
+// condition заполняет условие по оператору и значению
+func (p *parser) condition(t *Term, op, value string, opPos, valuePos int) error {
+	field := t.Field
+	if !field.Numeric && !field.Date {
+		switch op {
+		case ":":
+			t.Op = OpContains
+		case "=":
+			t.Op = OpEqual
+		default:
+			return p.errorf(opPos, "сравнение %s возможно только для числовых полей и дат, %s — текстовое", op, field.Key)
+		}
+		t.Value = value
+		return nil
+	}
+
+	if op == ":" {
+		if low, high, ok := strings.Cut(value, ".."); ok {
+			return p.rangeCondition(t, low, high, valuePos)
+		}
+	}
+
+	n, err := fieldNumber(field, value)
+	if err != nil {
+		return p.errorf(valuePos, "%q не %s (поле %s)", value, kind(field), field.Key)
+	}
+	t.Number = n
+	switch op {
+	case "<":
+		t.Op = OpLess
+	case "<=":
+		t.Op = OpLessEqual
+	case ">":
+		t.Op = OpGreater
+	case ">=":
+		t.Op = OpGreaterEqual
+	default:
+		t.Op = OpEqual
+	}
+	return nil
+}
+
+func (p *parser) rangeCondition(t *Term, low, high string, valuePos int) error {
+	if low == "" && high == "" {
+		return p.errorf(valuePos, "в диапазоне нужна хотя бы одна граница")
+	}
+
+	t.Op = OpRange
+	if low != "" {
+		n, err := fieldNumber(t.Field, low)
+		if err != nil {
+			return p.errorf(valuePos, "%q не %s (поле %s)", low, kind(t.Field), t.Field.Key)
+		}
+		t.Low = &n
+	}
+	if high != "" {
+		n, err := fieldNumber(t.Field, high)
+		if err != nil {
+			return p.errorf(valuePos+len([]rune(low))+2, "%q не %s (поле %s)", high, kind(t.Field), t.Field.Key)
+		}
+		t.High = &n
+	}
+	if t.Low != nil && t.High != nil && *t.Low > *t.High {
+		return p.errorf(valuePos, "начало диапазона %s больше конца %s", low, high)
+	}
+	return nil
+}
+
+func (p *parser) operator() string {
+	start := p.pos
+	p.pos++
+	if !p.eof() && p.peek() == '=' && (p.input[start] == '<' || p.input[start] == '>') {
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// quoted читает строку в кавычках, начиная с открывающей кавычки
+func (p *parser) quoted() (string, error) {
+	start := p.pos
+	p.pos++
+	for !p.eof() && p.peek() != '"' {
+		p.pos++
+	}
+	if p.eof() {
+		return "", p.errorf(start, "не закрыта кавычка")
+	}
+	value := string(p.input[start+1 : p.pos])
+	p.pos++
+	if value == "" {
+		return "", p.errorf(start, "пустая фраза в кавычках")
+	}
+	return value, nil
+}
+
+func isOperator(r rune) bool {
+	return r == ':' || r == '<' || r == '>' || r == '='
+}
+
+// parseNumber принимает и десятичную запятую
+func parseNumber(s string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+}
+
+// fieldNumber переводит значение поля в число для сравнений:
+// дату — в число вида ГГГГММДД
+func fieldNumber(f *model.Field, s string) (float64, error) {
+	if !f.Date {
+		return parseNumber(s)
+	}
+	date, err := model.ParseDate(s)
+	if err != nil {
+		return 0, err
+	}
+	return float64(date.Year()*10000 + int(date.Month())*100 + date.Day()), nil
+}
+
+// kind — чего ожидает поле, для сообщений об ошибках
+func kind(f *model.Field) string {
+	if f.Date {
+		return "дата"
+	}
+	return "число"
+}
@@ -0,0 +1,113 @@
+package query
+
+import (
+	"cursovay/internal/model"
+	"reflect"
+	"testing"
+)
+
+func testData() []model.Manufacturer {
+	return []model.Manufacturer{
+		{ID: 1, Name: "Alpha", Country: "RU", FoundedYear: 1990, Revenue: 1500, ProductType: "Dye", Website: "http://alpha.ru", Tags: []string{"preferred"}},
+		{ID: 2, Name: "Beta Paint", Country: "DE", FoundedYear: 2001, Revenue: 800, ProductType: "Ink", Address: "Berlin, open 10:30"},
+		{ID: 3, Name: "Gamma-Color", Country: "FR", FoundedYear: 2005, Revenue: 3000, ProductType: "Dye, Ink"},
+	}
+}
+
+func TestParseAndMatch(t *testing.T) {
+	fields := model.Schema(nil).Fields()
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{1, 2, 3}},
+		{"alpha", []int{1}},
+		{`"beta paint"`, []int{2}},
+		{"country:Russia", []int{1}},
+		{"country:германия", []int{2}},
+		{"revenue>1000", []int{1, 3}},
+		{"revenue<=800", []int{2}},
+		{"foundedYear:1995..2005", []int{2, 3}},
+		{"foundedYear:..2000", []int{1}},
+		{"productType=dye", []int{1, 3}},
+		{"-productType:Dye", []int{2}},
+		{"tags=preferred", []int{1}},
+		{"country:FR revenue>1000", []int{3}},
+		// Слово с двоеточием, где до двоеточия не поле, ищется целиком
+		{"http://alpha.ru", []int{1}},
+		{"10:30", []int{2}},
+		{"-10:30", []int{1, 3}},
+		// Одиночный минус не условие
+		{"-", []int{1, 2, 3}},
+		{"alpha -", []int{1}},
+		{"- dye", []int{1, 3}},
+		{"-color", []int{1, 2}},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query, fields)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		var got []int
+		for _, m := range q.Filter(testData()) {
+			got = append(got, m.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: найдены %v, ожидались %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	fields := model.Schema(nil).Fields()
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{`"alpha`, 1},
+		{`""`, 1},
+		{"revenue>", 9},
+		{"revenue>много", 9},
+		{"name>5", 5},
+		{">5", 1},
+		{"foundedYear:..", 13},
+		{"foundedYear:2005..1990", 13},
+		{"alpha revenue:1..x", 18},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query, fields)
+		syntax, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Parse(%q): ошибка %v, ожидалась SyntaxError", tt.query, err)
+			continue
+		}
+		if syntax.Pos != tt.pos {
+			t.Errorf("Parse(%q): позиция %d, ожидалась %d (%v)", tt.query, syntax.Pos, tt.pos, err)
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	fields := model.Schema(nil).Fields()
+	tests := []struct {
+		query string
+		text  string
+		plain bool
+	}{
+		{"alpha", "alpha", true},
+		{"http://alpha.ru", "http://alpha.ru", true},
+		{"-alpha", "", false},
+		{"name:alpha", "", false},
+		{"alpha beta", "", false},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query, fields)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.query, err)
+		}
+		if text, plain := q.PlainText(); text != tt.text || plain != tt.plain {
+			t.Errorf("PlainText(%q) = %q, %v", tt.query, text, plain)
+		}
+	}
+}
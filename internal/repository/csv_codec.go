@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"bufio"
+	"cursovay/internal/model"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metadata — служебные параметры файла базы. В CSV они хранятся строками
+// "# ключ: значение" перед заголовком, в SQLite — в таблице meta.
+type Metadata map[string]string
+
+// MetaLastID — наибольший когда-либо выданный в файле ID. Удаленные ID
+// не используются повторно, поэтому новые записи получают LastID+1.
+const MetaLastID = "last_id"
+
+// LastID возвращает сохраненный в метаданных наибольший выданный ID
+func (md Metadata) LastID() int {
+	id, _ := strconv.Atoi(md[MetaLastID])
+	return id
+}
+
+// SetLastID сохраняет наибольший выданный ID
+func (md Metadata) SetLastID(id int) {
+	md[MetaLastID] = strconv.Itoa(id)
+}
+
+// MetaCustomFields — пользовательские поля файла в формате model.Schema
+const MetaCustomFields = "custom_fields"
+
+// Schema возвращает пользовательские поля, объявленные в файле
+func (md Metadata) Schema() (model.Schema, error) {
+	return model.ParseSchema(md[MetaCustomFields])
+}
+
+// SetSchema сохраняет пользовательские поля файла
+func (md Metadata) SetSchema(schema model.Schema) {
+	md[MetaCustomFields] = schema.String()
+}
+
+// MetaProductTypes — справочник типов продукции файла: список JSON
+const MetaProductTypes = "product_types"
+
+// ProductTypes возвращает справочник типов продукции файла
+func (md Metadata) ProductTypes() ([]string, error) {
+	value := strings.TrimSpace(md[MetaProductTypes])
+	if value == "" {
+		return nil, nil
+	}
+	var types []string
+	if err := json.Unmarshal([]byte(value), &types); err != nil {
+		return nil, fmt.Errorf("неверный справочник типов продукции: %v", err)
+	}
+	return types, nil
+}
+
+// SetProductTypes сохраняет справочник типов продукции
+func (md Metadata) SetProductTypes(types []string) {
+	if len(types) == 0 {
+		md[MetaProductTypes] = ""
+		return
+	}
+	data, _ := json.Marshal(types)
+	md[MetaProductTypes] = string(data)
+}
+
+// ReadCSV читает производителей из CSV. Колонки сопоставляются с полями
+// по заголовку, поэтому порядок колонок не важен, а старые файлы
+// из 9 колонок (без Employees и Website) читаются без ошибок.
+// Если первая строка не похожа на заголовок, колонки берутся
+// по порядку полей файла. Колонки пользовательских полей распознаются
+// по описанию из метаданных этого же файла.
+func ReadCSV(r io.Reader) ([]model.Manufacturer, Metadata, error) {
+	br := bufio.NewReader(r)
+	meta, err := readMetadata(br)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema, err := meta.Schema()
+	if err != nil {
+		return nil, nil, err
+	}
+	fields := schema.Fields()
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1 // число колонок может отличаться от заголовка
+	reader.TrimLeadingSpace = true
+
+	var (
+		columns       []*model.Field
+		manufacturers []model.Manufacturer
+	)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				continue // Пропускаем битые строки
+			}
+			return nil, nil, err
+		}
+
+		if columns == nil {
+			var isHeader bool
+			columns, isHeader = mapColumns(record, fields)
+			if isHeader {
+				continue
+			}
+		}
+
+		m, ok := decodeRecord(record, columns)
+		if !ok {
+			continue
+		}
+		manufacturers = append(manufacturers, m)
+	}
+
+	return manufacturers, meta, nil
+}
+
+// WriteCSV записывает метаданные и производителей в CSV
+// с колонками fields — полями файла, включая пользовательские.
+// Без метаданных (nil) получается обычный CSV без служебных строк:
+// так пишется экспорт для других программ.
+func WriteCSV(w io.Writer, manufacturers []model.Manufacturer, fields []model.Field, meta Metadata) error {
+	if err := writeMetadata(w, meta); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = f.Header
+	}
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	for i := range manufacturers {
+		record := make([]string, len(fields))
+		for j, f := range fields {
+			record[j] = f.Get(&manufacturers[i])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// readMetadata читает строки "# ключ: значение" в начале файла
+func readMetadata(br *bufio.Reader) (Metadata, error) {
+	meta := Metadata{}
+	for {
+		next, err := br.Peek(1)
+		if err == io.EOF {
+			return meta, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if next[0] != '#' {
+			return meta, nil
+		}
+
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			meta[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		if err == io.EOF {
+			return meta, nil
+		}
+	}
+}
+
+func writeMetadata(w io.Writer, meta Metadata) error {
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if meta[key] == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "# %s: %s\n", key, meta[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapColumns сопоставляет колонки первой строки с полями fields.
+// Возвращает false, если ни одна колонка не распознана как заголовок.
+func mapColumns(record []string, fields []model.Field) ([]*model.Field, bool) {
+	columns := make([]*model.Field, len(record))
+	recognized := false
+	for i, name := range record {
+		if f, ok := model.LookupField(fields, name); ok {
+			field := f
+			columns[i] = &field
+			recognized = true
+		}
+	}
+	if recognized {
+		return columns, true
+	}
+
+	// Файл без заголовка: используем порядок полей модели
+	columns = make([]*model.Field, len(fields))
+	for i := range fields {
+		columns[i] = &fields[i]
+	}
+	return columns, false
+}
+
+// decodeRecord собирает производителя из строки. Строки с пустым или
+// некорректным ID пропускаются, остальные некорректные числа считаются нулем.
+func decodeRecord(record []string, columns []*model.Field) (model.Manufacturer, bool) {
+	var m model.Manufacturer
+	for i, value := range record {
+		if i >= len(columns) || columns[i] == nil {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		err := columns[i].Set(&m, value)
+		if columns[i].Key == "id" && (err != nil || value == "") {
+			return m, false
+		}
+	}
+	return m, true
+}
@@ -0,0 +1,33 @@
+package repository
+
+import "cursovay/internal/model"
+
+// MaxID возвращает наибольший ID среди записей
+func MaxID(data []model.Manufacturer) int {
+	maxID := 0
+	for _, m := range data {
+		if m.ID > maxID {
+			maxID = m.ID
+		}
+	}
+	return maxID
+}
+
+// EnsureUniqueIDs выдает новые ID записям с неположительным или уже
+// встречавшимся ID (такие файлы оставляли старые версии программы).
+// lastID — наибольший выданный ID; возвращается его новое значение.
+func EnsureUniqueIDs(data []model.Manufacturer, lastID int) int {
+	if maxID := MaxID(data); maxID > lastID {
+		lastID = maxID
+	}
+
+	seen := make(map[int]bool, len(data))
+	for i := range data {
+		if data[i].ID <= 0 || seen[data[i].ID] {
+			lastID++
+			data[i].ID = lastID
+		}
+		seen[data[i].ID] = true
+	}
+	return lastID
+}
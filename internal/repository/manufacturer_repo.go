@@ -2,21 +2,21 @@ package repository
 
 import (
 	"cursovay/internal/model"
-	"encoding/csv"
 	"os"
-	"sort"
-	"strconv"
-	"strings"
 	"sync"
 )
 
-// ManufacturerRepository реализует хранение данных о производителях
+// ManufacturerRepository реализует Store поверх одного CSV файла
 type ManufacturerRepository struct {
 	filePath string
 	data     []model.Manufacturer
+	lastID   int          // наибольший выданный ID, удаленные ID не переиспользуются
+	schema   model.Schema // пользовательские поля файла
 	mu       sync.RWMutex
 }
 
+var _ Store = (*ManufacturerRepository)(nil)
+
 // NewManufacturerRepository создает новый экземпляр репозитория
 func NewManufacturerRepository(filePath string) *ManufacturerRepository {
 	repo := &ManufacturerRepository{
@@ -32,69 +32,54 @@ func NewManufacturerRepository(filePath string) *ManufacturerRepository {
 }
 
 func (r *ManufacturerRepository) SetFilePath(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.filePath = path
 }
 
 // Load загружает данные из CSV файла
 func (r *ManufacturerRepository) Load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	file, err := os.Open(r.filePath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	data, meta, err := ReadCSV(file)
 	if err != nil {
 		return err
 	}
 
-	r.data = make([]model.Manufacturer, 0, len(records))
-
-	for _, record := range records {
-		if len(record) < 9 { // Проверяем, что есть все 9 полей
-			continue
-		}
-
-		id, err := strconv.Atoi(strings.TrimSpace(record[0]))
-		if err != nil {
-			continue
-		}
-
-		foundedYear, err := strconv.Atoi(strings.TrimSpace(record[7]))
-		if err != nil {
-			foundedYear = 0 // или можно вернуть ошибку
-		}
-
-		revenue, err := strconv.ParseFloat(strings.TrimSpace(record[8]), 64)
-		if err != nil {
-			revenue = 0
-		}
-
-		manufacturer := model.Manufacturer{
-			ID:          id,
-			Name:        strings.TrimSpace(record[1]),
-			Country:     strings.TrimSpace(record[2]),
-			Address:     strings.TrimSpace(record[3]),
-			Phone:       strings.TrimSpace(record[4]),
-			Email:       strings.TrimSpace(record[5]),
-			ProductType: strings.TrimSpace(record[6]),
-			FoundedYear: foundedYear,
-			Revenue:     revenue,
-		}
-		r.data = append(r.data, manufacturer)
+	schema, err := meta.Schema()
+	if err != nil {
+		return err
 	}
 
+	r.data = data
+	r.schema = schema
+	r.lastID = EnsureUniqueIDs(r.data, meta.LastID())
 	return nil
 }
 
-// GetAll возвращает всех производителей
-func (r *ManufacturerRepository) GetAll() ([]model.Manufacturer, error) {
-	return r.data, nil
+// List возвращает копию всех производителей: изменять записи можно
+// только через Create, Update и Delete
+func (r *ManufacturerRepository) List() ([]model.Manufacturer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]model.Manufacturer, len(r.data))
+	copy(result, r.data)
+	return result, nil
 }
 
-// GetByID находит производителя по ID
-func (r *ManufacturerRepository) GetByID(id int) (*model.Manufacturer, error) {
+// Get находит производителя по ID
+func (r *ManufacturerRepository) Get(id int) (*model.Manufacturer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	for _, m := range r.data {
 		if m.ID == id {
 			return &m, nil
@@ -105,121 +90,96 @@ func (r *ManufacturerRepository) GetByID(id int) (*model.Manufacturer, error) {
 
 // Create добавляет нового производителя
 func (r *ManufacturerRepository) Create(m *model.Manufacturer) error {
-	// Генерируем новый ID
-	maxID := 0
-	for _, item := range r.data {
-		if item.ID > maxID {
-			maxID = item.ID
-		}
-	}
-	m.ID = maxID + 1
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Новый ID всегда больше любого когда-либо выданного
+	r.lastID++
+	m.ID = r.lastID
 
 	r.data = append(r.data, *m)
-	return r.Save()
+	return r.save()
 }
 
 // Save сохраняет все данные в файл
 func (r *ManufacturerRepository) Save() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.save()
+}
+
+// save записывает файл; вызывается под r.mu
+func (r *ManufacturerRepository) save() error {
 	file, err := os.Create(r.filePath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	for _, m := range r.data {
-		record := []string{
-			strconv.Itoa(m.ID),
-			m.Name,
-			m.Country,
-			m.Address,
-			m.Phone,
-			m.Email,
-			m.ProductType,
-			strconv.Itoa(m.FoundedYear),
-			strconv.FormatFloat(m.Revenue, 'f', 2, 64),
-		}
-		if err := writer.Write(record); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	meta := Metadata{}
+	meta.SetLastID(r.lastID)
+	meta.SetSchema(r.schema)
+	return WriteCSV(file, r.data, r.schema.Fields(), meta)
 }
 
 // Update обновляет данные производителя
 func (r *ManufacturerRepository) Update(m *model.Manufacturer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	for i, item := range r.data {
 		if item.ID == m.ID {
 			r.data[i] = *m
-			return r.Save()
+			return r.save()
 		}
 	}
-	return nil
+	return notFound(m.ID)
 }
 
 // Delete удаляет производителя
 func (r *ManufacturerRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	for i, item := range r.data {
 		if item.ID == id {
 			r.data = append(r.data[:i], r.data[i+1:]...)
-			return r.Save()
+			return r.save()
 		}
 	}
-	return nil
-}
-
-func (r *ManufacturerRepository) SortBy(column string, ascending bool) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	sort.Slice(r.data, func(i, j int) bool {
-		switch column {
-		case "name":
-			if ascending {
-				return r.data[i].Name < r.data[j].Name
-			}
-			return r.data[i].Name > r.data[j].Name
-		case "revenue":
-			if ascending {
-				return r.data[i].Revenue < r.data[j].Revenue
-			}
-			return r.data[i].Revenue > r.data[j].Revenue
-		// ... другие поля
-		default:
-			return false
-		}
-	})
+	return notFound(id)
 }
 
-// Упрощаем Search
-func (r *ManufacturerRepository) Search(column, query string) []model.Manufacturer {
+// Query выполняет поиск и сортировку по любому полю модели
+func (r *ManufacturerRepository) Query(q Query) ([]model.Manufacturer, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	if q.Fields == nil {
+		q.Fields = r.schema.Fields()
+	}
+	return ApplyQuery(r.data, q)
+}
 
-	query = strings.ToLower(query)
-	var results []model.Manufacturer
+// SortBy сортирует данные репозитория по любому полю модели
+func (r *ManufacturerRepository) SortBy(column string, ascending bool) error {
+	return r.SortByKeys([]SortKey{{Field: column, Ascending: ascending}})
+}
 
-	for _, m := range r.data {
-		var field string
-		switch column {
-		case "name":
-			field = m.Name
-		case "email":
-			field = m.Email
-		case "country":
-			field = m.Country
-		case "productType":
-			field = m.ProductType
-		default:
-			continue
-		}
+// SortByKeys устойчиво сортирует данные репозитория по нескольким полям
+func (r *ManufacturerRepository) SortByKeys(keys []SortKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-		if strings.Contains(strings.ToLower(field), query) {
-			results = append(results, m)
-		}
+	sorted, err := SortManufacturers(r.data, keys, r.schema.Fields())
+	if err != nil {
+		return err
 	}
+	r.data = sorted
+	return nil
+}
+
+// Search ищет подстроку в указанном поле
+func (r *ManufacturerRepository) Search(column, query string) []model.Manufacturer {
+	results, _ := r.Query(Query{Column: column, Text: query})
 	return results
 }
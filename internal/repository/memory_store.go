@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"cursovay/internal/model"
+	"sync"
+)
+
+// MemoryStore хранит производителей только в памяти.
+// Используется для новых несохраненных баз и в тестах вместо файла.
+type MemoryStore struct {
+	data   []model.Manufacturer
+	lastID int
+	mu     sync.RWMutex
+}
+
+// NewMemoryStore создает хранилище с копией переданных данных
+func NewMemoryStore(data []model.Manufacturer) *MemoryStore {
+	s := &MemoryStore{}
+	s.data = append(s.data, data...)
+	s.lastID = EnsureUniqueIDs(s.data, 0)
+	return s
+}
+
+func (s *MemoryStore) List() ([]model.Manufacturer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]model.Manufacturer, len(s.data))
+	copy(result, s.data)
+	return result, nil
+}
+
+func (s *MemoryStore) Get(id int) (*model.Manufacturer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, m := range s.data {
+		if m.ID == id {
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) Create(m *model.Manufacturer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	m.ID = s.lastID
+
+	s.data = append(s.data, *m)
+	return nil
+}
+
+func (s *MemoryStore) Update(m *model.Manufacturer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, item := range s.data {
+		if item.ID == m.ID {
+			s.data[i] = *m
+			return nil
+		}
+	}
+	return notFound(m.ID)
+}
+
+func (s *MemoryStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, item := range s.data {
+		if item.ID == id {
+			s.data = append(s.data[:i], s.data[i+1:]...)
+			return nil
+		}
+	}
+	return notFound(id)
+}
+
+func (s *MemoryStore) Query(q Query) ([]model.Manufacturer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return ApplyQuery(s.data, q)
+}
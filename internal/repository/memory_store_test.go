@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"cursovay/internal/model"
+	"errors"
+	"testing"
+)
+
+func TestMemoryStoreCRUD(t *testing.T) {
+	s := NewMemoryStore([]model.Manufacturer{
+		{ID: 1, Name: "Alpha", Country: "RU"},
+		{ID: 5, Name: "Beta", Country: "DE"},
+	})
+
+	m := &model.Manufacturer{Name: "Gamma"}
+	if err := s.Create(m); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if m.ID != 6 {
+		t.Errorf("Create назначил ID %d, ожидался 6", m.ID)
+	}
+
+	m.Name = "Gamma Ltd"
+	if err := s.Update(m); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err := s.Get(6)
+	if err != nil || got == nil || got.Name != "Gamma Ltd" {
+		t.Fatalf("Get(6) = %v, %v", got, err)
+	}
+
+	if err := s.Delete(1); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, _ := s.Get(1); got != nil {
+		t.Errorf("запись 1 осталась после удаления: %+v", got)
+	}
+
+	// ID удаленной записи больше не выдается
+	next := &model.Manufacturer{Name: "Delta"}
+	if err := s.Create(next); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if next.ID != 7 {
+		t.Errorf("Create после удаления назначил ID %d, ожидался 7", next.ID)
+	}
+}
+
+func TestMemoryStoreNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(s *MemoryStore) error
+	}{
+		{"update", func(s *MemoryStore) error { return s.Update(&model.Manufacturer{ID: 42}) }},
+		{"delete", func(s *MemoryStore) error { return s.Delete(42) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore([]model.Manufacturer{{ID: 1, Name: "Alpha"}})
+			if err := tt.run(s); !errors.Is(err, ErrNotFound) {
+				t.Errorf("ошибка %v, ожидалась ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreListIsCopy(t *testing.T) {
+	s := NewMemoryStore([]model.Manufacturer{{ID: 1, Name: "Alpha"}})
+	list, _ := s.List()
+	list[0].Name = "changed"
+	if got, _ := s.Get(1); got.Name != "Alpha" {
+		t.Errorf("изменение результата List попало в хранилище: %q", got.Name)
+	}
+}
+
+func TestMemoryStoreQuery(t *testing.T) {
+	s := NewMemoryStore([]model.Manufacturer{
+		{ID: 1, Name: "Alpha", Country: "RU", Revenue: 10},
+		{ID: 2, Name: "Beta", Country: "DE", Revenue: 30},
+		{ID: 3, Name: "Gamma", Country: "RU", Revenue: 20},
+	})
+	tests := []struct {
+		name string
+		q    Query
+		want []int
+	}{
+		{"все", Query{}, []int{1, 2, 3}},
+		{"подстрока", Query{Text: "ET"}, []int{2}},
+		{"по названию страны", Query{Column: "country", Text: "Россия"}, []int{1, 3}},
+		{"сортировка", Query{SortBy: "revenue", Ascending: false}, []int{2, 3, 1}},
+		{"фильтр и сортировка", Query{Column: "country", Text: "ru", SortBy: "name", Ascending: false}, []int{3, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Query(tt.q)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			if ids := idsOf(got); !equalInts(ids, tt.want) {
+				t.Errorf("ID %v, ожидались %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func idsOf(data []model.Manufacturer) []int {
+	ids := make([]int, len(data))
+	for i, m := range data {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,577 @@
+package repository
+
+import (
+	"cursovay/internal/country"
+	"cursovay/internal/model"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+// Миграции схемы базы. Номер примененной миграции хранится в PRAGMA user_version,
+// поэтому новые миграции нужно только добавлять в конец списка.
+var sqliteMigrations = []string{
+	// 1: таблица производителей
+	`CREATE TABLE manufacturers (
+		id           INTEGER PRIMARY KEY,
+		name         TEXT    NOT NULL DEFAULT '',
+		country      TEXT    NOT NULL DEFAULT '',
+		address      TEXT    NOT NULL DEFAULT '',
+		phone        TEXT    NOT NULL DEFAULT '',
+		email        TEXT    NOT NULL DEFAULT '',
+		product_type TEXT    NOT NULL DEFAULT '',
+		founded_year INTEGER NOT NULL DEFAULT 0,
+		revenue      REAL    NOT NULL DEFAULT 0,
+		employees    INTEGER NOT NULL DEFAULT 0,
+		website      TEXT    NOT NULL DEFAULT ''
+	)`,
+	// 2: индексы для поиска, сортировки и группировки
+	`CREATE INDEX idx_manufacturers_name ON manufacturers(name);
+	CREATE INDEX idx_manufacturers_country ON manufacturers(country);
+	CREATE INDEX idx_manufacturers_product_type ON manufacturers(product_type);
+	CREATE INDEX idx_manufacturers_founded_year ON manufacturers(founded_year);
+	CREATE INDEX idx_manufacturers_revenue ON manufacturers(revenue)`,
+	// 3: служебные параметры файла (наибольший выданный ID и т.п.)
+	`CREATE TABLE meta (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	INSERT INTO meta (key, value) SELECT 'last_id', COALESCE(MAX(id), 0) FROM manufacturers`,
+	// 4: части адреса
+	`ALTER TABLE manufacturers ADD COLUMN street TEXT NOT NULL DEFAULT '';
+	ALTER TABLE manufacturers ADD COLUMN city TEXT NOT NULL DEFAULT '';
+	ALTER TABLE manufacturers ADD COLUMN region TEXT NOT NULL DEFAULT '';
+	ALTER TABLE manufacturers ADD COLUMN postal_code TEXT NOT NULL DEFAULT '';
+	CREATE INDEX idx_manufacturers_city ON manufacturers(city);
+	CREATE INDEX idx_manufacturers_region ON manufacturers(region)`,
+	// 5: выручка и сотрудники по годам в формате model.History
+	`ALTER TABLE manufacturers ADD COLUMN history TEXT NOT NULL DEFAULT ''`,
+	// 6: валюта выручки
+	`ALTER TABLE manufacturers ADD COLUMN currency TEXT NOT NULL DEFAULT ''`,
+	// 7: контактные лица в формате model.Contacts (JSON)
+	`ALTER TABLE manufacturers ADD COLUMN contacts TEXT NOT NULL DEFAULT ''`,
+	// 8: теги через запятую и значения пользовательских полей (JSON)
+	`ALTER TABLE manufacturers ADD COLUMN tags TEXT NOT NULL DEFAULT '';
+	ALTER TABLE manufacturers ADD COLUMN custom TEXT NOT NULL DEFAULT ''`,
+}
+
+// sqliteColumns сопоставляет ключи полей модели с колонками таблицы
+var sqliteColumns = map[string]string{
+	"id":          "id",
+	"name":        "name",
+	"country":     "country",
+	"address":     "address",
+	"phone":       "phone",
+	"email":       "email",
+	"productType": "product_type",
+	"foundedYear": "founded_year",
+	"revenue":     "revenue",
+	"employees":   "employees",
+	"website":     "website",
+	"street":      "street",
+	"city":        "city",
+	"region":      "region",
+	"postalCode":  "postal_code",
+	"history":     "history",
+	"currency":    "currency",
+	"contacts":    "contacts",
+	"tags":        "tags",
+}
+
+// Текстовые колонки сравниваются без учета регистра с поддержкой кириллицы:
+// встроенные lower() и NOCASE в SQLite работают только с ASCII
+const (
+	sqliteCollation = "unicode_nocase"
+	sqliteLower     = "unicode_lower"
+)
+
+const sqliteSelect = `SELECT id, name, country, address, phone, email, product_type,
+	founded_year, revenue, employees, website, street, city, region, postal_code, history,
+	currency, contacts, tags, custom FROM manufacturers`
+
+func init() {
+	sqlite.MustRegisterCollationUtf8(sqliteCollation, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	sqlite.MustRegisterDeterministicScalarFunction(sqliteLower, 1,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			switch v := args[0].(type) {
+			case string:
+				return strings.ToLower(v), nil
+			case []byte:
+				return strings.ToLower(string(v)), nil
+			}
+			return args[0], nil
+		})
+}
+
+// SQLiteStore хранит производителей в файле базы SQLite
+type SQLiteStore struct {
+	db   *sql.DB
+	path string
+}
+
+var (
+	_ Store      = (*SQLiteStore)(nil)
+	_ Aggregator = (*SQLiteStore)(nil)
+)
+
+// OpenSQLiteStore открывает (или создает) файл базы и применяет миграции
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы: %v", err)
+	}
+	// Один файл — одно соединение, иначе параллельные записи получат SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{db: db, path: path}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// ReadSQLiteFile читает записи из файла базы, не меняя его: файл
+// открывается только для чтения. Базу старой схемы миграции обновляют
+// во временной копии, сам файл остается прежним.
+func ReadSQLiteFile(path string) ([]model.Manufacturer, error) {
+	uri := (&url.URL{Scheme: "file", OmitHost: true, Path: path, RawQuery: "mode=ro"}).String()
+	db, err := sql.Open("sqlite", uri)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы: %v", err)
+	}
+	defer db.Close()
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return nil, fmt.Errorf("ошибка чтения версии схемы: %v", err)
+	}
+	if version >= len(sqliteMigrations) {
+		s := &SQLiteStore{db: db, path: path}
+		return s.List()
+	}
+
+	dir, err := os.MkdirTemp("", "manufacturers")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	copyPath := filepath.Join(dir, filepath.Base(path))
+	if err := copyFile(path, copyPath); err != nil {
+		return nil, err
+	}
+	s, err := OpenSQLiteStore(copyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	return s.List()
+}
+
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// Path возвращает путь к файлу базы
+func (s *SQLiteStore) Path() string {
+	return s.path
+}
+
+// Close закрывает соединение с базой
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("ошибка чтения версии схемы: %v", err)
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("ошибка миграции %d: %v", i+1, err)
+		}
+		// PRAGMA не поддерживает параметры, номер подставляем в текст
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("ошибка миграции %d: %v", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) List() ([]model.Manufacturer, error) {
+	return s.queryManufacturers(sqliteSelect + " ORDER BY id")
+}
+
+func (s *SQLiteStore) Get(id int) (*model.Manufacturer, error) {
+	result, err := s.queryManufacturers(sqliteSelect+" WHERE id = ?", id)
+	if err != nil || len(result) == 0 {
+		return nil, err
+	}
+	return &result[0], nil
+}
+
+func (s *SQLiteStore) Create(m *model.Manufacturer) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Новый ID всегда больше любого когда-либо выданного
+	if err := tx.QueryRow(`SELECT MAX(COALESCE((SELECT CAST(value AS INTEGER) FROM meta WHERE key = ?), 0),
+		COALESCE((SELECT MAX(id) FROM manufacturers), 0)) + 1`, MetaLastID).Scan(&m.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertManufacturer(tx, m); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := writeMeta(tx, Metadata{MetaLastID: strconv.Itoa(m.ID)}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Update(m *model.Manufacturer) error {
+	result, err := s.db.Exec(`UPDATE manufacturers SET name = ?, country = ?, address = ?,
+		phone = ?, email = ?, product_type = ?, founded_year = ?, revenue = ?,
+		employees = ?, website = ?, street = ?, city = ?, region = ?, postal_code = ?,
+		history = ?, currency = ?, contacts = ?, tags = ?, custom = ? WHERE id = ?`,
+		m.Name, m.Country, m.Address, m.Phone, m.Email, m.ProductType,
+		m.FoundedYear, m.Revenue, m.Employees, m.Website,
+		m.Street, m.City, m.Region, m.PostalCode, m.History.String(), m.Currency,
+		m.Contacts.String(), model.JoinList(m.Tags), model.CustomString(m.Custom), m.ID)
+	return checkAffected(result, err, m.ID)
+}
+
+func (s *SQLiteStore) Delete(id int) error {
+	result, err := s.db.Exec("DELETE FROM manufacturers WHERE id = ?", id)
+	return checkAffected(result, err, id)
+}
+
+// checkAffected возвращает ErrNotFound, если UPDATE или DELETE
+// не затронул ни одной строки
+func checkAffected(result sql.Result, err error, id int) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound(id)
+	}
+	return nil
+}
+
+// Metadata возвращает служебные параметры файла базы
+func (s *SQLiteStore) Metadata() (Metadata, error) {
+	rows, err := s.db.Query("SELECT key, value FROM meta")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	meta := Metadata{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		meta[key] = value
+	}
+	return meta, rows.Err()
+}
+
+// ReplaceAll заменяет содержимое базы и метаданные в одной транзакции
+func (s *SQLiteStore) ReplaceAll(data []model.Manufacturer, meta Metadata) error {
+	seen := make(map[int]bool, len(data))
+	for _, m := range data {
+		if seen[m.ID] {
+			return fmt.Errorf("ID %d встречается несколько раз, в базе ID должны быть уникальны", m.ID)
+		}
+		seen[m.ID] = true
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM manufacturers"); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for i := range data {
+		if err := insertManufacturer(tx, &data[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("ошибка записи производителя %d: %v", data[i].ID, err)
+		}
+	}
+	if err := writeMeta(tx, meta); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Query выполняет поиск и сортировку на стороне SQLite
+func (s *SQLiteStore) Query(q Query) ([]model.Manufacturer, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+
+	if q.Text != "" {
+		var fieldKeys []string
+		if q.Column == "" {
+			// Пользовательские поля хранятся в одной колонке JSON
+			// и в поиске по всем полям не участвуют
+			for _, f := range q.fields() {
+				if _, ok := sqliteColumns[f.Key]; ok {
+					fieldKeys = append(fieldKeys, f.Key)
+				}
+			}
+		} else {
+			f, ok := model.LookupField(q.fields(), q.Column)
+			if !ok {
+				return nil, fmt.Errorf("неизвестное поле для поиска: %s", q.Column)
+			}
+			if _, ok := sqliteColumns[f.Key]; !ok {
+				return nil, fmt.Errorf("поиск по полю %s не поддерживается базой", f.Key)
+			}
+			fieldKeys = append(fieldKeys, f.Key)
+		}
+
+		text := strings.ToLower(q.Text)
+		var conds []string
+		for _, key := range fieldKeys {
+			if key == "country" {
+				// Страна ищется и по названиям, как в ApplyQuery:
+				// "germ" и "герм" находят записи с кодом DE
+				values, err := s.countryValues(text)
+				if err != nil {
+					return nil, err
+				}
+				if len(values) == 0 {
+					continue
+				}
+				conds = append(conds, "country IN (?"+strings.Repeat(", ?", len(values)-1)+")")
+				for _, v := range values {
+					args = append(args, v)
+				}
+				continue
+			}
+			conds = append(conds, fmt.Sprintf("instr(%s, ?) > 0", sqliteTextExpr(key)))
+			args = append(args, text)
+		}
+		if len(conds) == 0 {
+			conds = append(conds, "0")
+		}
+		where = append(where, "("+strings.Join(conds, " OR ")+")")
+	}
+
+	query := sqliteSelect
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	order := "id"
+	if keys := q.sortKeys(); len(keys) > 0 {
+		var exprs []string
+		for _, key := range keys {
+			f, ok := model.LookupField(q.fields(), key.Field)
+			if !ok {
+				return nil, fmt.Errorf("неизвестный столбец для сортировки: %s", key.Field)
+			}
+			// История сравнивается по среднегодовому росту, контакты —
+			// по их числу, а пользовательские поля лежат в JSON, что в SQL
+			// не вычисляется; такую сортировку выполняет вызывающий
+			if _, ok := sqliteColumns[f.Key]; !ok || f.Key == "history" || f.Key == "contacts" {
+				return nil, fmt.Errorf("сортировка по полю %s не поддерживается базой", f.Key)
+			}
+			exprs = append(exprs, sqliteOrderExpr(f.Key, key.Ascending))
+		}
+		order = strings.Join(exprs, ", ") + ", id"
+	}
+	query += " ORDER BY " + order
+
+	return s.queryManufacturers(query, args...)
+}
+
+// countryValues возвращает значения поля страны из базы, которые
+// совпадают с text сами или кодом и названиями своей страны (country.Names).
+// Значений в поле немного, поэтому сравнение выполняется в Go.
+func (s *SQLiteStore) countryValues(text string) ([]string, error) {
+	rows, err := s.db.Query("SELECT DISTINCT country FROM manufacturers")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		for _, name := range country.Names(value) {
+			if strings.Contains(strings.ToLower(name), text) {
+				values = append(values, value)
+				break
+			}
+		}
+	}
+	return values, rows.Err()
+}
+
+// CountBy считает производителей по значениям поля средствами GROUP BY
+func (s *SQLiteStore) CountBy(column string) ([]GroupCount, error) {
+	f, ok := model.FieldByName(column)
+	if !ok {
+		return nil, fmt.Errorf("неизвестное поле для группировки: %s", column)
+	}
+	col, ok := sqliteColumns[f.Key]
+	if !ok {
+		return nil, fmt.Errorf("группировка по полю %s не поддерживается базой", f.Key)
+	}
+
+	rows, err := s.db.Query(fmt.Sprintf(
+		"SELECT %s, COUNT(*) AS n FROM manufacturers GROUP BY %s ORDER BY n DESC, %s",
+		col, col, col))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []GroupCount
+	for rows.Next() {
+		var g GroupCount
+		if err := rows.Scan(&g.Value, &g.Count); err != nil {
+			return nil, err
+		}
+		result = append(result, g)
+	}
+	return result, rows.Err()
+}
+
+// sqliteTextExpr возвращает выражение для поиска подстроки в поле:
+// текст приводится к нижнему регистру, числа — к тому же виду, что в CSV
+func sqliteTextExpr(key string) string {
+	col := sqliteColumns[key]
+	switch key {
+	case "id", "foundedYear", "employees":
+		return fmt.Sprintf("CAST(%s AS TEXT)", col)
+	case "revenue":
+		return fmt.Sprintf("printf('%%.2f', %s)", col)
+	}
+	return fmt.Sprintf("%s(%s)", sqliteLower, col)
+}
+
+// sqliteOrderExpr возвращает выражение ORDER BY для поля
+func sqliteOrderExpr(key string, ascending bool) string {
+	expr := sqliteColumns[key]
+	switch key {
+	case "id", "foundedYear", "revenue", "employees":
+	default:
+		expr += " COLLATE " + sqliteCollation
+	}
+	if ascending {
+		return expr + " ASC"
+	}
+	return expr + " DESC"
+}
+
+func (s *SQLiteStore) queryManufacturers(query string, args ...interface{}) ([]model.Manufacturer, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.Manufacturer
+	for rows.Next() {
+		var m model.Manufacturer
+		var history, contacts, tags, custom string
+		if err := rows.Scan(&m.ID, &m.Name, &m.Country, &m.Address, &m.Phone, &m.Email,
+			&m.ProductType, &m.FoundedYear, &m.Revenue, &m.Employees, &m.Website,
+			&m.Street, &m.City, &m.Region, &m.PostalCode, &history, &m.Currency, &contacts,
+			&tags, &custom); err != nil {
+			return nil, err
+		}
+		if m.History, err = model.ParseHistory(history); err != nil {
+			return nil, fmt.Errorf("запись %d: %v", m.ID, err)
+		}
+		if m.Contacts, err = model.ParseContacts(contacts); err != nil {
+			return nil, fmt.Errorf("запись %d: %v", m.ID, err)
+		}
+		if m.Custom, err = model.ParseCustom(custom); err != nil {
+			return nil, fmt.Errorf("запись %d: %v", m.ID, err)
+		}
+		m.Tags = model.SplitList(tags)
+		result = append(result, m)
+	}
+	return result, rows.Err()
+}
+
+func writeMeta(db execer, meta Metadata) error {
+	for key, value := range meta {
+		if _, err := db.Exec(`INSERT INTO meta (key, value) VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value`, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execer — общее у *sql.DB и *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertManufacturer(db execer, m *model.Manufacturer) error {
+	_, err := db.Exec(`INSERT INTO manufacturers (id, name, country, address, phone, email,
+		product_type, founded_year, revenue, employees, website, street, city, region, postal_code,
+		history, currency, contacts, tags, custom)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.ID, m.Name, m.Country, m.Address, m.Phone, m.Email, m.ProductType,
+		m.FoundedYear, m.Revenue, m.Employees, m.Website,
+		m.Street, m.City, m.Region, m.PostalCode, m.History.String(), m.Currency,
+		m.Contacts.String(), model.JoinList(m.Tags), model.CustomString(m.Custom))
+	return err
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"bytes"
+	"cursovay/internal/model"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Поиск в базе должен находить те же записи, что и ApplyQuery в памяти
+func TestSQLiteQueryMatchesApplyQuery(t *testing.T) {
+	data := []model.Manufacturer{
+		{ID: 1, Name: "Alpha", Country: "DE", City: "Berlin"},
+		{ID: 2, Name: "Beta", Country: "RU", City: "Moscow"},
+		{ID: 3, Name: "Gamma", Country: "Germany"},
+		{ID: 4, Name: "Delta", Country: "Narnia"},
+	}
+	s, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore: %v", err)
+	}
+	defer s.Close()
+	if err := s.ReplaceAll(data, nil); err != nil {
+		t.Fatalf("ReplaceAll: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+		want []int
+	}{
+		{"название страны", Query{Text: "germ"}, []int{1, 3}},
+		{"название по-русски", Query{Text: "герм"}, []int{1, 3}},
+		{"код страны", Query{Column: "country", Text: "ru"}, []int{2}},
+		{"неизвестная страна", Query{Column: "country", Text: "narn"}, []int{4}},
+		{"нет совпадений в стране", Query{Column: "country", Text: "france"}, nil},
+		{"другое поле", Query{Text: "beta"}, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Query(tt.q)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			if ids := idsOf(got); !equalInts(ids, tt.want) {
+				t.Errorf("SQLite нашла %v, ожидалось %v", ids, tt.want)
+			}
+			inMemory, err := ApplyQuery(data, tt.q)
+			if err != nil {
+				t.Fatalf("ApplyQuery: %v", err)
+			}
+			if ids := idsOf(inMemory); !equalInts(ids, tt.want) {
+				t.Errorf("ApplyQuery нашел %v, ожидалось %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+// ReadSQLiteFile читает базу старой схемы, не применяя к файлу миграции
+func TestReadSQLiteFileKeepsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		sqliteMigrations[0],
+		"INSERT INTO manufacturers (id, name, country) VALUES (1, 'Alpha', 'RU')",
+		"PRAGMA user_version = 1",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	db.Close()
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadSQLiteFile(path)
+	if err != nil {
+		t.Fatalf("ReadSQLiteFile: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "Alpha" {
+		t.Errorf("прочитано %+v", got)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Error("файл базы изменился при чтении")
+	}
+
+	s, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore: %v", err)
+	}
+	defer s.Close()
+	if got, err := ReadSQLiteFile(path); err != nil || len(got) != 1 {
+		t.Errorf("база текущей схемы: %+v, %v", got, err)
+	}
+}
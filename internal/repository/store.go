@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"cursovay/internal/country"
+	"cursovay/internal/model"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Store — хранилище производителей. Сервис и контроллер работают только
+// через этот интерфейс, поэтому CSV-файл можно заменить другой реализацией
+// (например, MemoryStore в тестах).
+type Store interface {
+	// List возвращает всех производителей в порядке хранения
+	List() ([]model.Manufacturer, error)
+	// Get возвращает производителя по ID или nil, если его нет
+	Get(id int) (*model.Manufacturer, error)
+	// Create назначает новый ID и добавляет производителя
+	Create(m *model.Manufacturer) error
+	// Update заменяет производителя с тем же ID; ErrNotFound, если его нет
+	Update(m *model.Manufacturer) error
+	// Delete удаляет производителя по ID; ErrNotFound, если его нет
+	Delete(id int) error
+	// Query возвращает отфильтрованную и отсортированную выборку
+	Query(q Query) ([]model.Manufacturer, error)
+}
+
+// ErrNotFound — в хранилище нет производителя с указанным ID
+var ErrNotFound = errors.New("производитель не найден")
+
+// notFound возвращает ErrNotFound с ID записи в тексте ошибки
+func notFound(id int) error {
+	return fmt.Errorf("%w: ID %d", ErrNotFound, id)
+}
+
+// Aggregator реализуют хранилища, которые умеют группировать данные сами
+// (например, через GROUP BY), не загружая все записи в память
+type Aggregator interface {
+	CountBy(column string) ([]GroupCount, error)
+}
+
+// GroupCount — количество производителей с одинаковым значением поля
+type GroupCount struct {
+	Value string
+	Count int
+}
+
+// Query описывает выборку из хранилища
+type Query struct {
+	Column    string // поле для поиска (ключ model.Field); пусто — все поля
+	Text      string // подстрока без учета регистра; пусто — без фильтра
+	SortBy    string // поле для сортировки; пусто — порядок хранения
+	Ascending bool
+	Sort      []SortKey // дополнительные ключи сортировки после SortBy
+
+	// Fields — поля файла (model.Schema.Fields), по которым ищут
+	// и сортируют; nil — только встроенные поля
+	Fields []model.Field
+}
+
+// fields возвращает поля файла, к которым относится запрос
+func (q Query) fields() []model.Field {
+	if q.Fields == nil {
+		return model.BuiltinFields()
+	}
+	return q.Fields
+}
+
+// SortKey — один ключ многоуровневой сортировки
+type SortKey struct {
+	Field     string
+	Ascending bool
+}
+
+// sortKeys возвращает все ключи сортировки запроса по порядку
+func (q Query) sortKeys() []SortKey {
+	if q.SortBy == "" {
+		return q.Sort
+	}
+	return append([]SortKey{{Field: q.SortBy, Ascending: q.Ascending}}, q.Sort...)
+}
+
+// ParseSortKeys разбирает список ключей вида "country,-revenue":
+// минус перед полем — сортировка по убыванию. Поля ищутся среди fields.
+func ParseSortKeys(spec string, fields []model.Field) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key := SortKey{Field: part, Ascending: true}
+		switch part[0] {
+		case '-':
+			key = SortKey{Field: part[1:], Ascending: false}
+		case '+':
+			key.Field = part[1:]
+		}
+		f, ok := model.LookupField(fields, key.Field)
+		if !ok {
+			return nil, fmt.Errorf("неизвестный столбец для сортировки: %s", key.Field)
+		}
+		key.Field = f.Key
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("не указаны столбцы для сортировки")
+	}
+	return keys, nil
+}
+
+// SortManufacturers возвращает копию данных, устойчиво отсортированную
+// по ключам: при равенстве первого ключа сравнивается второй и так далее,
+// а при равенстве всех ключей сохраняется исходный порядок.
+// Ключи ищутся среди полей файла fileFields.
+func SortManufacturers(data []model.Manufacturer, keys []SortKey, fileFields []model.Field) ([]model.Manufacturer, error) {
+	fields := make([]model.Field, len(keys))
+	for i, key := range keys {
+		f, ok := model.LookupField(fileFields, key.Field)
+		if !ok {
+			return nil, fmt.Errorf("неизвестный столбец для сортировки: %s", key.Field)
+		}
+		fields[i] = f
+	}
+
+	sorted := make([]model.Manufacturer, len(data))
+	copy(sorted, data)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for k, f := range fields {
+			c := f.Compare(&sorted[i], &sorted[j])
+			if c == 0 {
+				continue
+			}
+			if keys[k].Ascending {
+				return c < 0
+			}
+			return c > 0
+		}
+		return false
+	})
+	return sorted, nil
+}
+
+// ApplyQuery выполняет запрос над срезом в памяти. Исходный срез не меняется.
+func ApplyQuery(data []model.Manufacturer, q Query) ([]model.Manufacturer, error) {
+	fields := q.fields()
+	if q.Column != "" {
+		f, ok := model.LookupField(fields, q.Column)
+		if !ok {
+			return nil, fmt.Errorf("неизвестное поле для поиска: %s", q.Column)
+		}
+		fields = []model.Field{f}
+	}
+
+	text := strings.ToLower(q.Text)
+	result := make([]model.Manufacturer, 0, len(data))
+	for i := range data {
+		if text == "" || matchesText(&data[i], fields, text) {
+			result = append(result, data[i])
+		}
+	}
+
+	if keys := q.sortKeys(); len(keys) > 0 {
+		return SortManufacturers(result, keys, q.fields())
+	}
+
+	return result, nil
+}
+
+// matchesText ищет подстроку в полях. Страна ищется и по названиям:
+// запись с кодом RU находится по тексту "Россия" и "Russia".
+func matchesText(m *model.Manufacturer, fields []model.Field, text string) bool {
+	for _, f := range fields {
+		values := []string{f.Get(m)}
+		if f.Key == "country" {
+			values = country.Names(values[0])
+		}
+		for _, v := range values {
+			if strings.Contains(strings.ToLower(v), text) {
+				return true
+			}
+		}
+	}
+	return false
+}
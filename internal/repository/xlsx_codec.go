@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"cursovay/internal/model"
+	"io"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXSheet — имя листа при экспорте в Excel
+const XLSXSheet = "Manufacturers"
+
+// WriteXLSX записывает производителей в книгу Excel с колонками fields:
+// числовые поля — числами, строка заголовка закреплена и снабжена автофильтром
+func WriteXLSX(w io.Writer, manufacturers []model.Manufacturer, fields []model.Field) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName(f.GetSheetName(0), XLSXSheet); err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(fields))
+	for i, field := range fields {
+		header[i] = field.Header
+	}
+	if err := f.SetSheetRow(XLSXSheet, "A1", &header); err != nil {
+		return err
+	}
+
+	for i := range manufacturers {
+		row := make([]interface{}, len(fields))
+		for j, field := range fields {
+			row[j] = cellValue(field, &manufacturers[i])
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(XLSXSheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	if err := styleXLSX(f, len(manufacturers), fields); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+// cellValue возвращает число для числовых полей и строку для остальных
+func cellValue(field model.Field, m *model.Manufacturer) interface{} {
+	value := field.Get(m)
+	if !field.Numeric {
+		return value
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		return n
+	}
+	return value
+}
+
+// styleXLSX выделяет и закрепляет заголовок, включает автофильтр
+// и задает формат выручки
+func styleXLSX(f *excelize.File, rows int, fields []model.Field) error {
+	last, err := excelize.CoordinatesToCellName(len(fields), rows+1)
+	if err != nil {
+		return err
+	}
+	lastHeader, err := excelize.CoordinatesToCellName(len(fields), 1)
+	if err != nil {
+		return err
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(XLSXSheet, "A1", lastHeader, bold); err != nil {
+		return err
+	}
+
+	if err := f.SetPanes(XLSXSheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+	if err := f.AutoFilter(XLSXSheet, "A1:"+last, nil); err != nil {
+		return err
+	}
+
+	money, err := f.NewStyle(&excelize.Style{NumFmt: 4}) // #,##0.00
+	if err != nil {
+		return err
+	}
+	for i, field := range fields {
+		column, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		if field.Key == "revenue" && rows > 0 {
+			if err := f.SetCellStyle(XLSXSheet, column+"2", column+strconv.Itoa(rows+1), money); err != nil {
+				return err
+			}
+		}
+		width := 14.0
+		if !field.Numeric {
+			width = 24
+		}
+		if err := f.SetColWidth(XLSXSheet, column, column, width); err != nil {
+			return err
+		}
+	}
+	return nil
+}
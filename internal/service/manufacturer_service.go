@@ -4,54 +4,83 @@ import (
 	"bytes"
 	"cursovay/internal/model"
 	"cursovay/internal/repository"
+	"cursovay/internal/validation"
 	"html/template"
 	"os/exec"
 )
 
+// ManufacturerService содержит бизнес-логику поверх любого хранилища
 type ManufacturerService struct {
-	repo *repository.ManufacturerRepository
+	store  repository.Store
+	rules  *validation.RuleSet
+	fields []model.Field
 }
 
-func NewManufacturerService(repo *repository.ManufacturerRepository) *ManufacturerService {
+func NewManufacturerService(store repository.Store) *ManufacturerService {
 	return &ManufacturerService{
-		repo: repo,
+		store:  store,
+		rules:  validation.Default(),
+		fields: model.Schema(nil).Fields(),
 	}
 }
 
+// SetRules задает правила проверки записей и поля файла
+// (model.Schema.Fields); nil возвращает правила по умолчанию
+func (s *ManufacturerService) SetRules(rules *validation.RuleSet, fields []model.Field) {
+	if rules == nil {
+		rules = validation.Default()
+	}
+	s.rules = rules
+	s.fields = fields
+}
+
 func (s *ManufacturerService) GetAll() ([]model.Manufacturer, error) {
 	// В реальной реализации здесь должна быть бизнес-логика
-	// Пока просто возвращаем данные из репозитория
-	return s.repo.GetAll()
+	// Пока просто возвращаем данные из хранилища
+	return s.store.List()
 }
 
 func (s *ManufacturerService) GetByID(id int) (*model.Manufacturer, error) {
-	// Реализация получения по ID
-	return nil, nil
+	return s.store.Get(id)
+}
+
+func (s *ManufacturerService) Query(q repository.Query) ([]model.Manufacturer, error) {
+	return s.store.Query(q)
 }
 
 func (s *ManufacturerService) Create(manufacturer *model.Manufacturer) error {
 	// Валидация и создание
-	if err := manufacturer.Validate(); err != nil {
+	if err := s.check(manufacturer); err != nil {
 		return err
 	}
-	return s.repo.Create(manufacturer)
+	return s.store.Create(manufacturer)
 }
 
 func (s *ManufacturerService) Update(manufacturer *model.Manufacturer) error {
 	// Валидация и обновление
-	if err := manufacturer.Validate(); err != nil {
+	if err := s.check(manufacturer); err != nil {
+		return err
+	}
+	return s.store.Update(manufacturer)
+}
+
+// check возвращает ошибки проверки записи по правилам как
+// validation.Violations; уникальные поля сверяются с записями хранилища
+func (s *ManufacturerService) check(manufacturer *model.Manufacturer) error {
+	existing, err := s.store.List()
+	if err != nil {
 		return err
 	}
-	return s.repo.Update(manufacturer)
+	return s.rules.NewChecker(existing, s.fields).Check(manufacturer).Err()
 }
 
 func (s *ManufacturerService) Delete(id int) error {
 	// Удаление
-	return s.repo.Delete(id)
+	return s.store.Delete(id)
 }
 
 func (s *ManufacturerService) ExportToPDF(filePath string) error {
-	data, err := s.repo.GetAll()
+	data, err := s.store.List()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"cursovay/internal/model"
+	"cursovay/internal/repository"
+	"cursovay/internal/validation"
+	"errors"
+	"testing"
+)
+
+func TestServiceWithMemoryStore(t *testing.T) {
+	s := NewManufacturerService(repository.NewMemoryStore([]model.Manufacturer{
+		{ID: 1, Name: "Alpha", Country: "RU"},
+	}))
+
+	m := &model.Manufacturer{Name: "Beta", Country: "DE", FoundedYear: 2001}
+	if err := s.Create(m); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	all, err := s.GetAll()
+	if err != nil || len(all) != 2 {
+		t.Fatalf("GetAll = %d записей, %v", len(all), err)
+	}
+
+	found, err := s.Query(repository.Query{Column: "country", Text: "de"})
+	if err != nil || len(found) != 1 || found[0].ID != m.ID {
+		t.Fatalf("Query = %+v, %v", found, err)
+	}
+
+	if err := s.Delete(m.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Update(m); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Update удаленной записи: %v, ожидалась ErrNotFound", err)
+	}
+}
+
+func TestServiceValidates(t *testing.T) {
+	s := NewManufacturerService(repository.NewMemoryStore([]model.Manufacturer{
+		{ID: 1, Name: "Alpha", Country: "RU", FoundedYear: 1990},
+	}))
+
+	var violations validation.Violations
+	if err := s.Create(&model.Manufacturer{Country: "DE", FoundedYear: 2001}); !errors.As(err, &violations) {
+		t.Errorf("Create записи без названия: %v", err)
+	}
+	if all, _ := s.GetAll(); len(all) != 1 {
+		t.Errorf("неверная запись сохранена: %+v", all)
+	}
+
+	if err := s.Update(&model.Manufacturer{ID: 1, Name: "Alpha", FoundedYear: 1700}); !errors.As(err, &violations) {
+		t.Errorf("Update с неверным годом: %v", err)
+	}
+	if m, _ := s.GetByID(1); m.FoundedYear != 1990 {
+		t.Errorf("неверное изменение сохранено: %+v", m)
+	}
+}
@@ -0,0 +1,287 @@
+package validation
+
+import (
+	"cursovay/internal/country"
+	"cursovay/internal/currency"
+	"cursovay/internal/model"
+	"cursovay/internal/phone"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Violation — нарушение одного правила
+type Violation struct {
+	ID       int      `json:"id,omitempty"`
+	Field    string   `json:"field"`
+	Value    string   `json:"value,omitempty"`
+	Check    string   `json:"check"` // required, pattern, range, enum, unique, phone, country, currency или contacts
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (v Violation) String() string {
+	return v.Field + ": " + v.Message
+}
+
+// Violations — все нарушения записи или файла. Реализует error,
+// чтобы ошибки проверки можно было вернуть из контроллера.
+type Violations []Violation
+
+func (vs Violations) Error() string {
+	messages := make([]string, len(vs))
+	for i, v := range vs {
+		messages[i] = v.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Errors возвращает только нарушения с серьезностью error
+func (vs Violations) Errors() Violations {
+	return vs.filter(Error)
+}
+
+// Warnings возвращает только предупреждения
+func (vs Violations) Warnings() Violations {
+	return vs.filter(Warning)
+}
+
+// Err возвращает ошибки как error или nil, если ошибок нет
+func (vs Violations) Err() error {
+	if errs := vs.Errors(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (vs Violations) filter(s Severity) Violations {
+	var result Violations
+	for _, v := range vs {
+		if v.Severity == s {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Checker проверяет записи по правилам и помнит значения уникальных
+// полей уже проверенных записей
+type Checker struct {
+	rules []fieldRule
+	seen  map[string]map[string][]int // поле -> значение -> ID записей с ним
+}
+
+// fieldRule — правило вместе с полем файла, к которому оно относится
+type fieldRule struct {
+	*Rule
+	field model.Field
+}
+
+// NewChecker создает проверку записей файла с полями fields
+// (model.Schema.Fields). Поля правил ищутся среди fields; правила
+// для полей, которых в файле нет, не применяются. existing — уже
+// сохраненные записи: с ними сравниваются уникальные поля.
+func (rs *RuleSet) NewChecker(existing []model.Manufacturer, fields []model.Field) *Checker {
+	c := &Checker{seen: make(map[string]map[string][]int)}
+	for i := range rs.Rules {
+		if field, ok := model.LookupField(fields, rs.Rules[i].Field); ok {
+			c.rules = append(c.rules, fieldRule{Rule: &rs.Rules[i], field: field})
+		}
+	}
+	for i := range existing {
+		c.Add(&existing[i])
+	}
+	return c
+}
+
+// Check возвращает все нарушения записи. Запись не сравнивается сама
+// с собой: при редактировании ее прежняя версия не считается повтором.
+func (c *Checker) Check(m *model.Manufacturer) Violations {
+	var vs Violations
+	for _, r := range c.rules {
+		value := strings.TrimSpace(r.field.Get(m))
+		empty := isEmpty(r.field, value)
+
+		reportValue := func(check, value, message string) {
+			if r.Message != "" {
+				message = r.Message
+			}
+			vs = append(vs, Violation{
+				ID: m.ID, Field: r.field.Key, Value: value,
+				Check: check, Severity: r.Severity, Message: message,
+			})
+		}
+		report := func(check, message string) { reportValue(check, value, message) }
+
+		if empty {
+			if r.Required {
+				report("required", "обязательное поле")
+			}
+			continue
+		}
+		if r.pattern != nil && !r.pattern.MatchString(value) {
+			report("pattern", "неверный формат")
+		}
+		if r.low != nil || r.high != nil {
+			n, err := strconv.ParseFloat(value, 64)
+			switch {
+			case err != nil:
+				report("range", "ожидается число")
+			case r.low != nil && n < *r.low, r.high != nil && n > *r.high:
+				report("range", "вне диапазона "+rangeText(r.low, r.high))
+			}
+		}
+		if len(r.Enum) > 0 {
+			// В полях-списках проверяется каждое значение
+			items := []string{value}
+			if r.field.List {
+				items = model.SplitList(value)
+			}
+			for _, item := range items {
+				if !inEnum(r.Enum, item) {
+					reportValue("enum", item, "допустимые значения: "+strings.Join(r.Enum, ", "))
+				}
+			}
+		}
+		// Для поля контактов проверяются телефоны каждого контакта
+		if r.Phone && r.field.Key == "contacts" {
+			for i, contact := range m.Contacts {
+				if contact.Phone == "" {
+					continue
+				}
+				if _, err := phone.Parse(contact.Phone, m.Country); err != nil {
+					reportValue("phone", contact.Phone, contactMessage(i, contact, err.Error()))
+				}
+			}
+		} else if r.Phone {
+			if _, err := phone.Parse(value, m.Country); err != nil {
+				report("phone", err.Error())
+			}
+		}
+		if r.Country {
+			if _, ok := country.Lookup(value); !ok {
+				report("country", "страна не найдена в справочнике ISO 3166")
+			}
+		}
+		if r.Currency {
+			if code, ok := currency.Normalize(value); !ok || code != value {
+				report("currency", "ожидается код валюты ISO 4217 (RUB, EUR, USD)")
+			}
+		}
+		if r.Contacts {
+			for i, contact := range m.Contacts {
+				for _, problem := range contactProblems(contact) {
+					reportValue("contacts", contact.Name, contactMessage(i, contact, problem))
+				}
+			}
+		}
+		if r.Unique {
+			for _, id := range c.seen[r.field.Key][strings.ToLower(value)] {
+				if id != m.ID {
+					report("unique", fmt.Sprintf("значение уже есть у записи %d", id))
+					break
+				}
+			}
+		}
+	}
+	return vs
+}
+
+// contactEmail — допустимый email контакта
+var contactEmail = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// contactProblems перечисляет ошибки заполнения контакта
+func contactProblems(c model.Contact) []string {
+	var problems []string
+	if c.Name == "" {
+		problems = append(problems, "не указано имя")
+	}
+	if c.Phone == "" && c.Email == "" {
+		problems = append(problems, "нужен телефон или email")
+	}
+	if c.Email != "" && !contactEmail.MatchString(c.Email) {
+		problems = append(problems, "неверный email "+c.Email)
+	}
+	return problems
+}
+
+// contactMessage подписывает сообщение номером и именем контакта
+func contactMessage(i int, c model.Contact, message string) string {
+	if c.Name == "" {
+		return fmt.Sprintf("контакт %d: %s", i+1, message)
+	}
+	return fmt.Sprintf("контакт %d (%s): %s", i+1, c.Name, message)
+}
+
+// Add запоминает значения уникальных полей записи
+func (c *Checker) Add(m *model.Manufacturer) {
+	for _, r := range c.rules {
+		if !r.Unique {
+			continue
+		}
+		value := strings.TrimSpace(r.field.Get(m))
+		if isEmpty(r.field, value) {
+			continue
+		}
+		if c.seen[r.field.Key] == nil {
+			c.seen[r.field.Key] = make(map[string][]int)
+		}
+		key := strings.ToLower(value)
+		if ids := c.seen[r.field.Key][key]; !hasID(ids, m.ID) {
+			c.seen[r.field.Key][key] = append(ids, m.ID)
+		}
+	}
+}
+
+func hasID(ids []int, id int) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAll проверяет все записи файла с полями fields, включая
+// уникальность внутри списка
+func (rs *RuleSet) CheckAll(data []model.Manufacturer, fields []model.Field) Violations {
+	c := rs.NewChecker(nil, fields)
+	var vs Violations
+	for i := range data {
+		vs = append(vs, c.Check(&data[i])...)
+		c.Add(&data[i])
+	}
+	return vs
+}
+
+// isEmpty считает пустыми числовые поля, равные нулю
+func isEmpty(f model.Field, value string) bool {
+	if value == "" {
+		return true
+	}
+	if f.Numeric {
+		n, err := strconv.ParseFloat(value, 64)
+		return err == nil && n == 0
+	}
+	return false
+}
+
+func inEnum(enum []string, value string) bool {
+	for _, e := range enum {
+		if strings.EqualFold(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func rangeText(low, high *float64) string {
+	format := func(n *float64) string {
+		if n == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*n, 'f', -1, 64)
+	}
+	return format(low) + ".." + format(high)
+}
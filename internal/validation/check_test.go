@@ -0,0 +1,168 @@
+package validation
+
+import (
+	"cursovay/internal/model"
+	"reflect"
+	"testing"
+)
+
+// checks записывает нарушения как поле/проверка/серьезность по порядку
+func checks(vs Violations) []string {
+	var result []string
+	for _, v := range vs {
+		result = append(result, v.Field+"/"+v.Check+"/"+string(v.Severity))
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"правила по умолчанию", `{"rules": [{"field": "name", "required": true}]}`, false},
+		{"пользовательское поле", `{"rules": [{"field": "grade", "range": "1..5"}]}`, false},
+		{"заголовок вместо ключа", `{"rules": [{"field": "FoundedYear", "range": "1800.."}]}`, false},
+		{"неверный JSON", `{"rules": [`, true},
+		{"неизвестное поле", `{"rules": [{"field": "Год основания!"}]}`, true},
+		{"неверная серьезность", `{"rules": [{"field": "name", "severity": "fatal"}]}`, true},
+		{"неверное выражение", `{"rules": [{"field": "name", "pattern": "("}]}`, true},
+		{"диапазон без границ", `{"rules": [{"field": "revenue", "range": ".."}]}`, true},
+		{"граница не число", `{"rules": [{"field": "revenue", "range": "0..много"}]}`, true},
+	}
+	for _, tt := range tests {
+		if _, err := Parse([]byte(tt.data)); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ошибка %v, ожидалась %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestChecker(t *testing.T) {
+	rules, err := Parse([]byte(`{"rules": [
+		{"field": "name", "required": true},
+		{"field": "foundedYear", "required": true, "range": "1800..now"},
+		{"field": "email", "pattern": "^[^@\\s]+@[^@\\s]+$"},
+		{"field": "productType", "enum": ["Cement", "Dye"], "severity": "warning"},
+		{"field": "tags", "enum": ["paint", "glue"], "message": "неизвестный тег"},
+		{"field": "phone", "phone": true, "severity": "warning"},
+		{"field": "country", "country": true, "severity": "warning"},
+		{"field": "currency", "currency": true},
+		{"field": "contacts", "contacts": true}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	fields := model.Schema(nil).Fields()
+	valid := model.Manufacturer{ID: 1, Name: "Alpha", FoundedYear: 1990, Country: "RU", ProductType: "dye"}
+
+	tests := []struct {
+		name   string
+		change func(m *model.Manufacturer)
+		want   []string
+	}{
+		{"верная запись", func(m *model.Manufacturer) {}, nil},
+		{"пустое имя", func(m *model.Manufacturer) { m.Name = " " }, []string{"name/required/error"}},
+		{"год вне диапазона", func(m *model.Manufacturer) { m.FoundedYear = 1700 }, []string{"foundedYear/range/error"}},
+		{"нулевой год — пустое значение", func(m *model.Manufacturer) { m.FoundedYear = 0 }, []string{"foundedYear/required/error"}},
+		{"неверный email", func(m *model.Manufacturer) { m.Email = "alpha" }, []string{"email/pattern/error"}},
+		{"значение не из списка", func(m *model.Manufacturer) { m.ProductType = "Ink" }, []string{"productType/enum/warning"}},
+		{"каждый тег из списка", func(m *model.Manufacturer) { m.Tags = []string{"Paint", "wood", "oil"} }, []string{"tags/enum/error", "tags/enum/error"}},
+		{"неверный телефон", func(m *model.Manufacturer) { m.Phone = "12" }, []string{"phone/phone/warning"}},
+		{"страна не из справочника", func(m *model.Manufacturer) { m.Country = "Атлантида" }, []string{"country/country/warning"}},
+		{"неверная валюта", func(m *model.Manufacturer) { m.Currency = "usd" }, []string{"currency/currency/error"}},
+		{"контакт без способа связи", func(m *model.Manufacturer) { m.Contacts = model.Contacts{{Name: "Ivan"}} }, []string{"contacts/contacts/error"}},
+	}
+	for _, tt := range tests {
+		m := valid
+		tt.change(&m)
+		got := rules.NewChecker(nil, fields).Check(&m)
+		if !reflect.DeepEqual(checks(got), tt.want) {
+			t.Errorf("%s: нарушения %v, ожидались %v", tt.name, checks(got), tt.want)
+		}
+		if (got.Err() != nil) != (len(got.Errors()) > 0) {
+			t.Errorf("%s: Err() = %v при ошибках %v", tt.name, got.Err(), got.Errors())
+		}
+	}
+
+	m := valid
+	m.Tags = []string{"wood"}
+	if got := rules.NewChecker(nil, fields).Check(&m); len(got) != 1 || got[0].Value != "wood" || got[0].Message != "неизвестный тег" {
+		t.Errorf("нарушение тега %+v", got)
+	}
+}
+
+func TestCheckerUnique(t *testing.T) {
+	rules, err := Parse([]byte(`{"rules": [{"field": "email", "unique": true, "severity": "warning"}]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	fields := model.Schema(nil).Fields()
+	existing := []model.Manufacturer{{ID: 1, Email: "a@example.com"}, {ID: 2}}
+
+	tests := []struct {
+		name string
+		m    model.Manufacturer
+		want []string
+	}{
+		{"повтор без учета регистра", model.Manufacturer{ID: 3, Email: "A@Example.com"}, []string{"email/unique/warning"}},
+		{"прежняя версия той же записи", model.Manufacturer{ID: 1, Email: "a@example.com"}, nil},
+		{"пустое значение не повтор", model.Manufacturer{ID: 4}, nil},
+		{"новое значение", model.Manufacturer{ID: 5, Email: "b@example.com"}, nil},
+	}
+	for _, tt := range tests {
+		got := rules.NewChecker(existing, fields).Check(&tt.m)
+		if !reflect.DeepEqual(checks(got), tt.want) {
+			t.Errorf("%s: нарушения %v, ожидались %v", tt.name, checks(got), tt.want)
+		}
+	}
+
+	// Значение, повторенное в нескольких записях, остается повтором и
+	// для первой из них
+	repeated := []model.Manufacturer{{ID: 1, Email: "x@example.com"}, {ID: 2, Email: "x@example.com"}}
+	m := model.Manufacturer{ID: 1, Email: "x@example.com"}
+	if got := rules.NewChecker(repeated, fields).Check(&m); len(got) != 1 || got[0].Message != "значение уже есть у записи 2" {
+		t.Errorf("повтор в нескольких записях: %+v", got)
+	}
+
+	data := []model.Manufacturer{{ID: 1, Email: "x@example.com"}, {ID: 2, Email: "X@example.com"}, {ID: 3, Email: "y@example.com"}}
+	if got := rules.CheckAll(data, fields); len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("CheckAll: %+v", got)
+	}
+}
+
+// Правило для пользовательского поля находится среди полей файла при
+// проверке: в файле без такого поля оно не применяется
+func TestCheckerCustomField(t *testing.T) {
+	rules, err := Parse([]byte(`{"rules": [
+		{"field": "grade", "required": true, "range": "1..5"},
+		{"field": "status", "enum": ["новый", "проверен"]}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	schema := model.Schema{
+		{Key: "grade", Type: model.TypeNumber},
+		{Key: "status", Name: "Статус", Type: model.TypeText},
+	}
+
+	tests := []struct {
+		name   string
+		fields []model.Field
+		custom map[string]string
+		want   []string
+	}{
+		{"верные значения", schema.Fields(), map[string]string{"grade": "3", "status": "новый"}, nil},
+		{"пустое обязательное поле", schema.Fields(), nil, []string{"grade/required/error"}},
+		{"вне диапазона", schema.Fields(), map[string]string{"grade": "7"}, []string{"grade/range/error"}},
+		{"значение не из списка", schema.Fields(), map[string]string{"grade": "1", "status": "старый"}, []string{"status/enum/error"}},
+		{"в файле нет полей", model.Schema(nil).Fields(), map[string]string{"grade": "7"}, nil},
+	}
+	for _, tt := range tests {
+		m := model.Manufacturer{ID: 1, Custom: tt.custom}
+		got := rules.NewChecker(nil, tt.fields).Check(&m)
+		if !reflect.DeepEqual(checks(got), tt.want) {
+			t.Errorf("%s: нарушения %v, ожидались %v", tt.name, checks(got), tt.want)
+		}
+	}
+}
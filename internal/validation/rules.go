@@ -0,0 +1,175 @@
+// Package validation проверяет записи по правилам из файла настроек
+// вместо жестко заданного Manufacturer.Validate. Для каждого поля можно
+// задать обязательность, регулярное выражение, диапазон, список
+// допустимых значений и уникальность; нарушения бывают ошибками
+// и предупреждениями и возвращаются все сразу.
+//
+// Пример файла правил:
+//
+//	{"rules": [
+//	    {"field": "name", "required": true},
+//	    {"field": "foundedYear", "required": true, "range": "1800..now"},
+//	    {"field": "email", "pattern": "^[^@\\s]+@[^@\\s]+\\.[a-z]{2,}$"},
+//	    {"field": "email", "unique": true, "severity": "warning"},
+//	    {"field": "phone", "phone": true, "severity": "warning"},
+//	    {"field": "country", "country": true, "severity": "warning"},
+//	    {"field": "currency", "currency": true, "severity": "warning"},
+//	    {"field": "contacts", "contacts": true},
+//	    {"field": "contacts", "phone": true, "severity": "warning"},
+//	    {"field": "productType", "enum": ["Cement", "Dye"], "severity": "warning"}
+//	]}
+package validation
+
+import (
+	"cursovay/internal/model"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity — серьезность нарушения
+type Severity string
+
+const (
+	// Error — запись нельзя сохранить
+	Error Severity = "error"
+	// Warning — запись сохраняется, но пользователь видит предупреждение
+	Warning Severity = "warning"
+)
+
+// Rule — проверки одного поля. Правило может сочетать несколько проверок.
+// Pattern, Range и Enum не применяются к пустым значениям: пустое значение
+// запрещает только Required.
+type Rule struct {
+	Field    string   `json:"field"`
+	Required bool     `json:"required,omitempty"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Range    string   `json:"range,omitempty"` // "A..B", "A.." или "..B"; now — текущий год
+	Enum     []string `json:"enum,omitempty"`
+	Unique   bool     `json:"unique,omitempty"`
+	Phone    bool     `json:"phone,omitempty"`    // телефон, разбираемый по стране записи
+	Country  bool     `json:"country,omitempty"`  // страна из справочника ISO 3166
+	Currency bool     `json:"currency,omitempty"` // код валюты ISO 4217
+	Contacts bool     `json:"contacts,omitempty"` // у каждого контакта есть имя и верный email или телефон
+	Severity Severity `json:"severity,omitempty"` // по умолчанию error
+	Message  string   `json:"message,omitempty"`  // текст вместо стандартного
+
+	pattern   *regexp.Regexp
+	low, high *float64
+}
+
+// RuleSet — набор правил из файла настроек
+type RuleSet struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Default возвращает правила, совпадающие с прежним Manufacturer.Validate,
+// за исключением email: он проверяется, только если указан, а повтор
+// email считается предупреждением. Неверный телефон, страна не из
+// справочника и неверный код валюты — тоже предупреждения. Контакт без
+// имени или без способа связи — ошибка, неверный телефон контакта —
+// предупреждение.
+func Default() *RuleSet {
+	rs := &RuleSet{Rules: []Rule{
+		{Field: "name", Required: true},
+		{Field: "foundedYear", Required: true, Range: "1800..now"},
+		{Field: "revenue", Range: "0.."},
+		{Field: "email", Pattern: `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`},
+		{Field: "email", Unique: true, Severity: Warning},
+		{Field: "phone", Phone: true, Severity: Warning},
+		{Field: "country", Country: true, Severity: Warning},
+		{Field: "currency", Currency: true, Severity: Warning},
+		{Field: "contacts", Contacts: true},
+		{Field: "contacts", Phone: true, Severity: Warning},
+	}}
+	if err := rs.compile(); err != nil {
+		panic(err)
+	}
+	return rs
+}
+
+// Load читает правила из JSON-файла и проверяет их
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse разбирает правила из JSON
+func Parse(data []byte) (*RuleSet, error) {
+	var rs RuleSet
+	if err := json.Unmarshal(data, &rs); err != nil {
+		return nil, fmt.Errorf("неверный файл правил: %v", err)
+	}
+	if err := rs.compile(); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// compile проверяет правила и готовит их к применению. Поле правила
+// здесь только проверяется: встроенное поле или ключ пользовательского
+// поля. Само поле находится при проверке среди полей файла (NewChecker),
+// потому что пользовательские поля у каждого файла свои.
+func (rs *RuleSet) compile() error {
+	for i := range rs.Rules {
+		r := &rs.Rules[i]
+		if _, ok := model.FieldByName(r.Field); !ok && !model.IsCustomKey(r.Field) {
+			return fmt.Errorf("правило %d: неизвестное поле %q", i+1, r.Field)
+		}
+
+		switch r.Severity {
+		case "":
+			r.Severity = Error
+		case Error, Warning:
+		default:
+			return fmt.Errorf("правило %d: серьезность должна быть error или warning, а не %q", i+1, r.Severity)
+		}
+
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return fmt.Errorf("правило %d: неверное регулярное выражение: %v", i+1, err)
+			}
+			r.pattern = re
+		}
+
+		if r.Range != "" {
+			low, high, ok := strings.Cut(r.Range, "..")
+			if !ok || (low == "" && high == "") {
+				return fmt.Errorf("правило %d: диапазон задается как A..B, A.. или ..B", i+1)
+			}
+			var err error
+			if r.low, err = parseBound(low); err != nil {
+				return fmt.Errorf("правило %d: %v", i+1, err)
+			}
+			if r.high, err = parseBound(high); err != nil {
+				return fmt.Errorf("правило %d: %v", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseBound разбирает границу диапазона; now — текущий год
+func parseBound(s string) (*float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if strings.EqualFold(s, "now") {
+		year := float64(time.Now().Year())
+		return &year, nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("граница диапазона %q не число", s)
+	}
+	return &n, nil
+}
@@ -0,0 +1,133 @@
+package view
+
+import (
+	"cursovay/internal/model"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/storage"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+)
+
+// contactRow — строка таблицы контактов
+type contactRow struct {
+	name, role, email, notes *widget.Entry
+	phone                    *phoneEntry
+}
+
+// contactsEditor — таблица контактных лиц в окне редактирования
+type contactsEditor struct {
+	mw   *MainWindow
+	rows []*contactRow
+	grid *fyne.Container
+
+	country func() string
+}
+
+// country возвращает страну записи: по ней разбираются телефоны без кода страны
+func (mw *MainWindow) newContactsEditor(contacts model.Contacts, country func() string) *contactsEditor {
+	e := &contactsEditor{mw: mw, grid: container.NewVBox(), country: country}
+	for _, c := range contacts {
+		e.addRow(c)
+	}
+	return e
+}
+
+// widget возвращает таблицу с заголовком и кнопкой добавления контакта
+func (e *contactsEditor) widget() fyne.CanvasObject {
+	header := container.NewGridWithColumns(6,
+		widget.NewLabel(e.mw.locale.Translate("Contact Name")),
+		widget.NewLabel(e.mw.locale.Translate("Role")),
+		widget.NewLabel(e.mw.locale.Translate("Phone")),
+		widget.NewLabel(e.mw.locale.Translate("Email")),
+		widget.NewLabel(e.mw.locale.Translate("Notes")),
+		widget.NewLabel(""),
+	)
+	addButton := widget.NewButtonWithIcon(e.mw.locale.Translate("Add Contact"), theme.ContentAddIcon(), func() {
+		e.addRow(model.Contact{})
+	})
+	return container.NewVBox(header, e.grid, addButton)
+}
+
+func (e *contactsEditor) addRow(c model.Contact) {
+	row := &contactRow{
+		name: widget.NewEntry(), role: widget.NewEntry(), phone: newPhoneEntry(c.Phone, e.country),
+		email: widget.NewEntry(), notes: widget.NewEntry(),
+	}
+	row.name.SetText(c.Name)
+	row.role.SetText(c.Role)
+	row.email.SetText(c.Email)
+	row.notes.SetText(c.Notes)
+
+	var line *fyne.Container
+	remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		for i, r := range e.rows {
+			if r == row {
+				e.rows = append(e.rows[:i], e.rows[i+1:]...)
+				break
+			}
+		}
+		e.grid.Remove(line)
+	})
+	line = container.NewGridWithColumns(6, row.name, row.role, row.phone, row.email, row.notes, remove)
+	e.rows = append(e.rows, row)
+	e.grid.Add(line)
+}
+
+// contacts собирает контакты из таблицы; пустые строки пропускаются.
+// Измененные телефоны приводятся к E.164 по стране производителя,
+// неверный измененный телефон возвращается ошибкой.
+func (e *contactsEditor) contacts(countryName string) (model.Contacts, error) {
+	var contacts model.Contacts
+	for _, row := range e.rows {
+		number, err := row.phone.number(countryName)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, model.Contact{
+			Name:  row.name.Text,
+			Role:  row.role.Text,
+			Phone: number,
+			Email: row.email.Text,
+			Notes: row.notes.Text,
+		})
+	}
+	return contacts.Trimmed(), nil
+}
+
+// onExportMailing сохраняет список рассылки по показанным в таблице
+// записям: контакты с email и общие адреса производителей
+func (mw *MainWindow) onExportMailing() {
+	data := mw.controller.GetCurrentData()
+	if mw.isSearching {
+		data = mw.searchResults
+	}
+
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		writer.Close()
+
+		filePath := uriToPath(writer.URI())
+		if !strings.HasSuffix(strings.ToLower(filePath), ".csv") {
+			filePath += ".csv"
+		}
+		count, err := mw.controller.ExportMailingList(filePath, data)
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		mw.showNotification(fmt.Sprintf(mw.locale.Translate("Mailing list exported: %d addresses"), count))
+	}, mw.window)
+	saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+	saveDialog.Show()
+}
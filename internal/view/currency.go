@@ -0,0 +1,84 @@
+package view
+
+import (
+	"cursovay/internal/currency"
+	"cursovay/pkg/config"
+	"fmt"
+	"sort"
+
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/widget"
+)
+
+// onReportCurrency выбирает валюту, в которую пересчитываются сортировка
+// по выручке, графики, PDF и итоги. Выбор сохраняется в настройках.
+func (mw *MainWindow) onReportCurrency() {
+	currencySelect := widget.NewSelect(mw.controller.Currencies(), nil)
+	currencySelect.SetSelected(mw.controller.Currency())
+
+	dialog.ShowCustomConfirm(
+		mw.locale.Translate("Report Currency"),
+		mw.locale.Translate("Save"),
+		mw.locale.Translate("Cancel"),
+		container.NewVBox(
+			widget.NewLabel(mw.locale.Translate("Revenue in other currencies is converted using the exchange-rate table.")),
+			currencySelect,
+		),
+		func(ok bool) {
+			if !ok || currencySelect.Selected == "" {
+				return
+			}
+			if err := mw.controller.SetReportCurrency(currencySelect.Selected); err != nil {
+				dialog.ShowError(err, mw.window)
+				return
+			}
+			if cfg, err := config.LoadConfig(); err == nil {
+				cfg.Currency = currencySelect.Selected
+				if err := config.SaveConfig(cfg); err != nil {
+					dialog.ShowError(err, mw.window)
+				}
+			}
+			mw.refreshTable()
+		},
+		mw.window,
+	)
+}
+
+// onRevenueTotal показывает итог выручки в валюте отчетов
+// с разбивкой по типам продукции и по исходным валютам
+func (mw *MainWindow) onRevenueTotal() {
+	total, err := mw.controller.TotalRevenue(mw.controller.GetCurrentData())
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+
+	grid := container.NewGridWithColumns(2,
+		widget.NewLabel(mw.locale.Translate("Manufacturers")), widget.NewLabel(fmt.Sprintf("%d", total.Count)),
+		widget.NewLabel(mw.locale.Translate("Revenue")), widget.NewLabel(currency.Format(total.Total, total.Currency)),
+	)
+	for _, productType := range sortedKeys(total.ByType) {
+		grid.Add(widget.NewLabel("  " + productType))
+		grid.Add(widget.NewLabel(currency.Format(total.ByType[productType], total.Currency)))
+	}
+	if len(total.Original) > 1 {
+		grid.Add(widget.NewLabel(mw.locale.Translate("In original currencies")))
+		grid.Add(widget.NewLabel(""))
+		for _, code := range sortedKeys(total.Original) {
+			grid.Add(widget.NewLabel("  " + code))
+			grid.Add(widget.NewLabel(currency.Format(total.Original[code], code)))
+		}
+	}
+	dialog.ShowCustom(mw.locale.Translate("Revenue Total"), "OK", grid, mw.window)
+}
+
+// sortedKeys возвращает ключи по алфавиту
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,233 @@
+package view
+
+import (
+	"cursovay/internal/model"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+)
+
+// extraTableFields возвращает поля, которые таблица показывает после
+// встроенных столбцов: теги и пользовательские поля текущего файла
+func (mw *MainWindow) extraTableFields() []model.Field {
+	var fields []model.Field
+	if tags, ok := model.FieldByName("tags"); ok {
+		fields = append(fields, tags)
+	}
+	for _, f := range mw.controller.CustomFields() {
+		fields = append(fields, f.Field())
+	}
+	return fields
+}
+
+// tableColumnsWith возвращает ключи всех столбцов таблицы
+func tableColumnsWith(extra []model.Field) []string {
+	columns := append([]string(nil), tableColumns...)
+	for _, f := range extra {
+		columns = append(columns, f.Key)
+	}
+	return columns
+}
+
+// customValueEditor — виджет значения одного пользовательского поля
+// в окне редактирования
+type customValueEditor struct {
+	field    model.CustomField
+	original string
+	entry    *widget.Entry
+	choice   *widget.Select
+	check    *widget.Check
+}
+
+func newCustomValueEditor(f model.CustomField, value string) *customValueEditor {
+	e := &customValueEditor{field: f, original: value}
+	switch f.Type {
+	case model.TypeEnum:
+		e.choice = widget.NewSelect(f.Options, nil)
+		e.choice.SetSelected(value)
+	case model.TypeBool:
+		e.check = widget.NewCheck("", nil)
+		e.check.SetChecked(value == "true")
+	default:
+		e.entry = widget.NewEntry()
+		e.entry.SetText(value)
+		if f.Type == model.TypeDate {
+			e.entry.SetPlaceHolder("2025-12-31")
+		}
+		e.entry.Validator = func(text string) error {
+			_, err := f.Normalize(text)
+			return err
+		}
+	}
+	return e
+}
+
+func (e *customValueEditor) widget() fyne.CanvasObject {
+	switch {
+	case e.choice != nil:
+		return e.choice
+	case e.check != nil:
+		return e.check
+	}
+	return e.entry
+}
+
+// value возвращает введенное значение. Снятый флажок у поля, которое
+// не было заполнено, оставляет его пустым.
+func (e *customValueEditor) value() string {
+	switch {
+	case e.choice != nil:
+		return e.choice.Selected
+	case e.check != nil:
+		if e.check.Checked {
+			return "true"
+		}
+		if e.original == "" {
+			return ""
+		}
+		return "false"
+	}
+	return e.entry.Text
+}
+
+// customFieldsEditor — поля ввода тегов и пользовательских полей
+// в окне редактирования
+type customFieldsEditor struct {
+	tags   *widget.Entry
+	values []*customValueEditor
+}
+
+func (mw *MainWindow) newCustomFieldsEditor(m *model.Manufacturer) *customFieldsEditor {
+	e := &customFieldsEditor{tags: widget.NewEntry()}
+	e.tags.SetText(model.JoinList(m.Tags))
+	e.tags.SetPlaceHolder("preferred, ISO9001")
+	for _, f := range mw.controller.CustomFields() {
+		e.values = append(e.values, newCustomValueEditor(f, m.Custom[f.Key]))
+	}
+	return e
+}
+
+// formItems возвращает строки формы: теги и по строке на каждое поле
+func (e *customFieldsEditor) formItems(mw *MainWindow) []*widget.FormItem {
+	items := []*widget.FormItem{{Text: mw.locale.Translate("Tags"), Widget: e.tags}}
+	for _, v := range e.values {
+		items = append(items, &widget.FormItem{Text: v.field.Title(), Widget: v.widget()})
+	}
+	return items
+}
+
+// apply записывает теги и значения полей в запись, приводя значения
+// к типам полей
+func (e *customFieldsEditor) apply(m *model.Manufacturer) error {
+	m.Tags = model.SplitList(e.tags.Text)
+	for _, v := range e.values {
+		if err := v.field.Field().Set(m, v.value()); err != nil {
+			return fmt.Errorf("%s: %v", v.field.Title(), err)
+		}
+	}
+	return nil
+}
+
+// customFieldRow — строка редактора пользовательских полей
+type customFieldRow struct {
+	key, name, options *widget.Entry
+	fieldType          *widget.Select
+}
+
+// onCustomFields показывает редактор пользовательских полей текущего
+// файла: ключ, название, тип и допустимые значения для перечислений
+func (mw *MainWindow) onCustomFields() {
+	var rows []*customFieldRow
+	grid := container.NewVBox()
+
+	types := make([]string, len(model.FieldTypes))
+	for i, t := range model.FieldTypes {
+		types[i] = string(t)
+	}
+
+	addRow := func(f model.CustomField) {
+		row := &customFieldRow{
+			key: widget.NewEntry(), name: widget.NewEntry(), options: widget.NewEntry(),
+			fieldType: widget.NewSelect(types, nil),
+		}
+		row.key.SetText(f.Key)
+		row.name.SetText(f.Name)
+		row.options.SetText(strings.Join(f.Options, ", "))
+		row.options.SetPlaceHolder("gold, silver, bronze")
+		if f.Type == "" {
+			f.Type = model.TypeText
+		}
+		row.fieldType.SetSelected(string(f.Type))
+
+		var line *fyne.Container
+		remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			for i, r := range rows {
+				if r == row {
+					rows = append(rows[:i], rows[i+1:]...)
+					break
+				}
+			}
+			grid.Remove(line)
+		})
+		line = container.NewGridWithColumns(5, row.key, row.name, row.fieldType, row.options, remove)
+		rows = append(rows, row)
+		grid.Add(line)
+	}
+	for _, f := range mw.controller.CustomFields() {
+		addRow(f)
+	}
+
+	header := container.NewGridWithColumns(5,
+		widget.NewLabel(mw.locale.Translate("Key")),
+		widget.NewLabel(mw.locale.Translate("Title")),
+		widget.NewLabel(mw.locale.Translate("Type")),
+		widget.NewLabel(mw.locale.Translate("Allowed Values")),
+		widget.NewLabel(""),
+	)
+	addButton := widget.NewButtonWithIcon(mw.locale.Translate("Add Field"), theme.ContentAddIcon(), func() {
+		addRow(model.CustomField{})
+	})
+
+	dialog.ShowCustomConfirm(
+		mw.locale.Translate("Custom Fields"),
+		mw.locale.Translate("Save"),
+		mw.locale.Translate("Cancel"),
+		container.NewVBox(
+			widget.NewLabel(mw.locale.Translate("Fields are stored in the current file. Removing a field erases its values.")),
+			header, grid, addButton,
+		),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			var schema model.Schema
+			for _, row := range rows {
+				key := strings.TrimSpace(row.key.Text)
+				if key == "" {
+					continue
+				}
+				f := model.CustomField{
+					Key:  key,
+					Name: strings.TrimSpace(row.name.Text),
+					Type: model.FieldType(row.fieldType.Selected),
+				}
+				if f.Type == model.TypeEnum {
+					f.Options = model.SplitList(row.options.Text)
+				}
+				schema = append(schema, f)
+			}
+			if err := mw.controller.SetCustomFields(schema); err != nil {
+				dialog.ShowError(err, mw.window)
+				return
+			}
+			mw.updateWindowTitle()
+			mw.refreshTable()
+		},
+		mw.window,
+	)
+}
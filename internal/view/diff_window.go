@@ -0,0 +1,142 @@
+package view
+
+import (
+	"cursovay/internal/diff"
+	"cursovay/internal/model"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/storage"
+	"fyne.io/fyne/widget"
+)
+
+// diffRow — строка таблицы изменений: запись целиком или одно поле
+type diffRow struct {
+	kind  diff.Kind
+	id    int
+	name  string
+	field string // заголовок поля
+	old   string
+	new   string
+}
+
+// onShowChanges показывает, чем данные в памяти отличаются от файла на диске
+func (mw *MainWindow) onShowChanges() {
+	changes, err := mw.controller.DiffWithFile()
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	fileName := filepath.Base(mw.controller.GetCurrentFile())
+
+	if changes.Empty() {
+		dialog.ShowInformation(
+			mw.locale.Translate("Changes"),
+			fmt.Sprintf(mw.locale.Translate("No differences from %s"), fileName),
+			mw.window,
+		)
+		return
+	}
+
+	var rows []diffRow
+	fields := mw.controller.Fields()
+	for _, c := range changes.Changes {
+		switch c.Kind {
+		case diff.Added:
+			rows = append(rows, diffRow{kind: c.Kind, id: c.ID, name: c.New.Name})
+		case diff.Removed:
+			rows = append(rows, diffRow{kind: c.Kind, id: c.ID, name: c.Old.Name})
+		default:
+			for _, f := range c.Fields {
+				field, _ := model.LookupField(fields, f.Field)
+				rows = append(rows, diffRow{kind: c.Kind, id: c.ID, name: c.New.Name, field: field.Header, old: f.Old, new: f.New})
+			}
+		}
+	}
+
+	kinds := map[diff.Kind]string{
+		diff.Added:    "+ " + mw.locale.Translate("Added"),
+		diff.Removed:  "- " + mw.locale.Translate("Removed"),
+		diff.Modified: "~ " + mw.locale.Translate("Modified"),
+	}
+	headers := []string{"", "ID", mw.locale.Translate("Name"), mw.locale.Translate("Field"), mw.locale.Translate("Old → New")}
+	table := widget.NewTable(
+		func() (int, int) { return len(rows) + 1, len(headers) },
+		func() fyne.CanvasObject { return widget.NewLabel("Template long cell") },
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			label := cell.(*widget.Label)
+			if id.Row == 0 {
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				label.SetText(headers[id.Col])
+				return
+			}
+			label.TextStyle = fyne.TextStyle{}
+			r := rows[id.Row-1]
+			switch id.Col {
+			case 0:
+				label.SetText(kinds[r.kind])
+			case 1:
+				label.SetText(strconv.Itoa(r.id))
+			case 2:
+				label.SetText(r.name)
+			case 3:
+				label.SetText(mw.locale.Translate(r.field))
+			default:
+				if r.kind == diff.Modified {
+					label.SetText(r.old + " → " + r.new)
+				} else {
+					label.SetText("")
+				}
+			}
+		},
+	)
+	table.SetColumnWidth(0, 130)
+	table.SetColumnWidth(1, 50)
+	table.SetColumnWidth(2, 200)
+	table.SetColumnWidth(3, 130)
+	table.SetColumnWidth(4, 380)
+
+	window := mw.app.NewWindow(mw.locale.Translate("Changes") + " — " + fileName)
+	summary := widget.NewLabel(fmt.Sprintf(
+		mw.locale.Translate("Added: %d, modified: %d, deleted: %d"),
+		changes.Count(diff.Added), changes.Count(diff.Modified), changes.Count(diff.Removed),
+	))
+
+	reportButton := widget.NewButton(mw.locale.Translate("Save Report"), func() {
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer writer.Close()
+
+			var report strings.Builder
+			changes.WriteUnified(&report, fileName, fileName+" ("+mw.locale.Translate("in memory")+")")
+			if _, err := writer.Write([]byte(report.String())); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			mw.showNotification(mw.locale.Translate("Report saved"))
+		}, window)
+		saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".diff", ".txt"}))
+		saveDialog.Show()
+	})
+
+	window.SetContent(container.NewBorder(
+		summary,
+		container.NewHBox(reportButton, widget.NewButton(mw.locale.Translate("Close"), window.Close)),
+		nil, nil,
+		container.NewScroll(table),
+	))
+	window.Resize(fyne.NewSize(950, 600))
+	window.Show()
+	window.CenterOnScreen()
+}
@@ -0,0 +1,144 @@
+package view
+
+import (
+	"cursovay/internal/dedup"
+	"cursovay/pkg/config"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/widget"
+)
+
+// duplicateOptions возвращает настройки поиска дубликатов из конфигурации
+func duplicateOptions() dedup.Options {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg.Duplicates == nil {
+		return dedup.DefaultOptions()
+	}
+	d := cfg.Duplicates
+	return dedup.Options{
+		NameSimilarity: d.NameSimilarity,
+		MatchEmail:     d.MatchEmail,
+		MatchPhone:     d.MatchPhone,
+		MatchWebsite:   d.MatchWebsite,
+	}
+}
+
+// onFindDuplicates показывает группы вероятных дубликатов. Отмеченные
+// группы сливаются в запись с наименьшим ID.
+func (mw *MainWindow) onFindDuplicates() {
+	window := mw.app.NewWindow(mw.locale.Translate("Find Duplicates"))
+	opts := duplicateOptions()
+
+	thresholdLabel := widget.NewLabel("")
+	showThreshold := func() {
+		thresholdLabel.SetText(fmt.Sprintf(mw.locale.Translate("Name similarity: %.0f%%"), opts.NameSimilarity*100))
+	}
+	showThreshold()
+	slider := widget.NewSlider(0.5, 1)
+	slider.Step = 0.01
+	slider.Value = opts.NameSimilarity
+	slider.OnChanged = func(v float64) {
+		opts.NameSimilarity = v
+		showThreshold()
+	}
+
+	emailCheck := widget.NewCheck(mw.locale.Translate("Same email"), func(b bool) { opts.MatchEmail = b })
+	emailCheck.SetChecked(opts.MatchEmail)
+	phoneCheck := widget.NewCheck(mw.locale.Translate("Same phone"), func(b bool) { opts.MatchPhone = b })
+	phoneCheck.SetChecked(opts.MatchPhone)
+	websiteCheck := widget.NewCheck(mw.locale.Translate("Same website"), func(b bool) { opts.MatchWebsite = b })
+	websiteCheck.SetChecked(opts.MatchWebsite)
+
+	results := container.NewVBox()
+	var groups []dedup.Group
+	var selected []bool
+
+	search := func() {
+		groups = mw.controller.FindDuplicates(opts)
+		selected = make([]bool, len(groups))
+		results.Objects = nil
+		if len(groups) == 0 {
+			results.Add(widget.NewLabel(mw.locale.Translate("No duplicates found")))
+		}
+		for i, g := range groups {
+			i := i
+			selected[i] = true
+			results.Add(mw.duplicateCard(g, func(checked bool) { selected[i] = checked }))
+		}
+		results.Refresh()
+	}
+
+	mergeButton := widget.NewButton(mw.locale.Translate("Merge selected"), func() {
+		var ids [][]int
+		for i, g := range groups {
+			if !selected[i] {
+				continue
+			}
+			group := make([]int, len(g.Members))
+			for j, m := range g.Members {
+				group[j] = m.ID
+			}
+			ids = append(ids, group)
+		}
+		if len(ids) == 0 {
+			return
+		}
+		if err := mw.controller.MergeDuplicates(ids); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		mw.afterImport()
+		mw.showNotification(fmt.Sprintf(mw.locale.Translate("Merged groups: %d"), len(ids)))
+		search()
+	})
+
+	controls := container.NewVBox(
+		container.NewBorder(nil, nil, thresholdLabel, nil, slider),
+		container.NewHBox(emailCheck, phoneCheck, websiteCheck,
+			widget.NewButton(mw.locale.Translate("Search"), search)),
+		widget.NewSeparator(),
+	)
+	window.SetContent(container.NewBorder(
+		controls,
+		container.NewHBox(mergeButton, widget.NewButton(mw.locale.Translate("Close"), window.Close)),
+		nil, nil,
+		container.NewVScroll(results),
+	))
+	search()
+	window.Resize(fyne.NewSize(900, 700))
+	window.Show()
+	window.CenterOnScreen()
+}
+
+// duplicateCard показывает записи группы и результат их слияния
+func (mw *MainWindow) duplicateCard(g dedup.Group, onChecked func(bool)) fyne.CanvasObject {
+	rows := container.NewVBox()
+	for i := range g.Members {
+		m := &g.Members[i]
+		rows.Add(widget.NewLabel(fmt.Sprintf("%s | %s | %s | %s",
+			describeManufacturer(m), m.Email, m.Phone, m.Website)))
+	}
+
+	merged := dedup.Merge(g.Members, mw.controller.Fields())
+	rows.Add(widget.NewLabelWithStyle(
+		"→ "+fmt.Sprintf("%s | %s | %s | %s", describeManufacturer(&merged), merged.Email, merged.Phone, merged.Website),
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true},
+	))
+
+	reasons := make([]string, len(g.Reasons))
+	for i, r := range g.Reasons {
+		reasons[i] = mw.locale.Translate(r)
+	}
+	check := widget.NewCheck(mw.locale.Translate("Merge"), onChecked)
+	check.SetChecked(true)
+
+	return widget.NewCard(
+		fmt.Sprintf(mw.locale.Translate("%d records"), len(g.Members)),
+		mw.locale.Translate("Matched by: ")+strings.Join(reasons, ", "),
+		container.NewBorder(nil, nil, nil, check, rows),
+	)
+}
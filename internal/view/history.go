@@ -0,0 +1,112 @@
+package view
+
+import (
+	"cursovay/internal/model"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+)
+
+// historyRow — строка таблицы истории: год, выручка, сотрудники
+type historyRow struct {
+	year, revenue, employees *widget.Entry
+}
+
+// historyEditor — таблица выручки и численности по годам в окне
+// редактирования. onChanged вызывается при любом изменении таблицы.
+type historyEditor struct {
+	mw        *MainWindow
+	rows      []*historyRow
+	grid      *fyne.Container
+	onChanged func()
+}
+
+func (mw *MainWindow) newHistoryEditor(history model.History) *historyEditor {
+	e := &historyEditor{mw: mw, grid: container.NewVBox()}
+	for _, s := range history {
+		employees := ""
+		if s.Employees != 0 {
+			employees = strconv.Itoa(s.Employees)
+		}
+		e.addRow(strconv.Itoa(s.Year), fmt.Sprintf("%.2f", s.Revenue), employees)
+	}
+	return e
+}
+
+// widget возвращает таблицу с заголовком и кнопкой добавления года
+func (e *historyEditor) widget() fyne.CanvasObject {
+	header := container.NewGridWithColumns(4,
+		widget.NewLabel(e.mw.locale.Translate("Year")),
+		widget.NewLabel(e.mw.locale.Translate("Revenue")),
+		widget.NewLabel(e.mw.locale.Translate("Employees")),
+		widget.NewLabel(""),
+	)
+	addButton := widget.NewButtonWithIcon(e.mw.locale.Translate("Add Year"), theme.ContentAddIcon(), func() {
+		// Следующий год после последнего в таблице, для пустой — прошлый год
+		year := time.Now().Year() - 1
+		if h, err := e.history(); err == nil {
+			if latest, ok := h.Latest(); ok {
+				year = latest.Year + 1
+			}
+		}
+		e.addRow(strconv.Itoa(year), "", "")
+		e.changed()
+	})
+	return container.NewVBox(header, e.grid, addButton)
+}
+
+func (e *historyEditor) addRow(year, revenue, employees string) {
+	row := &historyRow{year: widget.NewEntry(), revenue: widget.NewEntry(), employees: widget.NewEntry()}
+	row.year.SetText(year)
+	row.revenue.SetText(revenue)
+	row.employees.SetText(employees)
+	for _, entry := range []*widget.Entry{row.year, row.revenue, row.employees} {
+		entry.OnChanged = func(string) { e.changed() }
+	}
+
+	var line *fyne.Container
+	remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		for i, r := range e.rows {
+			if r == row {
+				e.rows = append(e.rows[:i], e.rows[i+1:]...)
+				break
+			}
+		}
+		e.grid.Remove(line)
+		e.changed()
+	})
+	line = container.NewGridWithColumns(4, row.year, row.revenue, row.employees, remove)
+	e.rows = append(e.rows, row)
+	e.grid.Add(line)
+}
+
+func (e *historyEditor) changed() {
+	if e.onChanged != nil {
+		e.onChanged()
+	}
+}
+
+// history собирает историю из таблицы; пустые строки пропускаются
+func (e *historyEditor) history() (model.History, error) {
+	var parts []string
+	for _, row := range e.rows {
+		year := strings.TrimSpace(row.year.Text)
+		revenue := strings.TrimSpace(row.revenue.Text)
+		employees := strings.TrimSpace(row.employees.Text)
+		if year == "" && revenue == "" && employees == "" {
+			continue
+		}
+		part := year + "=" + revenue
+		if employees != "" {
+			part += "/" + employees
+		}
+		parts = append(parts, part)
+	}
+	return model.ParseHistory(strings.Join(parts, ";"))
+}
@@ -0,0 +1,445 @@
+package view
+
+import (
+	"cursovay/internal/controller"
+	"cursovay/internal/importer"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/storage"
+	"fyne.io/fyne/widget"
+)
+
+// previewRows — сколько строк файла показывать в предпросмотре
+const previewRows = 10
+
+// importWizard — состояние мастера импорта CSV или Excel
+type importWizard struct {
+	mw       *MainWindow
+	window   fyne.Window
+	data     []byte
+	workbook *importer.Workbook // nil для CSV
+	sheet    string
+	format   importer.Format
+	table    *importer.Table
+	mapping  importer.Mapping
+
+	mappingBox *fyne.Container
+	preview    *widget.Table
+	status     *widget.Label
+}
+
+// delimiterNames — подписи разделителей в мастере
+var delimiterNames = map[rune]string{',': ",", ';': ";", '\t': "Tab", '|': "|"}
+
+func (mw *MainWindow) onImportCSV() {
+	fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		data, err := os.ReadFile(uriToPath(reader.URI()))
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		mw.showImportWizard(data, nil)
+	}, mw.window)
+
+	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".txt", ".tsv"}))
+	fileDialog.Show()
+}
+
+func (mw *MainWindow) onImportXLSX() {
+	fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		data, err := os.ReadFile(uriToPath(reader.URI()))
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		workbook, err := importer.OpenWorkbook(data)
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		mw.showImportWizard(data, workbook)
+	}, mw.window)
+
+	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".xlsx"}))
+	fileDialog.Show()
+}
+
+// onImportJSON импортирует файл ExportToJSON или JSON Lines
+// в выбранном режиме: добавить, заменить все или объединить по ID
+func (mw *MainWindow) onImportJSON() {
+	fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+		filePath := uriToPath(reader.URI())
+
+		modes := map[string]controller.ImportMode{
+			mw.locale.Translate("Append"):      controller.ImportAppend,
+			mw.locale.Translate("Replace all"): controller.ImportReplace,
+			mw.locale.Translate("Merge by ID"): controller.ImportMerge,
+		}
+		modeSelect := widget.NewSelect([]string{
+			mw.locale.Translate("Append"),
+			mw.locale.Translate("Replace all"),
+			mw.locale.Translate("Merge by ID"),
+		}, nil)
+		modeSelect.SetSelected(mw.locale.Translate("Append"))
+
+		dialog.ShowCustomConfirm(
+			mw.locale.Translate("Import JSON"),
+			mw.locale.Translate("Import"),
+			mw.locale.Translate("Cancel"),
+			container.NewVBox(widget.NewLabel(filepath.Base(filePath)), modeSelect),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				result, err := mw.controller.ImportFromJSON(filePath, modes[modeSelect.Selected])
+				if err != nil {
+					// При отмене замены в отчете видно, какие записи не прошли проверку
+					if result != nil {
+						mw.showImportReport(result)
+					}
+					dialog.ShowError(err, mw.window)
+					return
+				}
+				mw.afterImport()
+				mw.showImportReport(result)
+			},
+			mw.window,
+		)
+	}, mw.window)
+
+	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json", ".jsonl"}))
+	fileDialog.Show()
+}
+
+// showImportWizard открывает окно с настройками формата, сопоставлением
+// столбцов и предпросмотром. Формат сначала определяется автоматически.
+// Для книги Excel вместо разделителя и кодировки выбирается лист.
+func (mw *MainWindow) showImportWizard(data []byte, workbook *importer.Workbook) {
+	title := mw.locale.Translate("Import CSV")
+	if workbook != nil {
+		title = mw.locale.Translate("Import Excel")
+	}
+	w := &importWizard{
+		mw:       mw,
+		window:   mw.app.NewWindow(title),
+		data:     data,
+		workbook: workbook,
+		status:   widget.NewLabel(""),
+	}
+	if workbook != nil {
+		w.sheet = workbook.Sheets()[0]
+		w.format = workbook.Sniff(w.sheet)
+		w.window.SetOnClosed(func() { workbook.Close() })
+	} else {
+		w.format = importer.Sniff(data)
+	}
+
+	delimiters := make([]string, len(importer.Delimiters))
+	for i, d := range importer.Delimiters {
+		delimiters[i] = delimiterNames[d]
+	}
+	delimiterSelect := widget.NewSelect(delimiters, nil)
+	delimiterSelect.SetSelected(delimiterNames[w.format.Delimiter])
+	delimiterSelect.OnChanged = func(selected string) {
+		for d, name := range delimiterNames {
+			if name == selected {
+				w.format.Delimiter = d
+			}
+		}
+		w.reload()
+	}
+
+	encodingSelect := widget.NewSelect(importer.Encodings, nil)
+	encodingSelect.SetSelected(w.format.Encoding)
+	encodingSelect.OnChanged = func(selected string) {
+		w.format.Encoding = selected
+		w.reload()
+	}
+
+	headerCheck := widget.NewCheck(mw.locale.Translate("First row is a header"), nil)
+	headerCheck.SetChecked(w.format.HasHeader)
+	headerCheck.OnChanged = func(checked bool) {
+		w.format.HasHeader = checked
+		w.reload()
+	}
+
+	formatControls := container.NewGridWithColumns(2,
+		widget.NewLabel(mw.locale.Translate("Delimiter:")),
+		delimiterSelect,
+		widget.NewLabel(mw.locale.Translate("Encoding:")),
+		encodingSelect,
+	)
+	if workbook != nil {
+		sheetSelect := widget.NewSelect(workbook.Sheets(), nil)
+		sheetSelect.SetSelected(w.sheet)
+		sheetSelect.OnChanged = func(selected string) {
+			w.sheet = selected
+			w.format = workbook.Sniff(selected)
+			headerCheck.SetChecked(w.format.HasHeader)
+			w.reload()
+		}
+		formatControls = container.NewGridWithColumns(2,
+			widget.NewLabel(mw.locale.Translate("Sheet:")),
+			sheetSelect,
+		)
+	}
+
+	w.mappingBox = container.NewGridWithColumns(2)
+	w.preview = widget.NewTable(
+		func() (int, int) {
+			if w.table == nil {
+				return 0, 0
+			}
+			rows := len(w.table.Rows)
+			if rows > previewRows {
+				rows = previewRows
+			}
+			return rows + 1, len(w.table.Header)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template long cell")
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cell.(*widget.Label).SetText(w.previewCell(id.Row, id.Col))
+		},
+	)
+
+	importButton := widget.NewButton(mw.locale.Translate("Import"), w.run)
+	cancelButton := widget.NewButton(mw.locale.Translate("Cancel"), w.window.Close)
+
+	settings := container.NewVBox(
+		formatControls,
+		headerCheck,
+		widget.NewSeparator(),
+		widget.NewLabel(mw.locale.Translate("Column mapping")),
+		container.NewVScroll(w.mappingBox),
+		widget.NewSeparator(),
+		widget.NewLabel(mw.locale.Translate("Preview")),
+	)
+
+	w.window.SetContent(container.NewBorder(
+		settings,
+		container.NewVBox(w.status, container.NewHBox(importButton, cancelButton)),
+		nil, nil,
+		container.NewScroll(w.preview),
+	))
+	w.reload()
+	w.window.Resize(fyne.NewSize(900, 700))
+	w.window.Show()
+	w.window.CenterOnScreen()
+}
+
+// reload заново разбирает файл после смены формата или листа
+func (w *importWizard) reload() {
+	var table *importer.Table
+	var err error
+	if w.workbook != nil {
+		table, err = w.workbook.ReadSheet(w.sheet, w.format)
+	} else {
+		table, err = importer.ReadTable(w.data, w.format)
+	}
+	if err != nil {
+		w.table = nil
+		w.mapping = nil
+		w.status.SetText(err.Error())
+	} else {
+		w.table = table
+		w.mapping = importer.GuessMapping(table, w.format, w.mw.controller.Fields())
+		w.status.SetText(fmt.Sprintf(w.mw.locale.Translate("Rows in file: %d"), len(table.Rows)))
+	}
+	w.rebuildMapping()
+	w.preview.Refresh()
+}
+
+// rebuildMapping показывает для каждого столбца выбор поля
+func (w *importWizard) rebuildMapping() {
+	w.mappingBox.Objects = nil
+	if w.table == nil {
+		w.mappingBox.Refresh()
+		return
+	}
+
+	skip := w.mw.locale.Translate("(skip)")
+	fields := importer.ImportFields(w.mw.controller.Fields())
+	options := []string{skip}
+	for _, f := range fields {
+		options = append(options, w.mw.locale.Translate(f.Header))
+	}
+
+	for i, column := range w.table.Header {
+		i := i
+		fieldSelect := widget.NewSelect(options, nil)
+		fieldSelect.SetSelected(skip)
+		for _, f := range fields {
+			if f.Key == w.mapping[i] {
+				fieldSelect.SetSelected(w.mw.locale.Translate(f.Header))
+			}
+		}
+		fieldSelect.OnChanged = func(selected string) {
+			w.mapping[i] = ""
+			for _, f := range fields {
+				if w.mw.locale.Translate(f.Header) == selected {
+					w.mapping[i] = f.Key
+				}
+			}
+			w.preview.Refresh()
+		}
+		w.mappingBox.Add(widget.NewLabel(column))
+		w.mappingBox.Add(fieldSelect)
+	}
+	w.mappingBox.Refresh()
+}
+
+// previewCell возвращает текст ячейки предпросмотра. В первой строке —
+// заголовок столбца и поле, в которое он импортируется.
+func (w *importWizard) previewCell(row, col int) string {
+	if row == 0 {
+		target := w.mw.locale.Translate("(skip)")
+		if key := w.mapping[col]; key != "" {
+			for _, f := range importer.ImportFields(w.mw.controller.Fields()) {
+				if f.Key == key {
+					target = w.mw.locale.Translate(f.Header)
+				}
+			}
+		}
+		return w.table.Header[col] + " → " + target
+	}
+
+	r := w.table.Rows[row-1]
+	if r.Err != nil {
+		if col == 0 {
+			return "! " + r.Err.Error()
+		}
+		return ""
+	}
+	if col < len(r.Values) {
+		return r.Values[col]
+	}
+	return ""
+}
+
+// run импортирует строки и показывает отчет об ошибках
+func (w *importWizard) run() {
+	if w.table == nil {
+		return
+	}
+	result, err := importer.Convert(w.table, w.mapping, w.mw.controller.Fields(), w.mw.controller.ImportChecker(controller.ImportAppend))
+	if err != nil {
+		dialog.ShowError(err, w.window)
+		return
+	}
+
+	if err := w.mw.controller.ImportManufacturers(result.Manufacturers); err != nil {
+		dialog.ShowError(err, w.window)
+		return
+	}
+	w.mw.afterImport()
+	w.window.Close()
+
+	w.mw.showImportReport(result)
+}
+
+// afterImport обновляет таблицы и заголовок после импорта
+func (mw *MainWindow) afterImport() {
+	if openFile, ok := mw.openFiles[mw.activeFile]; ok {
+		openFile.Manufacturers = mw.controller.GetCurrentData()
+		mw.refreshFileTab(mw.activeFile)
+	}
+	mw.updateWindowTitle()
+	mw.refreshTable()
+}
+
+// showImportReport показывает итог импорта и ошибки по строкам
+// с возможностью сохранить отчет в CSV
+func (mw *MainWindow) showImportReport(result *importer.Result) {
+	summary := widget.NewLabel(fmt.Sprintf(
+		mw.locale.Translate("Imported %d of %d rows, rejected: %d"),
+		len(result.Manufacturers), result.Rows, result.Rejected(),
+	))
+	if len(result.Issues) == 0 {
+		dialog.ShowCustom(mw.locale.Translate("Import"), "OK", summary, mw.window)
+		return
+	}
+
+	lines := make([]string, len(result.Issues))
+	for i, issue := range result.Issues {
+		severity := mw.locale.Translate("Warning")
+		switch issue.Severity {
+		case importer.Rejected:
+			severity = mw.locale.Translate("Rejected")
+		case importer.Skipped:
+			severity = mw.locale.Translate("Skipped")
+		}
+		line := fmt.Sprintf(mw.locale.Translate("Line %d"), issue.Line)
+		if result.Indexed {
+			line = fmt.Sprintf(mw.locale.Translate("Record %d"), issue.Line)
+		}
+		if issue.Column != "" {
+			line += fmt.Sprintf(", %s %q", issue.Column, issue.Value)
+		}
+		lines[i] = fmt.Sprintf("%s — %s: %s", line, severity, issue.Message)
+	}
+	issues := widget.NewLabel(strings.Join(lines, "\n"))
+	scroll := container.NewScroll(issues)
+	scroll.SetMinSize(fyne.NewSize(600, 300))
+
+	saveButton := widget.NewButton(mw.locale.Translate("Save Report"), func() {
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, mw.window)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer writer.Close()
+
+			if err := result.WriteReport(writer); err != nil {
+				dialog.ShowError(err, mw.window)
+				return
+			}
+			mw.showNotification(mw.locale.Translate("Report saved"))
+		}, mw.window)
+		saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+		saveDialog.Show()
+	})
+
+	dialog.ShowCustom(
+		mw.locale.Translate("Import"),
+		"OK",
+		container.NewBorder(summary, saveButton, nil, nil, scroll),
+		mw.window,
+	)
+}
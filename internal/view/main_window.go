@@ -2,8 +2,13 @@ package view
 
 import (
 	"bytes"
+	"cursovay/internal/address"
 	"cursovay/internal/controller"
+	"cursovay/internal/country"
+	"cursovay/internal/currency"
 	"cursovay/internal/model"
+	"cursovay/internal/phone"
+	"cursovay/internal/repository"
 	"cursovay/pkg/localization"
 	"errors"
 	"fmt"
@@ -21,6 +26,7 @@ import (
 	"fyne.io/fyne/canvas"
 	"fyne.io/fyne/container"
 	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/driver/desktop"
 	"fyne.io/fyne/storage"
 	"fyne.io/fyne/widget"
 	"fyne.io/fyne/theme"
@@ -36,16 +42,13 @@ type MainWindow struct {
 	searchResults []model.Manufacturer
 	isSearching   bool
 	recentFiles   *RecentFiles
-	currentSort   struct {
-		column    string
-		ascending bool
-	}
+	sortKeys      []repository.SortKey // сортировка вкладки Database
+	shiftDown     bool                 // зажат Shift (для сортировки по нескольким столбцам)
 	mainContainer *fyne.Container
 	// tableContainer *fyne.Container
 	controller     *controller.ManufacturerController
 	locale         *localization.Locale
 	currentFile    string
-	unsavedChanges bool
 	selectedRow    int
 	contextMenu    *widget.PopUp // Контекстное меню
 	openFiles      map[string]*OpenFile // Открытые файлы
@@ -59,6 +62,7 @@ type OpenFile struct {
 	Manufacturers   []model.Manufacturer
 	UnsavedChanges  bool
 	TabItem         *widget.TabItem
+	Sort            []repository.SortKey // сортировка таблицы этой вкладки
 }
 
 type RecentFiles struct {
@@ -95,7 +99,6 @@ func NewMainWindow(app fyne.App, controller *controller.ManufacturerController,
 		controller:     controller,
 		locale:         locale,
 		currentFile:    "",
-		unsavedChanges: false,
 		recentFiles:    NewRecentFiles(10),
 		openFiles:      make(map[string]*OpenFile),
 		activeFile:     "",
@@ -111,10 +114,17 @@ func (mw *MainWindow) setupMenu() *fyne.MainMenu {
 	fileMenu := fyne.NewMenu(mw.locale.Translate("File"),
 		fyne.NewMenuItem(mw.locale.Translate("New"), mw.onCreateNewFile),
 		fyne.NewMenuItem(mw.locale.Translate("Open"), mw.onOpen),
+		fyne.NewMenuItem(mw.locale.Translate("Show Changes"), mw.onShowChanges),
 		fyne.NewMenuItem(mw.locale.Translate("Save"), mw.onSave),
 		fyne.NewMenuItem(mw.locale.Translate("Save As"), mw.onSaveAsWithPrompt),
+		fyne.NewMenuItem(mw.locale.Translate("Import CSV")+"...", mw.onImportCSV),
+		fyne.NewMenuItem(mw.locale.Translate("Import Excel")+"...", mw.onImportXLSX),
+		fyne.NewMenuItem(mw.locale.Translate("Import JSON")+"...", mw.onImportJSON),
+		fyne.NewMenuItem(mw.locale.Translate("Merge Files")+"...", mw.onMergeFiles),
 		fyne.NewMenuItem(mw.locale.Translate("Export to PDF"), mw.onExportPDF),
 		fyne.NewMenuItem(mw.locale.Translate("Export to JSON"), mw.onExportJSON),
+		fyne.NewMenuItem(mw.locale.Translate("Export to Excel"), mw.onExportXLSX),
+		fyne.NewMenuItem(mw.locale.Translate("Export Mailing List")+"...", mw.onExportMailing),
 		fyne.NewMenuItem(mw.locale.Translate("Exit"), func() {
 			mw.checkUnsavedChanges(func() {
 				mw.app.Quit()
@@ -123,13 +133,26 @@ func (mw *MainWindow) setupMenu() *fyne.MainMenu {
 	)
 
 	editMenu := fyne.NewMenu(mw.locale.Translate("Edit"),
+		fyne.NewMenuItem(mw.locale.Translate("Undo")+" (Ctrl+Z)", mw.onUndo),
+		fyne.NewMenuItem(mw.locale.Translate("Redo")+" (Ctrl+Y)", mw.onRedo),
+		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem(mw.locale.Translate("Add"), mw.onAdd),
 		fyne.NewMenuItem(mw.locale.Translate("Edit"), func() { mw.onEdit(-1) }),
 		fyne.NewMenuItem(mw.locale.Translate("Delete"), func() { mw.onDelete(-1) }),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(mw.locale.Translate("Find Duplicates")+"...", mw.onFindDuplicates),
+		fyne.NewMenuItem(mw.locale.Translate("Normalize Phones"), mw.onNormalizePhones),
+		fyne.NewMenuItem(mw.locale.Translate("Normalize Countries"), mw.onNormalizeCountries),
+		fyne.NewMenuItem(mw.locale.Translate("Split Addresses"), mw.onSplitAddresses),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(mw.locale.Translate("Product Types")+"...", mw.onProductTypes),
+		fyne.NewMenuItem(mw.locale.Translate("Custom Fields")+"...", mw.onCustomFields),
 	)
 
 	viewMenu := fyne.NewMenu(mw.locale.Translate("View"),
 		fyne.NewMenuItem(mw.locale.Translate("Chart"), mw.onShowChart),
+		fyne.NewMenuItem(mw.locale.Translate("Revenue Total"), mw.onRevenueTotal),
+		fyne.NewMenuItem(mw.locale.Translate("Report Currency")+"...", mw.onReportCurrency),
 	)
 
 	helpMenu := fyne.NewMenu(mw.locale.Translate("About Program"),
@@ -151,14 +174,15 @@ func (mw *MainWindow) setupMenu() *fyne.MainMenu {
 }
 
 func (mw *MainWindow) checkUnsavedChanges(callback func()) {
-	if !mw.unsavedChanges {
+	changes := mw.controller.Changes()
+	if changes.Empty() {
 		callback()
 		return
 	}
 
 	dialog.ShowConfirm(
 		mw.locale.Translate("Unsaved Changes"),
-		mw.locale.Translate("You have unsaved changes. Do you want to save them?"),
+		mw.locale.Translate("You have unsaved changes. Do you want to save them?")+"\n"+mw.changesSummary(changes),
 		func(save bool) {
 			if save {
 				mw.onSave()
@@ -213,7 +237,6 @@ func (mw *MainWindow) createRecentFilesTab() *widget.TabItem {
 					}
 
 					mw.currentFile = filePath
-					mw.unsavedChanges = false
 
 					// Полностью пересоздаем таблицу
 					mw.table = mw.createManufacturersTable()
@@ -221,7 +244,7 @@ func (mw *MainWindow) createRecentFilesTab() *widget.TabItem {
 					// Обновляем содержимое главного окна
 					mw.refreshMainContent()
 
-					mw.window.SetTitle("База производителей - " + filepath.Base(filePath))
+					mw.updateWindowTitle()
 					mw.window.Content().Refresh()
 				})
 			}()
@@ -296,6 +319,7 @@ func (mw *MainWindow) Show() {
 
 	mw.mainContainer = container.NewMax(tabs)
 	mw.window.SetContent(mw.mainContainer)
+	mw.setupShortcuts()
 	mw.window.Resize(fyne.NewSize(1400, 800))
 	mw.window.ShowAndRun()
 }
@@ -361,8 +385,8 @@ func (mw *MainWindow) onOpen() {
 		defer reader.Close()
 
 		filePath := uriToPath(reader.URI())
-		if !strings.HasSuffix(strings.ToLower(filePath), ".csv") {
-			dialog.ShowError(errors.New("выберите CSV файл"), mw.window)
+		if !strings.HasSuffix(strings.ToLower(filePath), ".csv") && !controller.IsDatabaseFile(filePath) {
+			dialog.ShowError(errors.New("выберите CSV файл или базу .db"), mw.window)
 			return
 		}
 
@@ -372,10 +396,10 @@ func (mw *MainWindow) onOpen() {
 			return
 		}
 
-		mw.window.SetTitle("База производителей - " + filepath.Base(filePath))
+		mw.updateWindowTitle()
 	}, mw.window)
 
-	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".db"}))
 	fileDialog.Show()
 }
 
@@ -384,7 +408,11 @@ func (mw *MainWindow) onSave() {
 		mw.onSaveAsWithPrompt()
 		return
 	}
+	mw.confirmSaveViolations(mw.saveCurrentFile)
+}
 
+// saveCurrentFile сохраняет текущий файл в фоне
+func (mw *MainWindow) saveCurrentFile() {
 	loading := dialog.NewProgress("Сохранение", "Идет сохранение...", mw.window)
 	loading.Show()
 
@@ -397,7 +425,7 @@ func (mw *MainWindow) onSave() {
 				dialog.ShowError(fmt.Errorf("Ошибка сохранения: %v", err), mw.window)
 				return
 			}
-			mw.unsavedChanges = false
+			mw.updateWindowTitle()
 			mw.showNotification("Файл успешно сохранен")
 			mw.refreshTable() // Обновляем таблицу после сохранения
 		})
@@ -421,7 +449,7 @@ func (mw *MainWindow) onCreateNewFile() {
 	// Сначала создаем новую базу данных
 	mw.controller.NewDatabase()
 	mw.currentFile = ""
-	mw.unsavedChanges = false
+	mw.updateWindowTitle()
 	mw.refreshTable()
 
 	// Затем сразу предлагаем сохранить
@@ -442,7 +470,7 @@ func (mw *MainWindow) onSaveAsWithPrompt() {
 		defer writer.Close()
 
 		filePath := uriToPath(writer.URI())
-		if !strings.HasSuffix(strings.ToLower(filePath), ".csv") {
+		if !strings.HasSuffix(strings.ToLower(filePath), ".csv") && !controller.IsDatabaseFile(filePath) {
 			filePath += ".csv"
 		}
 
@@ -460,7 +488,6 @@ func (mw *MainWindow) onSaveAsWithPrompt() {
 						return
 					}
 					mw.currentFile = filePath
-					mw.unsavedChanges = false
 					mw.updateWindowTitle()
 					mw.showNotification("Файл успешно сохранён")
 					mw.refreshTable()
@@ -469,8 +496,8 @@ func (mw *MainWindow) onSaveAsWithPrompt() {
 		})
 	}, mw.window)
 
-	// Настраиваем фильтр для CSV файлов
-	saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+	// Настраиваем фильтр для CSV файлов и баз SQLite
+	saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".db"}))
 
 	// Устанавливаем начальную директорию
 	if mw.currentFile != "" {
@@ -553,104 +580,115 @@ func (mw *MainWindow) showEditDialog(manufacturer *model.Manufacturer, isNew boo
 	nameEntry := widget.NewEntry()
 	nameEntry.SetText(manufacturer.Name)
 
-	countryEntry := widget.NewEntry()
-	countryEntry.SetText(manufacturer.Country)
+	// Страна выбирается из справочника ISO 3166: список подсказок
+	// сужается по мере ввода, а сохраняется код страны
+	countryEntry := widget.NewSelectEntry(countryOptions("", mw.locale.Language))
+	countryEntry.SetText(country.Display(manufacturer.Country, mw.locale.Language))
+	countryEntry.OnChanged = func(text string) {
+		countryEntry.SetOptions(countryOptions(text, mw.locale.Language))
+	}
 
+	// Адрес одной строкой собирается из частей адреса. Если изменить
+	// саму строку, при сохранении она будет разобрана на части заново.
 	addressEntry := widget.NewEntry()
 	addressEntry.SetText(manufacturer.Address)
+	joinedAddress := manufacturer.Address
+	streetEntry := widget.NewEntry()
+	streetEntry.SetText(manufacturer.Street)
+	cityEntry := widget.NewEntry()
+	cityEntry.SetText(manufacturer.City)
+	regionEntry := widget.NewEntry()
+	regionEntry.SetText(manufacturer.Region)
+	postalCodeEntry := widget.NewEntry()
+	postalCodeEntry.SetText(manufacturer.PostalCode)
+	// Страна в адрес одной строкой не входит, поэтому ее изменение
+	// адрес не пересчитывает
+	addressParts := func() address.Address {
+		return address.Address{
+			Street:     strings.TrimSpace(streetEntry.Text),
+			City:       strings.TrimSpace(cityEntry.Text),
+			Region:     strings.TrimSpace(regionEntry.Text),
+			PostalCode: strings.TrimSpace(postalCodeEntry.Text),
+		}
+	}
+	joinAddress := func(string) {
+		parts := addressParts()
+		if parts.Street == "" && parts.City == "" && parts.Region == "" && parts.PostalCode == "" {
+			return
+		}
+		joinedAddress = parts.String()
+		addressEntry.SetText(joinedAddress)
+	}
+	streetEntry.OnChanged = joinAddress
+	cityEntry.OnChanged = joinAddress
+	regionEntry.OnChanged = joinAddress
+	postalCodeEntry.OnChanged = joinAddress
 
-	phoneEntry := widget.NewEntry()
-	phoneEntry.SetText(manufacturer.Phone)
+	// Телефон хранится в E.164 и редактируется в международном формате.
+	// Неверный номер отмечается сразу; измененный неверный номер не сохраняется.
+	phoneEntry := newPhoneEntry(manufacturer.Phone, func() string { return countryEntry.Text })
 
 	emailEntry := widget.NewEntry()
 	emailEntry.SetText(manufacturer.Email)
 
-	// Получаем список существующих типов продукции
-	productTypes := mw.controller.GetUniqueProductTypes()
-	
 	// Создаем элементы формы
 	formItems := []*widget.FormItem{
 		{Text: mw.locale.Translate("Name"), Widget: nameEntry},
 		{Text: mw.locale.Translate("Country"), Widget: countryEntry},
 		{Text: mw.locale.Translate("Address"), Widget: addressEntry},
+		{Text: mw.locale.Translate("Street"), Widget: streetEntry},
+		{Text: mw.locale.Translate("City"), Widget: cityEntry},
+		{Text: mw.locale.Translate("Region"), Widget: regionEntry},
+		{Text: mw.locale.Translate("Postal Code"), Widget: postalCodeEntry},
 		{Text: mw.locale.Translate("Phone"), Widget: phoneEntry},
 		{Text: mw.locale.Translate("Email"), Widget: emailEntry},
 	}
 
-	// Создаем виджеты для типа продукции
-	productTypeEntry := widget.NewEntry()
-	productTypeEntry.SetText(manufacturer.ProductType)
-	
-	var productTypeSelect *widget.Select
-	var productTypeContainer *fyne.Container
-	var useExistingType bool
-
-	if len(productTypes) > 0 {
-		// Создаем выпадающий список с существующими типами
-		productTypeSelect = widget.NewSelect(productTypes, nil)
-		if manufacturer.ProductType != "" && contains(productTypes, manufacturer.ProductType) {
-			productTypeSelect.SetSelected(manufacturer.ProductType)
-			useExistingType = true
-		}
+	// Типы продукции выбираются из справочника файла; новые типы
+	// вводятся через запятую и попадают в справочник при сохранении
+	productTypesEditor := mw.newProductTypesEditor(manufacturer.ProductTypes())
 
-		// Создаем радио-кнопки для выбора режима
-		radioGroup := widget.NewRadioGroup(
-			[]string{
-				mw.locale.Translate("Use Existing Type"),
-				mw.locale.Translate("Add New Type"),
-			},
-			func(selected string) {
-				useExistingType = selected == mw.locale.Translate("Use Existing Type")
-				if useExistingType {
-					productTypeContainer.Objects[0] = productTypeSelect
-				} else {
-					productTypeContainer.Objects[0] = productTypeEntry
-				}
-				productTypeContainer.Refresh()
-			},
-		)
+	foundedYearEntry := widget.NewEntry()
+	foundedYearEntry.SetText(fmt.Sprintf("%d", manufacturer.FoundedYear))
 
-		// Добавляем радио-группу в форму
-		formItems = append(formItems, &widget.FormItem{
-			Text:   mw.locale.Translate("Product Type Selection"),
-			Widget: radioGroup,
-		})
+	revenueEntry := widget.NewEntry()
+	revenueEntry.SetText(fmt.Sprintf("%.2f", manufacturer.Revenue))
 
-		// Инициализируем контейнер с правильным виджетом
-		if useExistingType {
-			productTypeContainer = container.NewHBox(productTypeSelect)
-			radioGroup.SetSelected(mw.locale.Translate("Use Existing Type"))
+	// Валюта выручки; пусто — базовая валюта таблицы курсов
+	currencyEntry := widget.NewSelectEntry(mw.controller.Currencies())
+	currencyEntry.SetText(manufacturer.Currency)
+
+	// При заполненной истории выручка — выручка последнего года истории
+	historyEditor := mw.newHistoryEditor(manufacturer.History)
+	historyEditor.onChanged = func() {
+		history, err := historyEditor.history()
+		if latest, ok := history.Latest(); err == nil && ok {
+			revenueEntry.SetText(fmt.Sprintf("%.2f", latest.Revenue))
+			revenueEntry.Disable()
 		} else {
-			productTypeContainer = container.NewHBox(productTypeEntry)
-			radioGroup.SetSelected(mw.locale.Translate("Add New Type"))
+			revenueEntry.Enable()
 		}
-	} else {
-		// Если нет существующих типов, показываем только поле ввода
-		productTypeContainer = container.NewHBox(productTypeEntry)
 	}
+	historyEditor.onChanged()
 
-	foundedYearEntry := widget.NewEntry()
-	foundedYearEntry.SetText(fmt.Sprintf("%d", manufacturer.FoundedYear))
-
-	revenueEntry := widget.NewEntry()
-	revenueEntry.SetText(fmt.Sprintf("%.2f", manufacturer.Revenue))
+	contactsEditor := mw.newContactsEditor(manufacturer.Contacts, func() string { return countryEntry.Text })
+	customEditor := mw.newCustomFieldsEditor(manufacturer)
 
 	// Добавляем оставшиеся поля формы
 	formItems = append(formItems,
-		&widget.FormItem{Text: mw.locale.Translate("Product Type"), Widget: productTypeContainer},
+		&widget.FormItem{Text: mw.locale.Translate("Product Types"), Widget: productTypesEditor.widget()},
 		&widget.FormItem{Text: mw.locale.Translate("Founded Year"), Widget: foundedYearEntry},
 		&widget.FormItem{Text: mw.locale.Translate("Revenue"), Widget: revenueEntry},
+		&widget.FormItem{Text: mw.locale.Translate("Currency"), Widget: currencyEntry},
+		&widget.FormItem{Text: mw.locale.Translate("Revenue History"), Widget: historyEditor.widget()},
+		&widget.FormItem{Text: mw.locale.Translate("Contacts"), Widget: contactsEditor.widget()},
 	)
+	formItems = append(formItems, customEditor.formItems(mw)...)
 
 	form := &widget.Form{
 		Items: formItems,
 		OnSubmit: func() {
-			// Валидация данных
-			if nameEntry.Text == "" {
-				dialog.ShowError(errors.New(mw.locale.Translate("Name cannot be empty")), mw.window)
-				return
-			}
-
+			// Формат чисел проверяется здесь, остальное — правилами проверки
 			year, yearErr := strconv.Atoi(foundedYearEntry.Text)
 			if yearErr != nil {
 				dialog.ShowError(errors.New(mw.locale.Translate("Invalid year format")), mw.window)
@@ -663,48 +701,77 @@ func (mw *MainWindow) showEditDialog(manufacturer *model.Manufacturer, isNew boo
 				return
 			}
 
-			// Получаем значение типа продукции
-			var productType string
-			if len(productTypes) > 0 && useExistingType && productTypeSelect.Selected != "" {
-				productType = productTypeSelect.Selected
-			} else {
-				productType = productTypeEntry.Text
+			history, historyErr := historyEditor.history()
+			if historyErr != nil {
+				dialog.ShowError(fmt.Errorf(mw.locale.Translate("Invalid revenue history: %s"), historyErr), mw.window)
+				return
 			}
 
-			if productType == "" {
-				dialog.ShowError(errors.New(mw.locale.Translate("Product type cannot be empty")), mw.window)
+			phoneNumber, phoneErr := phoneEntry.number(countryEntry.Text)
+			if phoneErr != nil {
+				dialog.ShowError(fmt.Errorf(mw.locale.Translate("Invalid phone number: %s"), phoneErr), mw.window)
 				return
 			}
 
-			// Обновляем данные производителя
-			manufacturer.Name = nameEntry.Text
-			manufacturer.Country = countryEntry.Text
-			manufacturer.Address = addressEntry.Text
-			manufacturer.Phone = phoneEntry.Text
-			manufacturer.Email = emailEntry.Text
-			manufacturer.ProductType = productType
-			manufacturer.FoundedYear = year
-			manufacturer.Revenue = revenue
-
-			var err error
-			if isNew {
-				err = mw.controller.AddManufacturer(manufacturer)
-			} else {
-				err = mw.controller.UpdateManufacturer(manufacturer)
+			productTypes := productTypesEditor.productTypes()
+			if len(productTypes) == 0 {
+				dialog.ShowError(errors.New(mw.locale.Translate("Product type cannot be empty")), mw.window)
+				return
 			}
 
-			if err != nil {
+			// Изменения применяются к записи только после проверки
+			edited := *manufacturer
+			edited.Name = nameEntry.Text
+			edited.Country, _ = country.Normalize(countryEntry.Text)
+			parts := addressParts()
+			edited.Street, edited.City, edited.Region, edited.PostalCode = parts.Street, parts.City, parts.Region, parts.PostalCode
+			edited.Address = strings.TrimSpace(addressEntry.Text)
+			if edited.Address != joinedAddress {
+				edited.Street, edited.City, edited.Region, edited.PostalCode = "", "", "", ""
+				address.Split(&edited)
+			}
+			address.Join(&edited)
+			edited.Phone = phoneNumber
+			edited.Email = emailEntry.Text
+			edited.SetProductTypes(productTypes)
+			edited.FoundedYear = year
+			edited.Revenue = revenue
+			edited.Currency, _ = currency.Normalize(currencyEntry.Text)
+			edited.History = history
+			edited.SyncLatest()
+			contacts, contactsErr := contactsEditor.contacts(countryEntry.Text)
+			if contactsErr != nil {
+				dialog.ShowError(fmt.Errorf(mw.locale.Translate("Invalid phone number: %s"), contactsErr), mw.window)
+				return
+			}
+			edited.Contacts = contacts
+			if err := customEditor.apply(&edited); err != nil {
 				dialog.ShowError(err, mw.window)
 				return
 			}
 
-			mw.unsavedChanges = true
-			mw.refreshTable()
-			dialog.ShowInformation(
-				mw.locale.Translate("Success"),
-				mw.locale.Translate("Manufacturer saved successfully"),
-				mw.window,
-			)
+			mw.confirmViolations(mw.controller.ValidateManufacturer(&edited), func() {
+				*manufacturer = edited
+				var err error
+				if isNew {
+					err = mw.controller.AddManufacturer(manufacturer)
+				} else {
+					err = mw.controller.UpdateManufacturer(manufacturer)
+				}
+
+				if err != nil {
+					dialog.ShowError(err, mw.window)
+					return
+				}
+
+				mw.updateWindowTitle()
+				mw.refreshTable()
+				dialog.ShowInformation(
+					mw.locale.Translate("Success"),
+					mw.locale.Translate("Manufacturer saved successfully"),
+					mw.window,
+				)
+			})
 		},
 	}
 
@@ -727,14 +794,55 @@ func (mw *MainWindow) showEditDialog(manufacturer *model.Manufacturer, isNew boo
 	)
 }
 
-// Вспомогательная функция для проверки наличия строки в слайсе
-func contains(slice []string, str string) bool {
-	for _, v := range slice {
-		if v == str {
-			return true
-		}
+// setupShortcuts назначает горячие клавиши отмены и повтора
+// и следит за клавишей Shift для сортировки по нескольким столбцам
+func (mw *MainWindow) setupShortcuts() {
+	windowCanvas := mw.window.Canvas()
+	if keys, ok := windowCanvas.(desktop.Canvas); ok {
+		keys.SetOnKeyDown(func(ev *fyne.KeyEvent) {
+			if ev.Name == desktop.KeyShiftLeft || ev.Name == desktop.KeyShiftRight {
+				mw.shiftDown = true
+			}
+		})
+		keys.SetOnKeyUp(func(ev *fyne.KeyEvent) {
+			if ev.Name == desktop.KeyShiftLeft || ev.Name == desktop.KeyShiftRight {
+				mw.shiftDown = false
+			}
+		})
+	}
+	windowCanvas.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: desktop.ControlModifier},
+		func(fyne.Shortcut) { mw.onUndo() })
+	windowCanvas.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyY, Modifier: desktop.ControlModifier},
+		func(fyne.Shortcut) { mw.onRedo() })
+	windowCanvas.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: desktop.ControlModifier | desktop.ShiftModifier},
+		func(fyne.Shortcut) { mw.onRedo() })
+}
+
+func (mw *MainWindow) onUndo() {
+	name, err := mw.controller.Undo()
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
 	}
-	return false
+	mw.afterHistoryStep(mw.locale.Translate("Undone") + ": " + mw.locale.Translate(name))
+}
+
+func (mw *MainWindow) onRedo() {
+	name, err := mw.controller.Redo()
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	mw.afterHistoryStep(mw.locale.Translate("Redone") + ": " + mw.locale.Translate(name))
+}
+
+// afterHistoryStep обновляет окно после отмены или повтора действия
+func (mw *MainWindow) afterHistoryStep(message string) {
+	mw.isSearching = false
+	mw.searchResults = nil
+	mw.updateWindowTitle()
+	mw.refreshTable()
+	mw.showNotification(message)
 }
 
 func (mw *MainWindow) onDeleteWithConfirmation(id int) {
@@ -786,7 +894,7 @@ func (mw *MainWindow) onDeleteWithConfirmation(id int) {
 					}
 
 					// Обновляем интерфейс
-					mw.unsavedChanges = false
+					mw.updateWindowTitle()
 					mw.refreshTable()
 
 					// Показываем уведомление
@@ -948,10 +1056,12 @@ func (mw *MainWindow) createManufacturersTable() *widget.Table {
 	} else {
 		manufacturers = mw.controller.GetCurrentData()
 	}
+	extra := mw.extraTableFields()
+	columns := tableColumnsWith(extra)
 
 	table := widget.NewTable(
 		func() (int, int) {
-			return len(manufacturers) + 1, 9
+			return len(manufacturers) + 1, len(columns)
 		},
 		func() fyne.CanvasObject {
 			return widget.NewLabel("template")
@@ -962,8 +1072,11 @@ func (mw *MainWindow) createManufacturersTable() *widget.Table {
 				// Заполняем заголовки
 				headers := []string{"Id", "Name", "Country", "Address", "Phone",
 					"Email", "Product Type", "Founded Year", "Revenue"}
+				for _, f := range extra {
+					headers = append(headers, f.Header)
+				}
 				if tci.Col < len(headers) {
-					label.SetText(headers[tci.Col])
+					label.SetText(headers[tci.Col] + sortMarker(*mw.sortState(), columns[tci.Col]))
 				}
 			} else if tci.Row-1 < len(manufacturers) {
 				// Заполняем данные
@@ -974,11 +1087,11 @@ func (mw *MainWindow) createManufacturersTable() *widget.Table {
 				case 1:
 					label.SetText(m.Name)
 				case 2:
-					label.SetText(m.Country)
+					label.SetText(country.Display(m.Country, mw.locale.Language))
 				case 3:
 					label.SetText(m.Address)
 				case 4:
-					label.SetText(m.Phone)
+					label.SetText(phone.Format(m.Phone, mw.locale.Language))
 				case 5:
 					label.SetText(m.Email)
 				case 6:
@@ -986,7 +1099,12 @@ func (mw *MainWindow) createManufacturersTable() *widget.Table {
 				case 7:
 					label.SetText(fmt.Sprintf("%d", m.FoundedYear))
 				case 8:
-					label.SetText(fmt.Sprintf("%.2f", m.Revenue))
+					label.SetText(currency.Format(m.Revenue, m.Currency))
+				default:
+					// Теги и пользовательские поля
+					if i := tci.Col - len(tableColumns); i < len(extra) {
+						label.SetText(extra[i].Get(&m))
+					}
 				}
 			}
 		},
@@ -1002,14 +1120,14 @@ func (mw *MainWindow) createManufacturersTable() *widget.Table {
 	table.SetColumnWidth(6, 150)  // Product Type
 	table.SetColumnWidth(7, 120)  // Founded Year
 	table.SetColumnWidth(8, 150)  // Revenue
+	for i := range extra {
+		table.SetColumnWidth(len(tableColumns)+i, 150)
+	}
 
 	table.OnSelected = func(id widget.TableCellID) {
-		if id.Row == 0 { // Сортировка по заголовку
-			columns := []string{"id", "name", "country", "address", "phone",
-				"email", "productType", "foundedYear", "revenue"}
-
+		if id.Row == 0 { // Сортировка по заголовку, с Shift — по нескольким столбцам
 			if id.Col < len(columns) {
-				ascending := !mw.currentSort.ascending
+				keys := toggleSort(*mw.sortState(), columns[id.Col], mw.shiftDown)
 				var dataToSort []model.Manufacturer
 
 				if mw.isSearching {
@@ -1018,7 +1136,7 @@ func (mw *MainWindow) createManufacturersTable() *widget.Table {
 					dataToSort = mw.controller.GetCurrentData()
 				}
 
-				sorted, err := mw.controller.Sort(dataToSort, columns[id.Col], ascending)
+				sorted, err := mw.controller.SortBy(dataToSort, keys)
 				if err != nil {
 					dialog.ShowError(err, mw.window)
 					return
@@ -1027,12 +1145,14 @@ func (mw *MainWindow) createManufacturersTable() *widget.Table {
 				if mw.isSearching {
 					mw.searchResults = sorted
 				} else {
-					// Для основного набора нужно обновить данные в контроллере
-					mw.controller.UpdateManufacturers(sorted)
+					// Для основного набора сортировка — отменяемое действие
+					if err := mw.controller.ReplaceManufacturers("Sort", sorted); err != nil {
+						dialog.ShowError(err, mw.window)
+						return
+					}
 				}
 
-				mw.currentSort.column = columns[id.Col]
-				mw.currentSort.ascending = ascending
+				*mw.sortState() = keys
 				mw.refreshTable()
 			}
 		} else {
@@ -1046,12 +1166,56 @@ func (mw *MainWindow) createManufacturersTable() *widget.Table {
 	return table
 }
 
-// Вспомогательная функция для отображения иконки сортировки
-func (mw *MainWindow) getSortIcon() string {
-	if mw.currentSort.ascending {
-		return "^"
+// Ключи столбцов таблицы в порядке отображения
+var tableColumns = []string{"id", "name", "country", "address", "phone",
+	"email", "productType", "foundedYear", "revenue"}
+
+// sortState возвращает сортировку активной вкладки: у каждого
+// открытого файла она своя и сохраняется при переключении вкладок
+func (mw *MainWindow) sortState() *[]repository.SortKey {
+	if openFile, exists := mw.openFiles[mw.activeFile]; exists {
+		return &openFile.Sort
 	}
-	return "!^!"
+	return &mw.sortKeys
+}
+
+// toggleSort возвращает новые ключи сортировки после щелчка по заголовку.
+// Повторный щелчок меняет направление. С Shift столбец добавляется
+// следующим ключом, без Shift сортировка идет только по нему.
+func toggleSort(current []repository.SortKey, column string, multi bool) []repository.SortKey {
+	keys := make([]repository.SortKey, 0, len(current)+1)
+	if multi {
+		keys = append(keys, current...)
+	}
+
+	for i := range keys {
+		if keys[i].Field == column {
+			keys[i].Ascending = !keys[i].Ascending
+			return keys
+		}
+	}
+	if len(current) == 1 && current[0].Field == column && !multi {
+		return []repository.SortKey{{Field: column, Ascending: !current[0].Ascending}}
+	}
+	return append(keys, repository.SortKey{Field: column, Ascending: true})
+}
+
+// sortMarker — стрелка направления и номер ключа для заголовка столбца
+func sortMarker(keys []repository.SortKey, column string) string {
+	for i, key := range keys {
+		if key.Field != column {
+			continue
+		}
+		marker := " ↓"
+		if key.Ascending {
+			marker = " ↑"
+		}
+		if len(keys) > 1 {
+			marker += strconv.Itoa(i + 1)
+		}
+		return marker
+	}
+	return ""
 }
 
 // Создание контекстного меню
@@ -1172,14 +1336,34 @@ func uriToPath(uri fyne.URI) string {
 	return path
 }
 
+// updateWindowTitle показывает в заголовке имя файла и звездочку,
+// если в нем есть несохраненные изменения
 func (mw *MainWindow) updateWindowTitle() {
 	title := mw.locale.Translate("База данных производителей")
 	if mw.currentFile != "" {
 		title += " - " + filepath.Base(mw.currentFile)
 	}
+
+	changes := mw.controller.Changes()
+	if openFile, exists := mw.openFiles[mw.activeFile]; exists {
+		openFile.UnsavedChanges = !changes.Empty()
+	}
+	if !changes.Empty() {
+		title += " *"
+	}
 	mw.window.SetTitle(title)
 }
 
+// changesSummary описывает несохраненные изменения для диалога подтверждения
+func (mw *MainWindow) changesSummary(changes controller.ChangeSet) string {
+	summary := fmt.Sprintf(mw.locale.Translate("Added: %d, modified: %d, deleted: %d"),
+		len(changes.Added), len(changes.Modified), len(changes.Deleted))
+	if changes.Settings {
+		summary += "\n" + mw.locale.Translate("Custom fields or product types changed")
+	}
+	return summary
+}
+
 func (mw *MainWindow) runInUI(f func()) {
 	// Самый надежный способ выполнить код в UI-потоке в Fyne 1.x
 	time.AfterFunc(10*time.Millisecond, func() {
@@ -1262,6 +1446,34 @@ func (mw *MainWindow) onExportJSON() {
 	saveDialog.Show()
 }
 
+func (mw *MainWindow) onExportXLSX() {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		filePath := uriToPath(writer.URI())
+		if !strings.HasSuffix(strings.ToLower(filePath), ".xlsx") {
+			filePath += ".xlsx"
+		}
+
+		if err := mw.controller.ExportToXLSX(filePath); err != nil {
+			dialog.ShowError(fmt.Errorf("export failed: %v", err), mw.window)
+			return
+		}
+
+		mw.showNotification(mw.locale.Translate("Excel exported successfully"))
+	}, mw.window)
+
+	saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".xlsx"}))
+	saveDialog.Show()
+}
+
 func (mw *MainWindow) onShowChart() {
 	// Создаем селектор типа графика
 	chartTypeSelect := widget.NewSelect([]string{
@@ -1269,9 +1481,20 @@ func (mw *MainWindow) onShowChart() {
 		mw.locale.Translate("Bar Chart - Founded Year"),
 		mw.locale.Translate("Pie Chart - Product Types"),
 		mw.locale.Translate("Line Chart - Revenue Trend"),
+		mw.locale.Translate("Line Chart - Revenue Growth"),
+		mw.locale.Translate("Bar Chart - Revenue CAGR"),
+		mw.locale.Translate("Bar Chart - Cities"),
 	}, nil)
 	chartTypeSelect.SetSelected(mw.locale.Translate("Bar Chart - Revenue"))
 
+	// Графики по истории выручки строятся по производителям или по типам продукции
+	groupOptions := []string{
+		mw.locale.Translate("Manufacturer"),
+		mw.locale.Translate("Product Type"),
+	}
+	groupBySelect := widget.NewSelect(groupOptions, nil)
+	groupBySelect.SetSelected(groupOptions[0])
+
 	// Создаем селектор цветовой схемы
 	colorSchemeSelect := widget.NewSelect([]string{
 		mw.locale.Translate("Default"),
@@ -1311,6 +1534,12 @@ func (mw *MainWindow) onShowChart() {
 			chartType = "product_pie"
 		case mw.locale.Translate("Line Chart - Revenue Trend"):
 			chartType = "revenue_line"
+		case mw.locale.Translate("Line Chart - Revenue Growth"):
+			chartType = "revenue_growth"
+		case mw.locale.Translate("Bar Chart - Revenue CAGR"):
+			chartType = "revenue_cagr"
+		case mw.locale.Translate("Bar Chart - Cities"):
+			chartType = "city_bar"
 		}
 
 		// Создаем параметры для графика
@@ -1319,6 +1548,10 @@ func (mw *MainWindow) onShowChart() {
 			"colorScheme": colorSchemeSelect.Selected,
 			"showValues":  showValuesCheck.Checked,
 			"sortData":    sortDataCheck.Checked,
+			"groupBy":     controller.GroupByManufacturer,
+		}
+		if groupBySelect.Selected == groupOptions[1] {
+			chartParams["groupBy"] = controller.GroupByProductType
 		}
 
 		// Генерируем график
@@ -1396,6 +1629,8 @@ func (mw *MainWindow) onShowChart() {
 			chartTypeSelect,
 			widget.NewLabel(mw.locale.Translate("Color Scheme:")),
 			colorSchemeSelect,
+			widget.NewLabel(mw.locale.Translate("Group By:")),
+			groupBySelect,
 		),
 		container.NewHBox(
 			showValuesCheck,
@@ -1444,6 +1679,17 @@ func (mw *MainWindow) showSearchWindow() {
 	searchEntry.SetPlaceHolder(mw.locale.Translate("Enter search text..."))
 	mw.searchEntry = searchEntry
 
+	// Подсказка по языку запросов
+	syntaxHint := widget.NewLabel(mw.locale.Translate("Query example:") +
+		"\ncountry:Russia revenue>1000 founded:1990..2005 -productType:Dye \"exact phrase\"")
+	syntaxHint.Wrapping = fyne.TextWrapWord
+
+	// Фильтр по типу продукции: производитель с несколькими типами
+	// находится по любому из них
+	allTypes := mw.locale.Translate("All Product Types")
+	typeFilter := widget.NewSelect(append([]string{allTypes}, mw.controller.ProductTypes()...), nil)
+	typeFilter.SetSelected(allTypes)
+
 	// Создаем метку для отображения текущего поискового запроса
 	searchLabel := widget.NewLabel("")
 	
@@ -1452,6 +1698,10 @@ func (mw *MainWindow) showSearchWindow() {
 
 	// Обработчик изменения текста
 	searchEntry.OnChanged = func(query string) {
+		if typeFilter.Selected != "" && typeFilter.Selected != allTypes {
+			query = strings.TrimSpace(fmt.Sprintf("productType=%q %s", typeFilter.Selected, query))
+		}
+
 		// Обновляем метку с текущим поисковым запросом
 		searchLabel.SetText(mw.locale.Translate("Current search: ") + query)
 		searchLabel.Refresh()
@@ -1464,28 +1714,15 @@ func (mw *MainWindow) showSearchWindow() {
 			return
 		}
 
-		// Получаем текущие данные
-		currentData := mw.controller.GetCurrentData()
-		if len(currentData) == 0 {
+		// Выполняем запрос. Пока запрос набирается, ошибка
+		// показывается в метке, а таблица остается прежней.
+		results, err := mw.controller.Search(query)
+		if err != nil {
+			searchLabel.SetText(mw.locale.Translate("Query error: ") + err.Error())
+			searchLabel.Refresh()
 			return
 		}
 
-		// Выполняем поиск
-		var results []model.Manufacturer
-		query = strings.ToLower(query)
-		for _, m := range currentData {
-			if strings.Contains(strings.ToLower(m.Name), query) ||
-				strings.Contains(strings.ToLower(m.Country), query) ||
-				strings.Contains(strings.ToLower(m.Address), query) ||
-				strings.Contains(m.Phone, query) ||
-				strings.Contains(strings.ToLower(m.Email), query) ||
-				strings.Contains(strings.ToLower(m.ProductType), query) ||
-				strings.Contains(fmt.Sprintf("%d", m.FoundedYear), query) ||
-				strings.Contains(fmt.Sprintf("%.2f", m.Revenue), query) {
-				results = append(results, m)
-			}
-		}
-
 		// Формируем текст для отображения результатов
 		var resultsText strings.Builder
 		for _, m := range results {
@@ -1502,6 +1739,10 @@ func (mw *MainWindow) showSearchWindow() {
 		mw.refreshTable()
 	}
 
+	typeFilter.OnChanged = func(string) {
+		searchEntry.OnChanged(searchEntry.Text)
+	}
+
 	// Создаем кнопку закрытия
 	closeButton := widget.NewButton(mw.locale.Translate("Close"), func() {
 		mw.searchWindow.Hide()
@@ -1509,6 +1750,7 @@ func (mw *MainWindow) showSearchWindow() {
 
 	// Создаем кнопку очистки
 	clearButton := widget.NewButton(mw.locale.Translate("Clear"), func() {
+		typeFilter.SetSelected(allTypes)
 		searchEntry.SetText("")
 		searchLabel.SetText("")
 		resultsList.SetText("")
@@ -1520,6 +1762,8 @@ func (mw *MainWindow) showSearchWindow() {
 	// Создаем контейнер с элементами
 	content := container.NewVBox(
 		searchEntry,
+		typeFilter,
+		syntaxHint,
 		searchLabel,
 		container.NewHBox(clearButton, closeButton),
 		widget.NewSeparator(),
@@ -1655,9 +1899,12 @@ func (mw *MainWindow) createFileTab(filePath string, manufacturers []model.Manuf
 
 // Создание таблицы для конкретного файла
 func (mw *MainWindow) createTableForFile(manufacturers []model.Manufacturer, filePath string) *widget.Table {
+	extra := mw.extraTableFields()
+	columns := tableColumnsWith(extra)
+
 	table := widget.NewTable(
 		func() (int, int) {
-			return len(manufacturers) + 1, 9
+			return len(manufacturers) + 1, len(columns)
 		},
 		func() fyne.CanvasObject {
 			return widget.NewLabel("template")
@@ -1668,8 +1915,11 @@ func (mw *MainWindow) createTableForFile(manufacturers []model.Manufacturer, fil
 				// Заполняем заголовки
 				headers := []string{"Id", "Name", "Country", "Address", "Phone",
 					"Email", "Product Type", "Founded Year", "Revenue"}
+				for _, f := range extra {
+					headers = append(headers, f.Header)
+				}
 				if tci.Col < len(headers) {
-					label.SetText(headers[tci.Col])
+					label.SetText(headers[tci.Col] + mw.fileSortMarker(filePath, columns[tci.Col]))
 				}
 			} else if tci.Row-1 < len(manufacturers) {
 				// Заполняем данные
@@ -1680,11 +1930,11 @@ func (mw *MainWindow) createTableForFile(manufacturers []model.Manufacturer, fil
 				case 1:
 					label.SetText(m.Name)
 				case 2:
-					label.SetText(m.Country)
+					label.SetText(country.Display(m.Country, mw.locale.Language))
 				case 3:
 					label.SetText(m.Address)
 				case 4:
-					label.SetText(m.Phone)
+					label.SetText(phone.Format(m.Phone, mw.locale.Language))
 				case 5:
 					label.SetText(m.Email)
 				case 6:
@@ -1692,7 +1942,12 @@ func (mw *MainWindow) createTableForFile(manufacturers []model.Manufacturer, fil
 				case 7:
 					label.SetText(fmt.Sprintf("%d", m.FoundedYear))
 				case 8:
-					label.SetText(fmt.Sprintf("%.2f", m.Revenue))
+					label.SetText(currency.Format(m.Revenue, m.Currency))
+				default:
+					// Теги и пользовательские поля
+					if i := tci.Col - len(tableColumns); i < len(extra) {
+						label.SetText(extra[i].Get(&m))
+					}
 				}
 			}
 		},
@@ -1708,6 +1963,15 @@ func (mw *MainWindow) createTableForFile(manufacturers []model.Manufacturer, fil
 	table.SetColumnWidth(6, 150)  // Product Type
 	table.SetColumnWidth(7, 120)  // Founded Year
 	table.SetColumnWidth(8, 150)  // Revenue
+	for i := range extra {
+		table.SetColumnWidth(len(tableColumns)+i, 150)
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row == 0 && id.Col < len(columns) {
+			mw.sortFileTab(filePath, columns[id.Col])
+		}
+	}
 
 	// Настраиваем drag-and-drop
 	mw.setupDragAndDrop(table, manufacturers, filePath)
@@ -1715,14 +1979,56 @@ func (mw *MainWindow) createTableForFile(manufacturers []model.Manufacturer, fil
 	return table
 }
 
+func (mw *MainWindow) fileSortMarker(filePath, column string) string {
+	if openFile, exists := mw.openFiles[filePath]; exists {
+		return sortMarker(openFile.Sort, column)
+	}
+	return ""
+}
+
+// sortFileTab сортирует таблицу вкладки файла по щелчку на заголовке
+func (mw *MainWindow) sortFileTab(filePath, column string) {
+	openFile, exists := mw.openFiles[filePath]
+	if !exists {
+		return
+	}
+
+	keys := toggleSort(openFile.Sort, column, mw.shiftDown)
+	var sorted []model.Manufacturer
+	var err error
+	if filePath == mw.activeFile {
+		sorted, err = mw.controller.SortBy(mw.controller.GetCurrentData(), keys)
+		if err == nil {
+			err = mw.controller.ReplaceManufacturers("Sort", sorted)
+		}
+	} else {
+		sorted, err = repository.SortManufacturers(openFile.Manufacturers, keys, mw.controller.FieldsOf(filePath))
+	}
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+
+	openFile.Manufacturers = sorted
+	openFile.Sort = keys
+	mw.refreshFileTab(filePath)
+}
+
 // Переключение на файл
 func (mw *MainWindow) switchToFile(filePath string) {
 	if openFile, exists := mw.openFiles[filePath]; exists {
+		// Запоминаем данные вкладки, с которой уходим
+		if previous, ok := mw.openFiles[mw.activeFile]; ok && mw.activeFile != filePath {
+			previous.Manufacturers = mw.controller.GetCurrentData()
+		}
+
 		mw.activeFile = filePath
 		mw.currentFile = filePath
 		
-		// Обновляем данные в контроллере
+		// Обновляем данные в контроллере. Изменения и ID контроллер
+		// хранит отдельно для каждого файла.
 		mw.controller.UpdateManufacturers(openFile.Manufacturers)
+		mw.controller.SetCurrentFile(filePath)
 		
 		// Обновляем заголовок окна
 		mw.updateWindowTitle()
@@ -1736,10 +2042,12 @@ func (mw *MainWindow) switchToFile(filePath string) {
 func (mw *MainWindow) closeFile(filePath string) {
 	if openFile, exists := mw.openFiles[filePath]; exists {
 		// Проверяем несохраненные изменения
+		changes := mw.controller.ChangesFor(filePath)
+		openFile.UnsavedChanges = !changes.Empty()
 		if openFile.UnsavedChanges {
 			dialog.ShowConfirm(
 				mw.locale.Translate("Unsaved Changes"),
-				mw.locale.Translate("You have unsaved changes. Do you want to save them?"),
+				mw.locale.Translate("You have unsaved changes. Do you want to save them?")+"\n"+mw.changesSummary(changes),
 				func(save bool) {
 					if save {
 						mw.saveFile(filePath)
@@ -1783,15 +2091,22 @@ func (mw *MainWindow) removeFile(filePath string) {
 // Сохранение конкретного файла
 func (mw *MainWindow) saveFile(filePath string) {
 	if openFile, exists := mw.openFiles[filePath]; exists {
+		if filePath == mw.activeFile {
+			openFile.Manufacturers = mw.controller.GetCurrentData()
+		}
+
 		// Временно обновляем данные в контроллере
 		originalData := mw.controller.GetCurrentData()
+		originalFile := mw.controller.GetCurrentFile()
 		mw.controller.UpdateManufacturers(openFile.Manufacturers)
+		mw.controller.SetCurrentFile(filePath)
 		
 		// Сохраняем файл
 		err := mw.controller.SaveToFile(filePath)
 		
 		// Восстанавливаем оригинальные данные
 		mw.controller.UpdateManufacturers(originalData)
+		mw.controller.SetCurrentFile(originalFile)
 		
 		if err != nil {
 			dialog.ShowError(err, mw.window)
@@ -0,0 +1,204 @@
+package view
+
+import (
+	"cursovay/internal/merge"
+	"cursovay/internal/model"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/widget"
+)
+
+// onMergeFiles выбирает два открытых файла и способ сопоставления записей
+func (mw *MainWindow) onMergeFiles() {
+	if len(mw.openFiles) < 2 {
+		dialog.ShowError(errors.New(mw.locale.Translate("Open at least two files to merge")), mw.window)
+		return
+	}
+
+	names := make(map[string]string, len(mw.openFiles))
+	var options []string
+	for path := range mw.openFiles {
+		name := filepath.Base(path)
+		if _, exists := names[name]; exists {
+			name = path
+		}
+		names[name] = path
+		options = append(options, name)
+	}
+	sort.Strings(options)
+
+	baseSelect := widget.NewSelect(options, nil)
+	otherSelect := widget.NewSelect(options, nil)
+	for _, name := range options {
+		if names[name] == mw.activeFile {
+			baseSelect.SetSelected(name)
+		}
+	}
+
+	matchOptions := []string{
+		mw.locale.Translate("By ID"),
+		mw.locale.Translate("By name and country"),
+		mw.locale.Translate("By email"),
+	}
+	matchSelect := widget.NewSelect(matchOptions, nil)
+	matchSelect.SetSelected(matchOptions[0])
+
+	content := container.NewGridWithColumns(2,
+		widget.NewLabel(mw.locale.Translate("Merge into:")), baseSelect,
+		widget.NewLabel(mw.locale.Translate("Take from:")), otherSelect,
+		widget.NewLabel(mw.locale.Translate("Match records:")), matchSelect,
+	)
+
+	dialog.ShowCustomConfirm(
+		mw.locale.Translate("Merge Files"),
+		mw.locale.Translate("Compare"),
+		mw.locale.Translate("Cancel"),
+		content,
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			basePath, otherPath := names[baseSelect.Selected], names[otherSelect.Selected]
+			if basePath == "" || otherPath == "" || basePath == otherPath {
+				dialog.ShowError(errors.New(mw.locale.Translate("Choose two different files")), mw.window)
+				return
+			}
+
+			// Слияние применяется к базовому файлу, поэтому делаем его текущим
+			mw.switchToFile(basePath)
+			matchBy := merge.MatchBy(0)
+			for i, option := range matchOptions {
+				if option == matchSelect.Selected {
+					matchBy = merge.MatchBy(i)
+				}
+			}
+			plan := merge.Compare(mw.controller.GetCurrentData(), mw.openFiles[otherPath].Manufacturers, matchBy, mw.controller.Fields())
+			mw.showMergeWindow(plan, basePath, otherPath)
+		},
+		mw.window,
+	)
+}
+
+// showMergeWindow показывает добавленные, отсутствующие и конфликтующие
+// записи. Для каждого различающегося поля пользователь выбирает версию.
+func (mw *MainWindow) showMergeWindow(plan *merge.Plan, basePath, otherPath string) {
+	window := mw.app.NewWindow(mw.locale.Translate("Merge Files"))
+	baseName, otherName := filepath.Base(basePath), filepath.Base(otherPath)
+
+	sections := container.NewVBox()
+
+	conflicts := plan.Conflicts()
+	sections.Add(widget.NewLabelWithStyle(
+		fmt.Sprintf(mw.locale.Translate("Conflicts: %d"), len(conflicts)),
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true},
+	))
+	for _, pair := range conflicts {
+		sections.Add(mw.conflictCard(pair, baseName, otherName))
+	}
+
+	sections.Add(widget.NewLabelWithStyle(
+		fmt.Sprintf(mw.locale.Translate("Only in %s (add): %d"), otherName, len(plan.Added)),
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true},
+	))
+	for _, entry := range plan.Added {
+		entry := entry
+		check := widget.NewCheck(describeManufacturer(&entry.Record), func(checked bool) {
+			entry.Include = checked
+		})
+		check.SetChecked(entry.Include)
+		sections.Add(check)
+	}
+
+	sections.Add(widget.NewLabelWithStyle(
+		fmt.Sprintf(mw.locale.Translate("Only in %s (keep): %d"), baseName, len(plan.Removed)),
+		fyne.TextAlignLeading, fyne.TextStyle{Bold: true},
+	))
+	for _, entry := range plan.Removed {
+		entry := entry
+		check := widget.NewCheck(describeManufacturer(&entry.Record), func(checked bool) {
+			entry.Include = checked
+		})
+		check.SetChecked(entry.Include)
+		sections.Add(check)
+	}
+
+	applyButton := widget.NewButton(mw.locale.Translate("Apply merge"), func() {
+		if mw.activeFile != basePath {
+			mw.switchToFile(basePath)
+		}
+		if err := mw.controller.ApplyMerge(plan.Result()); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		mw.afterImport()
+		window.Close()
+		mw.showNotification(mw.locale.Translate("Files merged"))
+	})
+	cancelButton := widget.NewButton(mw.locale.Translate("Cancel"), window.Close)
+
+	window.SetContent(container.NewBorder(
+		widget.NewLabel(fmt.Sprintf("%s ← %s", baseName, otherName)),
+		container.NewHBox(applyButton, cancelButton),
+		nil, nil,
+		container.NewVScroll(sections),
+	))
+	window.Resize(fyne.NewSize(900, 700))
+	window.Show()
+	window.CenterOnScreen()
+}
+
+// conflictCard показывает различающиеся поля записи: слева версия
+// базового файла, справа — второго. Выбранная версия попадет в результат.
+func (mw *MainWindow) conflictCard(pair *merge.Pair, baseName, otherName string) fyne.CanvasObject {
+	grid := container.NewGridWithColumns(2,
+		widget.NewLabelWithStyle(mw.locale.Translate("Field"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(baseName+" | "+otherName, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	)
+	empty := mw.locale.Translate("(empty)")
+	fields := mw.controller.Fields()
+	for _, key := range pair.Fields {
+		key := key
+		field, _ := model.LookupField(fields, key)
+		baseOption, otherOption := field.Get(&pair.Base), field.Get(&pair.Other)
+		if baseOption == "" {
+			baseOption = empty
+		}
+		if otherOption == "" {
+			otherOption = empty
+		}
+
+		radio := widget.NewRadioGroup([]string{baseOption, otherOption}, nil)
+		radio.Horizontal = true
+		radio.Required = true
+		if pair.Choice[key] == merge.Other {
+			radio.SetSelected(otherOption)
+		} else {
+			radio.SetSelected(baseOption)
+		}
+		radio.OnChanged = func(selected string) {
+			if selected == otherOption {
+				pair.Choice[key] = merge.Other
+			} else {
+				pair.Choice[key] = merge.Base
+			}
+		}
+
+		grid.Add(widget.NewLabel(mw.locale.Translate(field.Header)))
+		grid.Add(radio)
+	}
+	return widget.NewCard(describeManufacturer(&pair.Base), "", grid)
+}
+
+// describeManufacturer — краткое описание записи для списков
+func describeManufacturer(m *model.Manufacturer) string {
+	if m.Country == "" {
+		return fmt.Sprintf("#%d %s", m.ID, m.Name)
+	}
+	return fmt.Sprintf("#%d %s (%s)", m.ID, m.Name, m.Country)
+}
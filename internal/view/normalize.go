@@ -0,0 +1,93 @@
+package view
+
+import (
+	"cursovay/internal/controller"
+	"cursovay/internal/country"
+	"cursovay/internal/model"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/dialog"
+)
+
+// onNormalizePhones приводит телефоны всех записей к E.164 и перечисляет
+// записи с номерами, которые не удалось разобрать
+func (mw *MainWindow) onNormalizePhones() {
+	mw.runNormalization(
+		mw.locale.Translate("Normalize Phones"),
+		mw.locale.Translate("Convert all phone numbers to the international format? Numbers without a country code are read using the manufacturer's country."),
+		mw.controller.NormalizePhones,
+		mw.locale.Translate("Phones normalized: %d"),
+		mw.locale.Translate("Invalid numbers: %d"),
+		func(m *model.Manufacturer) string { return strings.Join(controller.InvalidPhones(m), ", ") },
+	)
+}
+
+// onNormalizeCountries заменяет страны всех записей кодами ISO 3166
+// и перечисляет записи, страну которых определить не удалось
+func (mw *MainWindow) onNormalizeCountries() {
+	mw.runNormalization(
+		mw.locale.Translate("Normalize Countries"),
+		mw.locale.Translate("Replace country names, aliases and cities with ISO 3166 country codes?"),
+		mw.controller.NormalizeCountries,
+		mw.locale.Translate("Countries normalized: %d"),
+		mw.locale.Translate("Unknown countries: %d"),
+		func(m *model.Manufacturer) string { return m.Country },
+	)
+}
+
+// onSplitAddresses разбирает адреса одной строкой на части
+// и перечисляет записи, адрес которых разобрать не удалось
+func (mw *MainWindow) onSplitAddresses() {
+	mw.runNormalization(
+		mw.locale.Translate("Split Addresses"),
+		mw.locale.Translate("Split single-line addresses into street, city, region and postal code? Records that already have these fields are not changed."),
+		mw.controller.SplitAddresses,
+		mw.locale.Translate("Addresses split: %d"),
+		mw.locale.Translate("Unparsed addresses: %d"),
+		func(m *model.Manufacturer) string { return m.Address },
+	)
+}
+
+// runNormalization после подтверждения выполняет массовую замену run
+// и показывает итог: сколько записей изменено и какие значения
+// не удалось распознать (value показывает такое значение)
+func (mw *MainWindow) runNormalization(title, question string, run func() (int, []int, error),
+	changedFormat, failedFormat string, value func(*model.Manufacturer) string) {
+	dialog.ShowConfirm(title, question, func(ok bool) {
+		if !ok {
+			return
+		}
+		changed, failed, err := run()
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		mw.afterImport()
+
+		lines := []string{fmt.Sprintf(changedFormat, changed)}
+		if len(failed) > 0 {
+			lines = append(lines, "", fmt.Sprintf(failedFormat, len(failed)))
+			for i, id := range failed {
+				if i == maxListedViolations {
+					lines = append(lines, fmt.Sprintf(mw.locale.Translate("...and %d more"), len(failed)-i))
+					break
+				}
+				if m, err := mw.controller.GetManufacturerByID(id); err == nil {
+					lines = append(lines, fmt.Sprintf("%s: %s", describeManufacturer(m), value(m)))
+				}
+			}
+		}
+		dialog.ShowInformation(title, strings.Join(lines, "\n"), mw.window)
+	}, mw.window)
+}
+
+// countryOptions — названия стран для подсказок, подходящие к тексту
+func countryOptions(text, lang string) []string {
+	found := country.Search(text, lang)
+	options := make([]string, len(found))
+	for i, c := range found {
+		options[i] = c.Name(lang)
+	}
+	return options
+}
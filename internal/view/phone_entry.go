@@ -0,0 +1,46 @@
+package view
+
+import (
+	"cursovay/internal/phone"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/widget"
+)
+
+// phoneEntry — поле ввода телефона в окне редактирования. Номер
+// показывается в международном формате: национальный формат
+// (8 (999) ...) по стране другой записи разобрался бы в чужой номер.
+// Неизмененный текст не разбирается заново, и сохраненный номер
+// остается как был, даже если он не в E.164.
+type phoneEntry struct {
+	*widget.Entry
+	original string // номер из записи
+	shown    string // текст, который был показан в поле
+}
+
+// newPhoneEntry создает поле для номера; country возвращает страну,
+// по которой разбирается номер без кода страны
+func newPhoneEntry(number string, country func() string) *phoneEntry {
+	e := &phoneEntry{Entry: widget.NewEntry(), original: number, shown: phone.International(number)}
+	e.SetText(e.shown)
+	e.Validator = func(string) error {
+		_, err := e.number(country())
+		return err
+	}
+	return e
+}
+
+// number возвращает номер для сохранения: исходный, если текст
+// не менялся, иначе введенный номер в E.164 или ошибку разбора
+func (e *phoneEntry) number(countryName string) (string, error) {
+	if e.Text == e.shown {
+		return e.original, nil
+	}
+	text := strings.TrimSpace(e.Text)
+	number, err := phone.Parse(text, countryName)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", text, err)
+	}
+	return number, nil
+}
@@ -0,0 +1,153 @@
+package view
+
+import (
+	"cursovay/internal/model"
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne"
+	"fyne.io/fyne/container"
+	"fyne.io/fyne/dialog"
+	"fyne.io/fyne/theme"
+	"fyne.io/fyne/widget"
+)
+
+// productTypesEditor — выбор типов продукции в окне редактирования:
+// флажки по справочнику и поле для новых типов через запятую
+type productTypesEditor struct {
+	checks map[string]*widget.Check
+	order  []string
+	entry  *widget.Entry
+}
+
+func (mw *MainWindow) newProductTypesEditor(selected []string) *productTypesEditor {
+	e := &productTypesEditor{checks: make(map[string]*widget.Check), entry: widget.NewEntry()}
+	e.entry.SetPlaceHolder(mw.locale.Translate("New types, comma-separated"))
+	catalogue := mw.controller.ProductTypes()
+	for _, t := range selected {
+		if !model.HasItem(catalogue, t) {
+			catalogue = append(catalogue, t)
+		}
+	}
+	for _, t := range catalogue {
+		check := widget.NewCheck(t, nil)
+		check.SetChecked(model.HasItem(selected, t))
+		e.checks[t] = check
+		e.order = append(e.order, t)
+	}
+	return e
+}
+
+func (e *productTypesEditor) widget() fyne.CanvasObject {
+	grid := container.NewGridWithColumns(3)
+	for _, t := range e.order {
+		grid.Add(e.checks[t])
+	}
+	return container.NewVBox(grid, e.entry)
+}
+
+// productTypes возвращает отмеченные типы и новые типы из поля ввода
+func (e *productTypesEditor) productTypes() []string {
+	var types []string
+	for _, t := range e.order {
+		if e.checks[t].Checked {
+			types = append(types, t)
+		}
+	}
+	return model.SplitList(model.JoinList(append(types, model.SplitList(e.entry.Text)...)))
+}
+
+// onProductTypes показывает справочник типов продукции текущего файла:
+// число производителей каждого типа, добавление, переименование,
+// объединение отмеченных типов и удаление неиспользуемых
+func (mw *MainWindow) onProductTypes() {
+	var dlg dialog.Dialog
+	// reopen закрывает справочник и показывает его заново с новыми данными
+	reopen := func() {
+		dlg.Hide()
+		mw.updateWindowTitle()
+		mw.refreshTable()
+		mw.onProductTypes()
+	}
+
+	selected := make(map[string]bool)
+	rows := container.NewVBox()
+	for _, usage := range mw.controller.ProductTypeUsage() {
+		name := usage.Value
+		check := widget.NewCheck(fmt.Sprintf("%s (%d)", name, usage.Count), func(on bool) {
+			selected[name] = on
+		})
+		rename := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() {
+			entry := dialog.NewEntryDialog(mw.locale.Translate("Rename Product Type"), name, func(newName string) {
+				count, err := mw.controller.RenameProductType(name, newName)
+				if err != nil {
+					dialog.ShowError(err, mw.window)
+					return
+				}
+				mw.showNotification(fmt.Sprintf(mw.locale.Translate("Product types updated in %d records"), count))
+				reopen()
+			}, mw.window)
+			entry.SetText(name)
+			entry.Show()
+		})
+		remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			if err := mw.controller.RemoveProductType(name); err != nil {
+				dialog.ShowError(err, mw.window)
+				return
+			}
+			reopen()
+		})
+		rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(rename, remove), check))
+	}
+
+	newType := widget.NewEntry()
+	newType.SetPlaceHolder(mw.locale.Translate("Product Type"))
+	addButton := widget.NewButtonWithIcon(mw.locale.Translate("Add"), theme.ContentAddIcon(), func() {
+		if err := mw.controller.AddProductType(newType.Text); err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		reopen()
+	})
+
+	mergeButton := widget.NewButton(mw.locale.Translate("Merge Selected")+"...", func() {
+		var sources []string
+		for _, usage := range mw.controller.ProductTypeUsage() {
+			if selected[usage.Value] {
+				sources = append(sources, usage.Value)
+			}
+		}
+		if len(sources) < 2 {
+			dialog.ShowError(errors.New(mw.locale.Translate("Select at least two product types to merge")), mw.window)
+			return
+		}
+		target := widget.NewSelectEntry(sources)
+		target.SetText(sources[0])
+		dialog.ShowCustomConfirm(
+			mw.locale.Translate("Merge Product Types"),
+			mw.locale.Translate("Merge"),
+			mw.locale.Translate("Cancel"),
+			container.NewVBox(widget.NewLabel(mw.locale.Translate("Merge into:")), target),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				count, err := mw.controller.MergeProductTypes(sources, target.Text)
+				if err != nil {
+					dialog.ShowError(err, mw.window)
+					return
+				}
+				mw.showNotification(fmt.Sprintf(mw.locale.Translate("Product types updated in %d records"), count))
+				reopen()
+			},
+			mw.window,
+		)
+	})
+
+	content := container.NewBorder(nil,
+		container.NewVBox(container.NewBorder(nil, nil, nil, addButton, newType), mergeButton),
+		nil, nil, container.NewVScroll(rows))
+	dlg = dialog.NewCustom(mw.locale.Translate("Product Types"), mw.locale.Translate("Close"), content, mw.window)
+	dlg.Resize(fyne.NewSize(450, 450))
+	dlg.Show()
+}
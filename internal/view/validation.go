@@ -0,0 +1,82 @@
+package view
+
+import (
+	"cursovay/internal/model"
+	"cursovay/internal/validation"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/dialog"
+)
+
+// maxListedViolations — сколько нарушений перечислять в окне сообщения
+const maxListedViolations = 15
+
+// confirmViolations показывает все нарушения правил для записи. При ошибках
+// сохранение невозможно, при одних предупреждениях пользователь решает,
+// сохранять ли запись. Без нарушений save вызывается сразу.
+func (mw *MainWindow) confirmViolations(violations validation.Violations, save func()) {
+	if errs := violations.Errors(); len(errs) > 0 {
+		dialog.ShowError(errors.New(mw.describeViolations(errs, false)), mw.window)
+		return
+	}
+	if warnings := violations.Warnings(); len(warnings) > 0 {
+		dialog.ShowConfirm(
+			mw.locale.Translate("Warnings"),
+			mw.describeViolations(warnings, false)+"\n\n"+mw.locale.Translate("Save anyway?"),
+			func(ok bool) {
+				if ok {
+					save()
+				}
+			},
+			mw.window,
+		)
+		return
+	}
+	save()
+}
+
+// confirmSaveViolations проверяет весь файл перед сохранением. Если
+// в записях есть ошибки, пользователь видит их список и может сохранить
+// файл как есть; предупреждения сохранению не мешают.
+func (mw *MainWindow) confirmSaveViolations(save func()) {
+	errs := mw.controller.ValidateAll().Errors()
+	if len(errs) == 0 {
+		save()
+		return
+	}
+	dialog.ShowConfirm(
+		mw.locale.Translate("Validation errors"),
+		mw.describeViolations(errs, true)+"\n\n"+mw.locale.Translate("Save anyway?"),
+		func(ok bool) {
+			if ok {
+				save()
+			}
+		},
+		mw.window,
+	)
+}
+
+// describeViolations перечисляет нарушения по строке на каждое;
+// withID добавляет ID записи
+func (mw *MainWindow) describeViolations(violations validation.Violations, withID bool) string {
+	lines := make([]string, 0, maxListedViolations+1)
+	fields := mw.controller.Fields()
+	for i, v := range violations {
+		if i == maxListedViolations {
+			lines = append(lines, fmt.Sprintf(mw.locale.Translate("...and %d more"), len(violations)-i))
+			break
+		}
+		header := v.Field
+		if field, ok := model.LookupField(fields, v.Field); ok {
+			header = mw.locale.Translate(field.Header)
+		}
+		line := fmt.Sprintf("%s: %s", header, v.Message)
+		if withID {
+			line = fmt.Sprintf("#%d %s", v.ID, line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
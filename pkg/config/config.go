@@ -12,7 +12,53 @@ type AppConfig struct {
 		Width  int `json:"width"`
 		Height int `json:"height"`
 	} `json:"window_size"`
-	RecentFiles []string `json:"recent_files"`
+	RecentFiles []string          `json:"recent_files"`
+	UndoDepth   int               `json:"undo_depth,omitempty"` // 0 — значение по умолчанию
+	Duplicates  *DuplicatesConfig `json:"duplicates,omitempty"`
+	RulesFile   string            `json:"rules_file,omitempty"` // файл правил проверки записей
+	RatesFile   string            `json:"rates_file,omitempty"` // файл курсов валют
+	Currency    string            `json:"currency,omitempty"`   // валюта отчетов; пусто — базовая валюта курсов
+}
+
+// DuplicatesConfig — настройки поиска дубликатов. Без этого раздела
+// используются dedup.DefaultOptions.
+type DuplicatesConfig struct {
+	NameSimilarity float64 `json:"name_similarity"` // от 0 до 1, 0 — не сравнивать названия
+	MatchEmail     bool    `json:"match_email"`
+	MatchPhone     bool    `json:"match_phone"`
+	MatchWebsite   bool    `json:"match_website"`
+}
+
+// RulesPath возвращает путь к файлу правил проверки: rules_file из настроек
+// (относительный путь — от каталога настроек) или rules.json рядом
+// с config.json
+func (c *AppConfig) RulesPath() string {
+	dir, _ := os.UserConfigDir()
+	dir = filepath.Join(dir, "manufacturers-db")
+	switch {
+	case c.RulesFile == "":
+		return filepath.Join(dir, "rules.json")
+	case filepath.IsAbs(c.RulesFile):
+		return c.RulesFile
+	default:
+		return filepath.Join(dir, c.RulesFile)
+	}
+}
+
+// RatesPath возвращает путь к файлу курсов валют: rates_file из настроек
+// (относительный путь — от каталога настроек) или rates.csv рядом
+// с config.json
+func (c *AppConfig) RatesPath() string {
+	dir, _ := os.UserConfigDir()
+	dir = filepath.Join(dir, "manufacturers-db")
+	switch {
+	case c.RatesFile == "":
+		return filepath.Join(dir, "rates.csv")
+	case filepath.IsAbs(c.RatesFile):
+		return c.RatesFile
+	default:
+		return filepath.Join(dir, c.RatesFile)
+	}
 }
 
 func LoadConfig() (*AppConfig, error) {